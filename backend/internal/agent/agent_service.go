@@ -1,19 +1,23 @@
 package agent
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
 	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
 	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AgentService handles AI-powered incident remediation
@@ -28,68 +32,335 @@ func NewAgentService() *AgentService {
 	}
 }
 
-// StartRemediation initiates the full agent workflow for an incident
-func (s *AgentService) StartRemediation(incident *models.Incident) (*models.AgentExecution, error) {
+// StartRemediation initiates the full agent workflow for an incident.
+// A dry run plans and simulates the commands without side effects, and needs no approval.
+func (s *AgentService) StartRemediation(incident *models.Incident, dryRun bool) (*models.AgentExecution, error) {
 	// Safety check: Can the agent act on this incident?
 	safetyCheck := CanAgentActOnIncident(incident)
+	if dryRun {
+		safetyCheck = CanAgentDryRunOnIncident(incident)
+	}
 	if !safetyCheck.Allowed {
 		return nil, fmt.Errorf("agent cannot act on this incident: %s", safetyCheck.Reason)
 	}
 
+	// Don't plan a remediation that could not be approved while the circuit breaker is open
+	if !dryRun {
+		if err := checkCircuitBreaker(time.Now()); err != nil {
+			if isGuardError(err) {
+				recordBlocked(incident.ID, uuid.Nil, "system", "Agent remediation", err)
+			}
+			return nil, err
+		}
+	}
+
 	// Create agent execution record
 	execution := &models.AgentExecution{
 		ID:         uuid.New(),
 		IncidentID: incident.ID,
 		Status:     models.StatusThinking,
 		AgentModel: "gemini-2.5-flash",
-		DryRun:     false,
+		DryRun:     dryRun,
 	}
 
-	if err := db.DB.Create(execution).Error; err != nil {
-		return nil, fmt.Errorf("failed to create agent execution: %w", err)
+	// Create the execution and its planning job together so a restart can't strand either
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(execution).Error; err != nil {
+			return fmt.Errorf("failed to create agent execution: %w", err)
+		}
+		if _, err := services.EnqueueJobTx(tx, services.JobAgentPlan, executionJobPayload{ExecutionID: execution.ID}); err != nil {
+			return fmt.Errorf("failed to queue agent workflow: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Start async workflow
-	go s.runWorkflow(execution, incident)
-
 	return execution, nil
 }
 
-// ApproveExecution continues the workflow after user approval
-func (s *AgentService) ApproveExecution(execution *models.AgentExecution) error {
+// ErrExecutionNotFound is returned when an execution ID does not exist
+var ErrExecutionNotFound = errors.New("execution not found")
+
+// ErrNotAwaitingApproval is returned when approving or rejecting an execution that already moved on
+var ErrNotAwaitingApproval = errors.New("execution is not awaiting approval")
+
+// ApproveExecution continues the workflow after approval by actor, approving every plan step.
+// Used by both the web UI and chat-ops links so every approval goes through the same checks.
+func (s *AgentService) ApproveExecution(executionID uuid.UUID, actor string) (*models.AgentExecution, error) {
+	return s.ApproveExecutionSteps(executionID, actor, nil, false)
+}
+
+// ApproveExecutionSteps approves only the given plan steps (0-based); nil approves them all.
+// Steps left unapproved are skipped when the plan reaches them. The execution runs once as many distinct
+// approvers as its approval policy requires have signed off, with each step approved by all of them;
+// until then each approval is recorded and the execution keeps waiting. If the targets changed significantly
+// since the preview, the approval is refused unless confirmStale says the plan still applies.
+func (s *AgentService) ApproveExecutionSteps(executionID uuid.UUID, actor string, approved []int, confirmStale bool) (*models.AgentExecution, error) {
+	var execution models.AgentExecution
+	if err := db.DB.First(&execution, "id = ?", executionID).Error; err != nil {
+		return nil, ErrExecutionNotFound
+	}
+	if execution.Status != models.StatusAwaitingApproval {
+		return nil, ErrNotAwaitingApproval
+	}
+
 	// Get the incident
 	var incident models.Incident
 	if err := db.DB.First(&incident, "id = ?", execution.IncidentID).Error; err != nil {
-		return fmt.Errorf("incident not found: %w", err)
+		return nil, fmt.Errorf("incident not found: %w", err)
 	}
 
-	log.Printf("✅ [Agent] Execution %s approved - continuing workflow", execution.ID.String()[:8])
+	// Re-check safety: a maintenance window may have started since the preview
+	if safetyCheck := CanAgentActOnIncident(&incident); !safetyCheck.Allowed {
+		return nil, fmt.Errorf("%w: %s", ErrNotActionable, safetyCheck.Reason)
+	}
 
-	// Continue workflow in goroutine
-	go s.continueWorkflowAfterApproval(execution, &incident)
+	// Refuse an expired approval, or a plan made for a system state that no longer holds
+	if err := s.checkBeforeApproval(&execution, actor, confirmStale); err != nil {
+		return nil, err
+	}
 
-	return nil
+	// Lock the execution so concurrent approvers (web and chat at once) are counted one after the other,
+	// and queue the execution job in the same transaction as the final approval
+	now := time.Now()
+	var approvals []models.Approval
+	var steps []models.PlanStep
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&execution, "id = ?", executionID).Error; err != nil {
+			return ErrExecutionNotFound
+		}
+		if execution.Status != models.StatusAwaitingApproval {
+			return ErrNotAwaitingApproval
+		}
+
+		var err error
+		if approvals, err = addApproval(&execution, actor, approved, now); err != nil {
+			return err
+		}
+		execution.Approvals = models.JSONB{Data: approvals}
+		if len(approvals) < requiredApprovals(&execution) {
+			return tx.Model(&models.AgentExecution{}).Where("id = ?", execution.ID).
+				Update("approvals", execution.Approvals).Error
+		}
+
+		if steps, err = applyApprovals(planOf(&execution), approvals); err != nil {
+			return err
+		}
+		// The final approval is refused, and can be given again later, while a guard would block the run
+		if err := checkGuards(&execution, &incident, approvedSteps(steps)); err != nil {
+			return err
+		}
+		setPlan(&execution, steps)
+		execution.Status = models.StatusExecuting
+		execution.ApprovedBy = approverNames(approvals)
+		execution.DecidedAt = &now
+		result := tx.Model(&models.AgentExecution{}).
+			Where("id = ? AND status = ?", execution.ID, models.StatusAwaitingApproval).
+			Updates(map[string]interface{}{
+				"status":      execution.Status,
+				"approved_by": execution.ApprovedBy,
+				"approvals":   execution.Approvals,
+				"decided_at":  now,
+				"plan":        execution.Plan,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to record approval: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotAwaitingApproval
+		}
+		if _, err := services.EnqueueJobTx(tx, services.JobAgentExecute, executionJobPayload{ExecutionID: execution.ID}); err != nil {
+			return fmt.Errorf("failed to queue execution: %w", err)
+		}
+		return nil
+	})
+	if isGuardError(err) {
+		recordBlocked(incident.ID, execution.ID, actor, "Approval of "+execution.RecommendedAction, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	required := requiredApprovals(&execution)
+	if execution.Status == models.StatusAwaitingApproval {
+		log.Printf("✍️  [Agent] Execution %s approved by %s (%d of %d approvals)", execution.ID.String()[:8], actor, len(approvals), required)
+		services.RecordTimelineEvent(incident.ID, "agent_approval_recorded", actor,
+			fmt.Sprintf("Approved agent action %s (%d of %d approvals)", execution.RecommendedAction, len(approvals), required),
+			map[string]interface{}{"execution_id": execution.ID, "approvals": len(approvals), "required_approvals": required})
+		return &execution, nil
+	}
+
+	log.Printf("✅ [Agent] Execution %s approved by %s - continuing workflow", execution.ID.String()[:8], execution.ApprovedBy)
+	services.RecordTimelineEvent(incident.ID, "agent_approved", actor,
+		fmt.Sprintf("Approved agent action %s%s", execution.RecommendedAction, describeApproval(steps)),
+		map[string]interface{}{"execution_id": execution.ID, "approved_steps": approvedStepIndexes(steps), "approvers": execution.ApprovedBy})
+
+	return &execution, nil
 }
 
-// continueWorkflowAfterApproval resumes the workflow after approval
-func (s *AgentService) continueWorkflowAfterApproval(execution *models.AgentExecution, incident *models.Incident) {
-	// Phase 4: Execution
-	if err := s.phaseExecution(execution, incident); err != nil {
-		s.failExecution(execution, fmt.Sprintf("Execution phase failed: %v", err))
-		return
+// RejectExecution cancels an execution that is awaiting approval
+func (s *AgentService) RejectExecution(executionID uuid.UUID, actor string) (*models.AgentExecution, error) {
+	var execution models.AgentExecution
+	if err := db.DB.First(&execution, "id = ?", executionID).Error; err != nil {
+		return nil, ErrExecutionNotFound
+	}
+	if execution.Status != models.StatusAwaitingApproval {
+		return nil, ErrNotAwaitingApproval
 	}
 
-	// Phase 5: Verification
-	if err := s.phaseVerification(execution, incident); err != nil {
-		s.failExecution(execution, fmt.Sprintf("Verification phase failed: %v", err))
-		return
+	now := time.Now()
+	errorMessage := fmt.Sprintf("Rejected by %s", actor)
+	result := db.DB.Model(&models.AgentExecution{}).
+		Where("id = ? AND status = ?", execution.ID, models.StatusAwaitingApproval).
+		Updates(map[string]interface{}{
+			"status":        models.StatusCancelled,
+			"error_message": errorMessage,
+			"rejected_by":   actor,
+			"decided_at":    now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to cancel execution: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrNotAwaitingApproval
+	}
+	execution.Status = models.StatusCancelled
+	execution.ErrorMessage = errorMessage
+	execution.RejectedBy = actor
+	execution.DecidedAt = &now
+
+	log.Printf("❌ [Agent] Execution %s rejected by %s", execution.ID.String()[:8], actor)
+	services.RecordTimelineEvent(execution.IncidentID, "agent_rejected", actor,
+		fmt.Sprintf("Rejected agent action %s", execution.RecommendedAction),
+		map[string]interface{}{"execution_id": execution.ID})
+
+	return &execution, nil
+}
+
+// continueWorkflowAfterApproval runs the approved plan step by step, each step gated by its own
+// verification. It runs as an agent.execute job; a returned error dead-letters the job, which marks
+// the execution as failed. An execution resumed while verifying picks up at that step's verification,
+// which only reads system state.
+func (s *AgentService) continueWorkflowAfterApproval(ctx context.Context, execution *models.AgentExecution, incident *models.Incident) error {
+	steps := planOf(execution)
+	if len(steps) == 0 {
+		// Executions planned before multi-step plans: run their commands as a single step
+		var commands []models.Command
+		commandsJSON, _ := json.Marshal(execution.Commands.Data)
+		json.Unmarshal(commandsJSON, &commands)
+		steps = []models.PlanStep{{
+			Action:   execution.RecommendedAction,
+			Commands: commands,
+			Rollback: rollbackPlanOf(execution),
+			Approved: true,
+			Status:   models.StepPending,
+		}}
+		setPlan(execution, steps)
+	}
+
+	// No other execution may change or verify the same systems until this one is done with them
+	if !execution.DryRun {
+		release, err := s.holdTargets(execution, incident, "Execution of "+execution.RecommendedAction)
+		if err != nil {
+			s.failExecution(execution, err.Error())
+			return nil
+		}
+		defer release()
+	}
+
+	var last *models.PlanStep
+	for ; execution.CurrentStep < len(steps); execution.CurrentStep++ {
+		step := &steps[execution.CurrentStep]
+		label := fmt.Sprintf("Step %d/%d (%s)", execution.CurrentStep+1, len(steps), step.Action)
+
+		if execution.Status != models.StatusVerifying {
+			reason := stepSkipReason(steps, execution.CurrentStep)
+			// Rate limits and the breaker are checked again for each step, since earlier steps count too
+			if reason == "" && !execution.DryRun {
+				target := stepTarget(step.Action, incident)
+				if err := checkStepGuards(step.Action, target); err != nil {
+					recordBlocked(execution.IncidentID, execution.ID, "system", label, err)
+					reason = "Blocked: " + err.Error()
+				} else {
+					recordActionRun(execution, step.Action, target)
+				}
+			}
+			if reason != "" {
+				log.Printf("⏭️  [Agent] %s skipped: %s", label, reason)
+				step.Status = models.StepSkipped
+				step.Outcome = reason
+				setPlan(execution, steps)
+				if err := s.saveProgress(execution); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Phase 4: Execution
+			if err := s.phaseExecution(ctx, execution, steps, step); err != nil {
+				if errors.Is(err, ErrExecutionStopped) || ctx.Err() != nil {
+					return err
+				}
+				step.Status = models.StepFailed
+				step.Outcome = err.Error()
+				setPlan(execution, steps)
+				return s.rollbackOrFail(ctx, execution, incident, fmt.Sprintf("%s failed: %v", label, err))
+			}
+		}
+
+		// Phase 5: Verification gate
+		if err := s.phaseVerification(ctx, execution, incident, steps, step); err != nil {
+			return fmt.Errorf("Verification phase failed: %v", err)
+		}
+		last = step
+		services.RecordTimelineEvent(execution.IncidentID, "agent_step_completed", "system",
+			fmt.Sprintf("%s %s: %s", label, step.Status, step.Outcome),
+			map[string]interface{}{"execution_id": execution.ID, "step": execution.CurrentStep, "action": step.Action, "passed": step.Status == models.StepPassed})
+		execution.Status = models.StatusExecuting
+	}
+	setPlan(execution, steps)
+
+	if last == nil {
+		last = lastRunStep(steps, len(steps))
+	}
+	if last == nil {
+		reasons := make([]string, len(steps))
+		for i, step := range steps {
+			reasons[i] = fmt.Sprintf("step %d: %s", i+1, step.Outcome)
+		}
+		s.failExecution(execution, "No plan step ran: every step was skipped ("+strings.Join(reasons, "; ")+")")
+		return nil
+	}
+	return s.completeExecution(ctx, execution, incident, last)
+}
+
+// completeExecution records the outcome once the plan has finished; the last step that ran decides it
+func (s *AgentService) completeExecution(ctx context.Context, execution *models.AgentExecution, incident *models.Incident, last *models.PlanStep) error {
+	passed := last.Status == models.StepPassed
+	execution.VerificationChecks = models.JSONB{Data: last.Checks}
+	execution.VerificationPassed = &passed
+	execution.VerificationNotes = last.Outcome
+
+	// A dry run only reports current state; it never fails on it or resolves the incident
+	if execution.DryRun {
+		success := true
+		execution.Success = &success
+		execution.Status = models.StatusCompleted
+		now := time.Now()
+		execution.CompletedAt = &now
+		if err := s.saveProgressAndPublish(execution, events.AgentCompleted); err != nil {
+			return err
+		}
+		log.Printf("🧪 [Agent] Dry run completed for incident %s", incident.ID.String()[:8])
+		return nil
 	}
 
 	// Check if verification passed - if not, mark as failed
-	if execution.VerificationPassed == nil || !*execution.VerificationPassed {
+	if !passed {
 		log.Printf("❌ [Agent] Verification failed - marking execution as failed")
-		s.failExecution(execution, "Verification checks failed. System did not return to healthy state.")
-		return
+		return s.rollbackOrFail(ctx, execution, incident, "Verification checks failed. System did not return to healthy state.")
 	}
 
 	// Complete successfully
@@ -98,77 +369,158 @@ func (s *AgentService) continueWorkflowAfterApproval(execution *models.AgentExec
 	execution.Status = models.StatusCompleted
 	execution.CompletedAt = &time.Time{}
 	*execution.CompletedAt = time.Now()
-	db.DB.Save(execution)
+	if err := s.saveProgressAndPublish(execution, events.AgentCompleted); err != nil {
+		return err
+	}
 
 	// Verification passed, resolve the incident
-	if execution.VerificationPassed != nil && *execution.VerificationPassed {
-		log.Printf("🎯 [Agent] Verification passed - marking incident as resolved")
+	log.Printf("🎯 [Agent] Verification passed - marking incident as resolved")
 
-		// Create status history entry
-		statusHistory := models.StatusHistory{
-			IncidentID: incident.ID,
-			FromStatus: &incident.Status,
-			ToStatus:   "resolved",
-			ChangedAt:  time.Now(),
-		}
+	// Create status history entry
+	statusHistory := models.StatusHistory{
+		IncidentID: incident.ID,
+		FromStatus: &incident.Status,
+		ToStatus:   "resolved",
+		ChangedAt:  time.Now(),
+	}
 
-		// Update incident status to resolved
-		oldStatus := incident.Status
-		incident.Status = "resolved"
+	// Update incident status to resolved
+	oldStatus := incident.Status
+	incident.Status = "resolved"
+	incident.SnoozedUntil = nil
+	incident.SnoozeReason = ""
+	incident.SnoozedBy = ""
 
-		// Save both in a transaction
-		tx := db.DB.Begin()
+	// Save both in a transaction, together with the resolved event
+	var outbox services.EventOutbox
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Save(incident).Error; err != nil {
-			tx.Rollback()
-			log.Printf("⚠️  [Agent] Failed to update incident status: %v", err)
-		} else if err := tx.Create(&statusHistory).Error; err != nil {
-			tx.Rollback()
-			log.Printf("⚠️  [Agent] Failed to create status history: %v", err)
-		} else {
-			tx.Commit()
-			log.Printf("✅ [Agent] Incident %s automatically resolved (%s → resolved)", incident.ID.String()[:8], oldStatus)
-
-			// Broadcast the status change via WebSocket (use BroadcastIncidentUpdate to include StatusHistory)
-			services.BroadcastIncidentUpdate(incident.ID)
-			log.Printf("📡 [Agent] Broadcasted incident resolution to WebSocket clients")
+			return fmt.Errorf("failed to update incident status: %w", err)
+		}
+		if err := tx.Create(&statusHistory).Error; err != nil {
+			return fmt.Errorf("failed to create status history: %w", err)
 		}
+		return outbox.AddIncident(tx, events.IncidentResolved, incident.ID)
+	}); err != nil {
+		log.Printf("⚠️  [Agent] %v", err)
 	} else {
-		log.Printf("⚠️  [Agent] Verification failed - incident requires manual review")
+		log.Printf("✅ [Agent] Incident %s automatically resolved (%s → resolved)", incident.ID.String()[:8], oldStatus)
+
+		// Broadcast the status change via WebSocket (use BroadcastIncidentUpdate to include StatusHistory)
+		services.BroadcastIncidentUpdate(incident.ID)
+		log.Printf("📡 [Agent] Broadcasted incident resolution to WebSocket clients")
+		outbox.Dispatch()
 	}
 
 	log.Printf("✅ [Agent] Remediation completed successfully for incident %s", incident.ID.String()[:8])
+	return nil
 }
 
-// runWorkflow executes the full multi-phase agent workflow
-func (s *AgentService) runWorkflow(execution *models.AgentExecution, incident *models.Incident) {
+// runWorkflow runs the planning phases up to approval. It runs as an agent.plan job;
+// a returned error is retried, and the execution is failed once attempts run out.
+func (s *AgentService) runWorkflow(ctx context.Context, execution *models.AgentExecution, incident *models.Incident) error {
 	log.Printf("🤖 [Agent] Starting remediation workflow for incident %s", incident.ID.String()[:8])
 
 	// Phase 1: Thinking
-	if err := s.phaseThinking(execution, incident); err != nil {
-		s.failExecution(execution, fmt.Sprintf("Thinking phase failed: %v", err))
-		return
+	if err := s.phaseThinking(ctx, execution, incident); err != nil {
+		return fmt.Errorf("Thinking phase failed: %w", err)
 	}
 
 	// Phase 2: Command Preview
-	if err := s.phaseCommandPreview(execution, incident); err != nil {
-		s.failExecution(execution, fmt.Sprintf("Command preview failed: %v", err))
-		return
+	if err := s.phaseCommandPreview(ctx, execution, incident); err != nil {
+		return fmt.Errorf("Command preview failed: %v", err)
+	}
+
+	// A dry run changes nothing, so it goes straight to the simulated execution of every step
+	if execution.DryRun {
+		steps, _ := approvePlanSteps(planOf(execution), nil)
+		setPlan(execution, steps)
+		execution.Status = models.StatusExecuting
+		return db.DB.Transaction(func(tx *gorm.DB) error {
+			if err := s.saveProgressTx(tx, execution); err != nil {
+				return err
+			}
+			if _, err := services.EnqueueJobTx(tx, services.JobAgentExecute, executionJobPayload{ExecutionID: execution.ID}); err != nil {
+				return fmt.Errorf("failed to queue dry run: %w", err)
+			}
+			return nil
+		})
+	}
+
+	// Phase 3: Approval, as the policy requires
+	decision := evaluateApprovalPolicy(execution, incident, time.Now())
+	execution.ApprovalPolicy = models.JSONB{Data: decision}
+	if decision.RequiredApprovals == 0 {
+		err := checkGuards(execution, incident, planOf(execution))
+		if err == nil {
+			return s.autoApprove(execution, decision)
+		}
+		if !isGuardError(err) {
+			return err
+		}
+		// Rather than queue a plan that would be blocked, leave it for a person to approve once the block clears
+		recordBlocked(incident.ID, execution.ID, PolicyActor, "Auto-approval of "+execution.RecommendedAction, err)
+		decision.RequiredApprovals = 1
+		decision.Reasons = append(decision.Reasons, "Auto-approval blocked: "+err.Error())
+		execution.ApprovalPolicy = models.JSONB{Data: decision}
 	}
 
-	// Phase 3: Wait for human approval
+	openApprovalWindow(ctx, execution)
 	execution.Status = models.StatusAwaitingApproval
-	db.DB.Save(execution)
+	if err := s.saveProgressAndPublish(execution, events.AgentAwaitingApproval); err != nil {
+		return err
+	}
 
 	log.Printf("⏳ [Agent] Execution %s is awaiting user approval", execution.ID.String()[:8])
 	// Workflow will be resumed by ApproveExecution handler
+	return nil
+}
+
+// autoApprove queues the plan for execution without waiting for a person, as the approval policy allows
+func (s *AgentService) autoApprove(execution *models.AgentExecution, decision models.ApprovalDecision) error {
+	now := time.Now()
+	steps, _ := approvePlanSteps(planOf(execution), nil)
+	setPlan(execution, steps)
+	execution.Approvals = models.JSONB{Data: []models.Approval{{Actor: PolicyActor, Steps: approvedStepIndexes(steps), At: now}}}
+	execution.ApprovedBy = PolicyActor
+	execution.DecidedAt = &now
+	execution.Status = models.StatusExecuting
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.saveProgressTx(tx, execution); err != nil {
+			return err
+		}
+		if _, err := services.EnqueueJobTx(tx, services.JobAgentExecute, executionJobPayload{ExecutionID: execution.ID}); err != nil {
+			return fmt.Errorf("failed to queue execution: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🤖 [Agent] Execution %s auto-approved by policy (%s risk)", execution.ID.String()[:8], decision.RiskLevel)
+	services.RecordTimelineEvent(execution.IncidentID, "agent_auto_approved", PolicyActor,
+		fmt.Sprintf("Auto-approved agent action %s: %s", execution.RecommendedAction, strings.Join(decision.Reasons, "; ")),
+		map[string]interface{}{"execution_id": execution.ID, "risk_level": decision.RiskLevel})
+	return nil
 }
 
 // phaseThinking: AI analyses the incident and decides what action to take
-func (s *AgentService) phaseThinking(execution *models.AgentExecution, incident *models.Incident) error {
+func (s *AgentService) phaseThinking(ctx context.Context, execution *models.AgentExecution, incident *models.Incident) error {
 	log.Printf("🧠 [Agent] Phase 1: Thinking...")
+	ctx, cancel := context.WithTimeout(ctx, thinkingPhaseTimeout)
+	defer cancel()
 
 	execution.Status = models.StatusThinking
-	db.DB.Save(execution)
+	if err := s.saveProgress(execution); err != nil {
+		return err
+	}
+
+	// Only offer the catalog actions that target one of the incident's systems
+	actions := actionsForIncident(incident)
+	if len(actions) == 0 {
+		return fmt.Errorf("no catalog actions target the incident's affected systems %v", incident.AffectedSystems)
+	}
 
 	// Call AI to analyse incident and recommend action
 	prompt := fmt.Sprintf(`You are an expert SRE AI agent analyzing a production system incident. Your job is to diagnose the problem and select the best remediation action.
@@ -181,35 +533,7 @@ Affected Systems: %v
 AVAILABLE REMEDIATION ACTIONS:
 You can ONLY choose from these pre-approved actions:
 
-1. "clear_redis_cache" - Clears all keys from Redis using FLUSHALL
-   - Impact: Immediate memory recovery, ~1-2 second operation
-   - Risk: Active sessions/cached data will be lost (medium severity)
-   - Use when: Redis memory is critically high but service is responsive
-
-2. "restart_redis" - Restarts the Redis container 
-   - Impact: 2-3 second downtime, complete service interruption
-   - Risk: Brief outage for all Redis-dependent services (high severity)
-   - Use when: Redis is unresponsive, crashed, or in an error state that cache clearing won't fix
-
-3. "kill_idle_connections" - Terminates idle PostgreSQL connections
-   - Impact: Frees connection slots immediately, no query interruption
-   - Risk: Minimal, only idle connections affected (low severity)
-   - Use when: Connection pool is exhausted but active queries are fine
-
-4. "vacuum_table" - Runs VACUUM ANALYZE on PostgreSQL tables
-   - Impact: Reclaims dead tuple space, updates statistics, brief performance impact
-   - Risk: Table remains accessible, slight performance degradation during operation (low severity)
-   - Use when: Table bloat from dead tuples is degrading performance
-
-5. "restart_postgres" - Restarts the PostgreSQL container
-   - Impact: 2-3 second downtime, all connections dropped
-   - Risk: Brief outage for database-dependent services (medium-high severity)
-   - Use when: PostgreSQL is unresponsive or in a corrupted state that lighter fixes won't resolve
-
-6. "cleanup_old_logs" - Deletes old log files to free disk space
-   - Impact: Immediate disk space recovery, no service impact
-   - Risk: Historical logs lost (low severity - test logs only)
-   - Use when: Disk space is critically low
+%s
 
 DECISION CRITERIA:
 - Analyze the incident message, source, and affected systems
@@ -218,131 +542,192 @@ DECISION CRITERIA:
 - Prefer targeted fixes (e.g., kill_idle_connections) over nuclear options (e.g., restart_postgres)
 - Consider: Will this fix actually resolve the root cause, or just temporarily mask it?
 
+MULTI-STEP PLANS (optional):
+If the least disruptive action may not be enough on its own, add a "plan" of up to %d steps that escalates.
+The first step must be recommended_action with no condition. Each later step may have a "condition" on the
+verification of the previous step that ran: {"check": "<one of that action's verification checks>", "passed": false}
+runs the step only if that check failed; leave out "check" to mean all of that step's checks. A step without a
+condition always runs. Omit "plan" for a single action.
+
 Respond ONLY in valid JSON format:
 {
   "analysis": "detailed technical analysis of the root cause and current system state",
-  "recommended_action": "one of the 6 action names above",
-  "reasoning": "explain why this specific action is the best choice given the tradeoffs"
-}`, incident.Message, incident.Source, incident.AffectedSystems)
-
-	response, err := s.callAI(prompt)
-	if err != nil {
-		return fmt.Errorf("AI call failed: %w", err)
-	}
-
-	// Try to extract JSON from response (AI might return text + JSON)
-	jsonStart := strings.Index(response, "{")
-	jsonEnd := strings.LastIndex(response, "}")
-
-	if jsonStart == -1 || jsonEnd == -1 || jsonEnd <= jsonStart {
-		log.Printf("❌ [Agent] Invalid AI response format (no JSON found): %s", response)
-		return fmt.Errorf("AI response does not contain valid JSON")
-	}
-
-	jsonResponse := response[jsonStart : jsonEnd+1]
-	log.Printf("🔍 [Agent] Extracted JSON: %s", jsonResponse)
-
-	// Parse response
-	var result struct {
-		Analysis          string `json:"analysis"`
-		RecommendedAction string `json:"recommended_action"`
-		Reasoning         string `json:"reasoning"`
-	}
-
-	if err := json.Unmarshal([]byte(jsonResponse), &result); err != nil {
-		log.Printf("❌ [Agent] Failed to parse JSON: %s", jsonResponse)
-		return fmt.Errorf("failed to parse AI response: %w", err)
+  "recommended_action": "one of the %d action names above",
+  "reasoning": "explain why this specific action is the best choice given the tradeoffs",
+  "plan": [
+    {"action": "same as recommended_action", "reason": "why this step"},
+    {"action": "another action name", "condition": {"check": "a check of the previous step", "passed": false}, "reason": "why escalate"}
+  ]
+}`, incident.Message, incident.Source, incident.AffectedSystems, promptActions(actions), maxPlanSteps, len(actions))
+
+	// Validate the answer, re-prompting with the validation error a bounded number of times
+	var result thinkingResult
+	request := prompt
+	for attempt := 1; ; attempt++ {
+		response, err := s.callAI(ctx, request)
+		if err != nil {
+			return fmt.Errorf("AI call failed: %w", err)
+		}
+		result, err = parseThinkingResponse(response, incident, execution.DryRun)
+		if err == nil {
+			break
+		}
+		log.Printf("⚠️  [Agent] Rejected AI decision (attempt %d/%d): %v", attempt, maxThinkingAttempts, err)
+		if attempt == maxThinkingAttempts {
+			// Re-running the phase would only repeat the same loop
+			return services.Permanent(fmt.Errorf("AI did not recommend a valid action after %d attempts: %w", maxThinkingAttempts, err))
+		}
+		request = repairPrompt(prompt, response, err, actions)
 	}
 
 	// Update execution
 	execution.Analysis = result.Analysis
 	execution.RecommendedAction = result.RecommendedAction
 	execution.Reasoning = result.Reasoning
-	db.DB.Save(execution)
+	steps := make([]models.PlanStep, len(result.Plan))
+	for i, decision := range result.Plan {
+		steps[i] = models.PlanStep{Action: decision.Action, Condition: decision.Condition, Reason: decision.Reason, Status: models.StepPending}
+	}
+	setPlan(execution, steps)
+	execution.CurrentStep = 0
+	if err := s.saveProgress(execution); err != nil {
+		return err
+	}
 
-	log.Printf("✅ [Agent] Thinking complete. Action: %s", result.RecommendedAction)
+	log.Printf("✅ [Agent] Thinking complete. Action: %s (%d step plan)", result.RecommendedAction, len(steps))
 	return nil
 }
 
-// phaseCommandPreview: Generate specific commands and assess risks
-func (s *AgentService) phaseCommandPreview(execution *models.AgentExecution, incident *models.Incident) error {
+// phaseCommandPreview: Generate specific commands and assess risks for every plan step
+func (s *AgentService) phaseCommandPreview(ctx context.Context, execution *models.AgentExecution, incident *models.Incident) error {
 	log.Printf("📋 [Agent] Phase 2: Generating command preview...")
 
 	execution.Status = models.StatusPreviewing
-	db.DB.Save(execution)
-
-	// Generate commands based on recommended action
-	commands, impact, risks := s.generateCommands(execution.RecommendedAction, incident)
+	if err := s.saveProgress(execution); err != nil {
+		return err
+	}
 
-	// Store as JSONB
-	execution.Commands = models.JSONB{Data: commands}
-	execution.Risks = models.JSONB{Data: risks}
+	steps := planOf(execution)
+	if len(steps) == 0 {
+		steps = []models.PlanStep{{Action: execution.RecommendedAction, Status: models.StepPending}}
+	}
 
-	execution.EstimatedImpact = impact
-	db.DB.Save(execution)
+	if err := previewPlan(execution, steps); err != nil {
+		return err
+	}
+	if err := s.saveProgress(execution); err != nil {
+		return err
+	}
 
-	log.Printf("✅ [Agent] Command preview complete. %d commands generated", len(commands))
+	log.Printf("✅ [Agent] Command preview complete. %d step(s) generated", len(steps))
 	return nil
 }
 
-// phaseExecution: Execute the commands
-func (s *AgentService) phaseExecution(execution *models.AgentExecution, incident *models.Incident) error {
-	log.Printf("⚡ [Agent] Phase 3: Executing commands...")
+// phaseExecution: Execute one plan step's commands
+func (s *AgentService) phaseExecution(ctx context.Context, execution *models.AgentExecution, steps []models.PlanStep, step *models.PlanStep) error {
+	log.Printf("⚡ [Agent] Phase 3: Executing %s...", step.Action)
+	ctx, cancel := context.WithTimeout(ctx, executionPhaseTimeout)
+	defer cancel()
 
 	execution.Status = models.StatusExecuting
 	now := time.Now()
-	execution.StartedAt = &now
-	db.DB.Save(execution)
+	if execution.StartedAt == nil {
+		execution.StartedAt = &now
+	}
+	step.StartedAt = &now
+	step.Status = models.StepRunning
+	execution.RollbackPlan = models.JSONB{Data: step.Rollback} // A failure undoes only this step
+	setPlan(execution, steps)
+	if err := s.saveProgress(execution); err != nil {
+		return err
+	}
 
-	// Parse commands from JSONB
-	var commands []models.Command
-	commandsJSON, _ := json.Marshal(execution.Commands.Data)
-	json.Unmarshal(commandsJSON, &commands)
+	// Snapshot the targets first (read-only, so dry runs take one too)
+	execution.Snapshot = models.JSONB{Data: s.takeSnapshot(ctx, step.Rollback)}
+	if err := s.saveProgress(execution); err != nil {
+		return err
+	}
 
-	// Execute each command
+	// Execute each command, saving the logs after each one so a restart can tell how far it got.
+	// The save fails once the execution was cancelled, so the loop stops even when the cancellation
+	// came from another instance.
 	var logs []models.ExecutionLog
-	for _, cmd := range commands {
-		log.Printf("🔧 [Agent] Executing: %s", cmd.Name)
-
+	logsJSON, _ := json.Marshal(execution.ExecutionLogs.Data)
+	json.Unmarshal(logsJSON, &logs)
+	appendLog := func(entry models.ExecutionLog) error {
+		logs = append(logs, entry)
+		step.Logs = append(step.Logs, entry)
+		execution.ExecutionLogs = models.JSONB{Data: logs}
+		setPlan(execution, steps)
+		return saveLogs(execution)
+	}
+	for _, cmd := range step.Commands {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("stopped before %s: %w", cmd.Name, err)
+		}
 		startTime := time.Now()
-		output, err := s.executeCommand(cmd, incident)
+		if execution.DryRun {
+			log.Printf("🧪 [Agent] Simulating: %s", cmd.Name)
+			if err := appendLog(models.ExecutionLog{
+				Timestamp: startTime,
+				Command:   cmd.Command,
+				Output:    "[dry run] " + describeCommand(cmd),
+				Status:    "simulated",
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		log.Printf("🔧 [Agent] Executing: %s", cmd.Name)
+		result, err := runCommand(ctx, cmd)
 		duration := time.Since(startTime).Milliseconds()
 
 		logEntry := models.ExecutionLog{
 			Timestamp:  startTime,
 			Command:    cmd.Command,
-			Output:     output,
+			Output:     result.Output,
+			Result:     result.Data,
 			DurationMs: duration,
 		}
 
 		if err != nil {
 			logEntry.Status = "failed"
+			if ctx.Err() != nil {
+				logEntry.Status = "cancelled"
+			}
 			logEntry.ErrorDetail = err.Error()
-			logs = append(logs, logEntry)
+			if saveErr := appendLog(logEntry); saveErr != nil {
+				return saveErr
+			}
 			return fmt.Errorf("command failed: %w", err)
 		}
 
 		logEntry.Status = "success"
-		logs = append(logs, logEntry)
+		if err := appendLog(logEntry); err != nil {
+			return err
+		}
 	}
 
-	// Store logs
-	execution.ExecutionLogs = models.JSONB{Data: logs}
-	db.DB.Save(execution)
-
-	log.Printf("✅ [Agent] Execution complete. %d commands executed", len(logs))
+	log.Printf("✅ [Agent] Execution of %s complete. %d commands executed", step.Action, len(step.Logs))
 	return nil
 }
 
-// phaseVerification: Verify the fix worked
-func (s *AgentService) phaseVerification(execution *models.AgentExecution, incident *models.Incident) error {
-	log.Printf("🔍 [Agent] Phase 4: Verifying remediation...")
+// phaseVerification: Verify one plan step worked
+func (s *AgentService) phaseVerification(ctx context.Context, execution *models.AgentExecution, incident *models.Incident, steps []models.PlanStep, step *models.PlanStep) error {
+	log.Printf("🔍 [Agent] Phase 4: Verifying %s...", step.Action)
+	ctx, cancel := context.WithTimeout(ctx, verificationPhaseTimeout)
+	defer cancel()
 
 	execution.Status = models.StatusVerifying
-	db.DB.Save(execution)
+	step.Status = models.StepVerifying
+	setPlan(execution, steps)
+	if err := s.saveProgress(execution); err != nil {
+		return err
+	}
 
 	// Run verification checks based on the action taken
-	checks := s.runVerificationChecks(execution.RecommendedAction, incident)
+	checks := s.runVerificationChecks(ctx, step.Action, incident)
 
 	// Determine if all checks passed
 	allPassed := true
@@ -354,29 +739,48 @@ func (s *AgentService) phaseVerification(execution *models.AgentExecution, incid
 	}
 
 	// Store results
+	completed := time.Now()
+	step.Checks = checks
+	step.CompletedAt = &completed
+	step.Status = models.StepFailed
+	if allPassed {
+		step.Status = models.StepPassed
+	}
 	execution.VerificationChecks = models.JSONB{Data: checks}
 	execution.VerificationPassed = &allPassed
 
-	if allPassed {
-		execution.VerificationNotes = "All verification checks passed. System is healthy."
-	} else {
-		execution.VerificationNotes = "Some verification checks failed. Manual review recommended."
+	switch {
+	case execution.DryRun && allPassed:
+		step.Outcome = "Dry run: no changes were made. Target systems currently pass all checks."
+	case execution.DryRun:
+		step.Outcome = "Dry run: no changes were made. Target systems currently fail some checks, which the real remediation would need to fix."
+	case allPassed:
+		step.Outcome = "All verification checks passed. System is healthy."
+	default:
+		step.Outcome = "Some verification checks failed. Manual review recommended."
+	}
+	execution.VerificationNotes = step.Outcome
+	setPlan(execution, steps)
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	db.DB.Save(execution)
+	if err := s.saveProgress(execution); err != nil {
+		return err
+	}
 
 	log.Printf("✅ [Agent] Verification complete. Passed: %v", allPassed)
 	return nil
 }
 
 // Helper: Call AI service
-func (s *AgentService) callAI(prompt string) (string, error) {
+func (s *AgentService) callAI(ctx context.Context, prompt string) (string, error) {
 	payload := map[string]interface{}{
 		"prompt": prompt,
 	}
 
 	jsonData, _ := json.Marshal(payload)
-	resp, err := http.Post(s.aiServiceURL+"/api/v1/agent-think", "application/json", strings.NewReader(string(jsonData)))
+	resp, err := httpPost(ctx, s.aiServiceURL+"/api/v1/agent-think", strings.NewReader(string(jsonData)))
 	if err != nil {
 		return "", err
 	}
@@ -401,364 +805,80 @@ func (s *AgentService) failExecution(execution *models.AgentExecution, errorMsg
 	execution.Success = &success
 	execution.Status = models.StatusFailed
 	execution.ErrorMessage = errorMsg
-	db.DB.Save(execution)
-}
-
-// generateCommands creates the actual commands to execute
-func (s *AgentService) generateCommands(action string, incident *models.Incident) ([]models.Command, string, []models.Risk) {
-	switch action {
-	case "clear_redis_cache":
-		return []models.Command{
-				{
-					Name:        "Clear Redis Cache",
-					Command:     "redis-cli",
-					Args:        []string{"FLUSHALL"},
-					Target:      "redis-test",
-					Description: "Clear all keys from Redis to free up memory",
-				},
-			},
-			"Redis memory will be freed. Active sessions may be temporarily disrupted.",
-			[]models.Risk{
-				{Level: "medium", Description: "Active user sessions will be cleared", Mitigation: "Sessions will be recreated automatically"},
-			}
-
-	case "restart_redis":
-		return []models.Command{
-				{
-					Name:        "Restart Redis Container",
-					Command:     "docker",
-					Args:        []string{"restart", "redis-test"},
-					Target:      "redis-test",
-					Description: "Restart the Redis container to recover from error state",
-				},
-			},
-			"Redis will be unavailable for 2-3 seconds during restart.",
-			[]models.Risk{
-				{Level: "high", Description: "Brief service interruption", Mitigation: "Application has retry logic"},
-			}
-
-	case "kill_idle_connections":
-		return []models.Command{
-				{
-					Name:        "Kill Idle PostgreSQL Connections",
-					Command:     "http_post",
-					Args:        []string{"http://health-monitor:8002/clear/postgres"},
-					Target:      "postgres-test",
-					Description: "Terminate idle database connections to free up connection pool",
-				},
-			},
-			"Will terminate idle connections. Active queries will not be affected.",
-			[]models.Risk{
-				{Level: "low", Description: "Only idle connections terminated", Mitigation: "Active queries continue unaffected"},
-			}
-
-	case "vacuum_table":
-		return []models.Command{
-				{
-					Name:        "Run VACUUM on PostgreSQL Table",
-					Command:     "http_post",
-					Args:        []string{"http://health-monitor:8002/clear/postgres-bloat"},
-					Target:      "postgres-test",
-					Description: "Run VACUUM ANALYZE to reclaim space from dead tuples and update statistics",
-				},
-			},
-			"Will reclaim space from dead tuples. Brief performance impact during execution.",
-			[]models.Risk{
-				{Level: "low", Description: "Brief performance impact while VACUUM runs", Mitigation: "Operation typically completes in seconds"},
-				{Level: "low", Description: "Table remains accessible during VACUUM", Mitigation: "PostgreSQL VACUUM does not lock tables"},
-			}
-
-	case "restart_postgres":
-		return []models.Command{
-				{
-					Name:        "Restart PostgreSQL Container",
-					Command:     "docker",
-					Args:        []string{"restart", "postgres-test"},
-					Target:      "postgres-test",
-					Description: "Restart PostgreSQL to clear all connections and reset state",
-				},
-			},
-			"PostgreSQL will be unavailable for 2-3 seconds during restart.",
-			[]models.Risk{
-				{Level: "medium", Description: "Brief service interruption", Mitigation: "Applications should have connection retry logic"},
-				{Level: "low", Description: "All connections will be dropped", Mitigation: "Expected behavior for restart"},
-			}
-
-	case "cleanup_old_logs":
-		return []models.Command{
-				{
-					Name:        "Clean Up Old Log Files",
-					Command:     "http_post",
-					Args:        []string{"http://health-monitor:8002/clear/disk"},
-					Target:      "disk-monitor",
-					Description: "Remove old log files to free up disk space",
-				},
-			},
-			"Will delete test log files. No impact on running services.",
-			[]models.Risk{
-				{Level: "low", Description: "Log files will be deleted", Mitigation: "Only removes test log files created for simulation"},
-				{Level: "low", Description: "No service interruption", Mitigation: "Disk cleanup happens in the background"},
-			}
-
-	default:
-		return []models.Command{
-				{
-					Name:        "Unknown Action",
-					Command:     "echo",
-					Args:        []string{"Unknown action: " + action},
-					Target:      "",
-					Description: "No commands available for this action",
-				},
-			},
-			"Unknown impact",
-			[]models.Risk{}
-	}
-}
-
-// executeCommand runs a single command
-func (s *AgentService) executeCommand(cmd models.Command, incident *models.Incident) (string, error) {
-	healthMonitorURL := os.Getenv("HEALTH_MONITOR_URL")
-	if healthMonitorURL == "" {
-		healthMonitorURL = "http://localhost:8002"
-	}
-
-	// For Redis actions, call the health-monitor service
-	if cmd.Target == "redis-test" {
-		if cmd.Command == "redis-cli" && len(cmd.Args) > 0 && cmd.Args[0] == "FLUSHALL" {
-			resp, err := http.Post(healthMonitorURL+"/clear/redis", "application/json", nil)
-			if err != nil {
-				return "", err
-			}
-			defer resp.Body.Close()
-
-			body, _ := ioutil.ReadAll(resp.Body)
-			return string(body), nil
-		}
+	if s.saveProgressAndPublish(execution, events.AgentFailed) != nil {
+		return
 	}
 
-	// For PostgreSQL actions, call the health-monitor service
-	if cmd.Target == "postgres-test" {
-		if cmd.Command == "http_post" {
-			// Call health monitor to kill idle connections
-			url := cmd.Args[0]
-			resp, err := http.Post(url, "application/json", nil)
-			if err != nil {
-				return "", fmt.Errorf("failed to call %s: %w", url, err)
-			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode == 200 {
-				body, _ := ioutil.ReadAll(resp.Body)
-				return string(body), nil
-			}
-			return fmt.Sprintf("Failed with status %d", resp.StatusCode), fmt.Errorf("unexpected status")
-		} else if cmd.Command == "docker" {
-			// Restart container
-			log.Printf("🐳 [Agent] Restarting postgres-test container...")
-			// For now, return success message (actual Docker API implementation can be added later)
-			return "PostgreSQL container restart initiated", nil
-		}
-	}
-
-	// For disk cleanup actions, call the health-monitor service
-	if cmd.Target == "disk-monitor" {
-		if cmd.Command == "http_post" {
-			url := cmd.Args[0]
-			resp, err := http.Post(url, "application/json", nil)
-			if err != nil {
-				return "", fmt.Errorf("failed to call %s: %w", url, err)
-			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode == 200 {
-				body, _ := ioutil.ReadAll(resp.Body)
-				return string(body), nil
-			}
-			return fmt.Sprintf("Failed with status %d", resp.StatusCode), fmt.Errorf("unexpected status")
+	// Only failures after commands ran count toward the circuit breaker
+	if !execution.DryRun && execution.StartedAt != nil {
+		if _, err := evaluateCircuitBreaker(time.Now()); err != nil {
+			log.Printf("⚠️  [Agent] Failed to evaluate the circuit breaker: %v", err)
 		}
 	}
-
-	return fmt.Sprintf("Command executed: %s %v", cmd.Command, cmd.Args), nil
 }
 
-// runVerificationChecks verifies the remediation worked
-func (s *AgentService) runVerificationChecks(action string, incident *models.Incident) []models.VerificationCheck {
+// runVerificationChecks evaluates the action's declared checks against the health monitor's status
+func (s *AgentService) runVerificationChecks(ctx context.Context, actionName string, incident *models.Incident) []models.VerificationCheck {
 	checks := []models.VerificationCheck{}
-
-	// For Redis actions, check health
-	if action == "clear_redis_cache" || action == "restart_redis" {
-		// Call health monitor to check Redis status
-		healthMonitorURL := os.Getenv("HEALTH_MONITOR_URL")
-		if healthMonitorURL == "" {
-			healthMonitorURL = "http://localhost:8002"
-		}
-
-		resp, err := http.Get(healthMonitorURL + "/status")
-		if err != nil {
-			checks = append(checks, models.VerificationCheck{
-				CheckName:   "Redis Health Check",
-				Description: "Verify Redis is responding",
-				Passed:      false,
-				Result:      "Failed to connect to health monitor",
-				Expected:    "Health > 70%",
-			})
-			return checks
-		}
-		defer resp.Body.Close()
-
-		var status struct {
-			Services map[string]struct {
-				Health float64 `json:"health"`
-				Status string  `json:"status"`
-			} `json:"services"`
-		}
-
-		body, _ := ioutil.ReadAll(resp.Body)
-		json.Unmarshal(body, &status)
-
-		redisHealth := status.Services["redis-test"].Health
-		passed := redisHealth >= 70
-
-		checks = append(checks, models.VerificationCheck{
-			CheckName:   "Redis Health Check",
-			Description: "Verify Redis memory is below threshold",
-			Passed:      passed,
-			Result:      fmt.Sprintf("Health: %.0f%%", redisHealth),
-			Expected:    "Health >= 70%",
-		})
-
-		checks = append(checks, models.VerificationCheck{
-			CheckName:   "Redis Availability",
-			Description: "Verify Redis is responding to commands",
-			Passed:      status.Services["redis-test"].Status == "healthy",
-			Result:      status.Services["redis-test"].Status,
-			Expected:    "healthy",
-		})
+	action, ok := GetAction(actionName)
+	if !ok || len(action.Checks) == 0 {
+		return checks
 	}
 
-	// For PostgreSQL actions, check health
-	if action == "kill_idle_connections" || action == "vacuum_table" || action == "restart_postgres" {
-		healthMonitorURL := os.Getenv("HEALTH_MONITOR_URL")
-		if healthMonitorURL == "" {
-			healthMonitorURL = "http://localhost:8002"
-		}
-
-		resp, err := http.Get(healthMonitorURL + "/status")
-		if err != nil {
-			checks = append(checks, models.VerificationCheck{
-				CheckName:   "PostgreSQL Health Check",
-				Description: "Verify PostgreSQL is responding",
-				Passed:      false,
-				Result:      "Failed to connect to health monitor",
-				Expected:    "Health > 70%",
-			})
-			return checks
-		}
+	var status struct {
+		Services map[string]map[string]interface{} `json:"services"`
+	}
+	resp, err := httpGet(ctx, healthMonitorURL()+"/status")
+	if err == nil {
 		defer resp.Body.Close()
-
-		var status struct {
-			Services map[string]struct {
-				Health            float64 `json:"health"`
-				Status            string  `json:"status"`
-				IdleConnections   float64 `json:"idle_connections"`
-				ActiveConnections float64 `json:"active_connections"`
-				TotalConnections  float64 `json:"total_connections"`
-			} `json:"services"`
-		}
-
 		body, _ := ioutil.ReadAll(resp.Body)
-		json.Unmarshal(body, &status)
-
-		postgresHealth := status.Services["postgres-test"].Health
-		idleConns := int(status.Services["postgres-test"].IdleConnections)
-		passed := postgresHealth >= 70
-
-		checks = append(checks, models.VerificationCheck{
-			CheckName:   "PostgreSQL Health Check",
-			Description: "Verify PostgreSQL connection pool is healthy",
-			Passed:      passed,
-			Result:      fmt.Sprintf("Health: %.0f%%", postgresHealth),
-			Expected:    "Health >= 70%",
-		})
-
-		checks = append(checks, models.VerificationCheck{
-			CheckName:   "Idle Connections",
-			Description: "Verify idle connections are at acceptable level",
-			Passed:      idleConns <= 8,
-			Result:      fmt.Sprintf("%d idle connections", idleConns),
-			Expected:    "<= 8 idle connections",
-		})
-
-		checks = append(checks, models.VerificationCheck{
-			CheckName:   "PostgreSQL Availability",
-			Description: "Verify PostgreSQL is responding to queries",
-			Passed:      status.Services["postgres-test"].Status == "healthy",
-			Result:      status.Services["postgres-test"].Status,
-			Expected:    "healthy",
-		})
+		err = json.Unmarshal(body, &status)
 	}
 
-	// For disk cleanup actions, check disk space
-	if action == "cleanup_old_logs" {
-		healthMonitorURL := os.Getenv("HEALTH_MONITOR_URL")
-		if healthMonitorURL == "" {
-			healthMonitorURL = "http://localhost:8002"
+	for _, spec := range action.Checks {
+		check := models.VerificationCheck{
+			CheckName:   spec.Name,
+			Description: spec.Description,
+			Expected:    spec.Expected,
 		}
-
-		resp, err := http.Get(healthMonitorURL + "/status")
 		if err != nil {
-			checks = append(checks, models.VerificationCheck{
-				CheckName:   "Disk Space Check",
-				Description: "Verify disk space is available",
-				Passed:      false,
-				Result:      "Failed to connect to health monitor",
-				Expected:    "Health > 70%",
-			})
-			return checks
-		}
-		defer resp.Body.Close()
-
-		var status struct {
-			Services map[string]struct {
-				Health      float64 `json:"health"`
-				Status      string  `json:"status"`
-				UsedPercent float64 `json:"used_percent"`
-				FreeMB      float64 `json:"free_mb"`
-			} `json:"services"`
+			check.Result = "Failed to connect to health monitor"
+			checks = append(checks, check)
+			continue
 		}
+		check.Passed, check.Result = evaluateCheck(spec, status.Services[spec.Service][spec.Field])
+		checks = append(checks, check)
+	}
+	return checks
+}
 
-		body, _ := ioutil.ReadAll(resp.Body)
-		json.Unmarshal(body, &status)
-
-		diskHealth := status.Services["disk-space"].Health
-		usedPercent := status.Services["disk-space"].UsedPercent
-		freeMB := status.Services["disk-space"].FreeMB
-		passed := diskHealth >= 70
-
-		checks = append(checks, models.VerificationCheck{
-			CheckName:   "Disk Space Health",
-			Description: "Verify disk space is below threshold",
-			Passed:      passed,
-			Result:      fmt.Sprintf("Health: %.0f%% (Used: %.1f%%)", diskHealth, usedPercent),
-			Expected:    "Health >= 70% (Used < 60%)",
-		})
-
-		checks = append(checks, models.VerificationCheck{
-			CheckName:   "Free Space Available",
-			Description: "Verify sufficient free space",
-			Passed:      freeMB > 100,
-			Result:      fmt.Sprintf("%.0f MB free", freeMB),
-			Expected:    "> 100 MB free",
-		})
-
-		checks = append(checks, models.VerificationCheck{
-			CheckName:   "Disk Availability",
-			Description: "Verify disk is accessible",
-			Passed:      status.Services["disk-space"].Status == "healthy",
-			Result:      status.Services["disk-space"].Status,
-			Expected:    "healthy",
-		})
+// evaluateCheck compares an observed value with the check's threshold
+func evaluateCheck(spec CheckSpec, observed interface{}) (bool, string) {
+	if observed == nil {
+		return false, fmt.Sprintf("%s not reported for %s", spec.Field, spec.Service)
+	}
+	result := fmt.Sprintf("%v", observed)
+	if spec.Format != "" {
+		result = fmt.Sprintf(spec.Format, observed)
 	}
 
-	return checks
+	if spec.Op == "==" {
+		return fmt.Sprintf("%v", observed) == fmt.Sprintf("%v", spec.Value), result
+	}
+	actual, ok := observed.(float64)
+	threshold, thresholdOK := spec.Value.(float64)
+	if !ok || !thresholdOK {
+		return false, result
+	}
+	switch spec.Op {
+	case ">=":
+		return actual >= threshold, result
+	case "<=":
+		return actual <= threshold, result
+	case ">":
+		return actual > threshold, result
+	case "<":
+		return actual < threshold, result
+	}
+	return false, result
 }
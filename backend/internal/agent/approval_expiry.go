@@ -0,0 +1,240 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+	wshub "github.com/tri27pham/incident-management-simulator/backend/internal/websocket"
+)
+
+const (
+	approvalExpiryInterval = time.Minute
+	preflightTimeout       = 10 * time.Second
+)
+
+// ErrApprovalExpired is returned when approving an execution whose approval window has passed
+var ErrApprovalExpired = errors.New("approval window has expired")
+
+// ErrStalePlan is returned when the targets changed enough since the preview that the approver must re-confirm
+var ErrStalePlan = errors.New("system state changed since the plan was previewed")
+
+// approvalTTL is how long an execution may wait for approval (AGENT_APPROVAL_TTL_MINUTES, default 30)
+func approvalTTL() time.Duration {
+	if minutes, err := strconv.Atoi(os.Getenv("AGENT_APPROVAL_TTL_MINUTES")); err == nil && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return 30 * time.Minute
+}
+
+// preflightHealthDelta is how many health points a target may move before the plan counts as stale
+// (AGENT_PREFLIGHT_HEALTH_DELTA, default 20)
+func preflightHealthDelta() float64 {
+	if delta, err := strconv.ParseFloat(os.Getenv("AGENT_PREFLIGHT_HEALTH_DELTA"), 64); err == nil && delta > 0 {
+		return delta
+	}
+	return 20
+}
+
+// systemState is what the pre-flight check compares for one monitored service
+type systemState struct {
+	Health float64 `json:"health"`
+	Status string  `json:"status"`
+}
+
+// monitoredServices lists the health-monitor services the plan's verification checks read
+func monitoredServices(execution *models.AgentExecution) []string {
+	var names []string
+	for _, step := range planOf(execution) {
+		action, _ := GetAction(step.Action)
+		for _, check := range action.Checks {
+			if !containsString(names, check.Service) {
+				names = append(names, check.Service)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fetchSystemState reads the current health of the named services from health-monitor /status
+func fetchSystemState(ctx context.Context, names []string) (map[string]systemState, error) {
+	ctx, cancel := context.WithTimeout(ctx, preflightTimeout)
+	defer cancel()
+
+	resp, err := httpGet(ctx, healthMonitorURL()+"/status")
+	if err != nil {
+		return nil, fmt.Errorf("health monitor unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	var status struct {
+		Services map[string]systemState `json:"services"`
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, fmt.Errorf("invalid health monitor status: %w", err)
+	}
+
+	state := map[string]systemState{}
+	for _, name := range names {
+		if s, ok := status.Services[name]; ok {
+			state[name] = s
+		}
+	}
+	return state, nil
+}
+
+func previewStateOf(execution *models.AgentExecution) map[string]systemState {
+	state := map[string]systemState{}
+	raw, _ := json.Marshal(execution.PreviewState.Data)
+	json.Unmarshal(raw, &state)
+	return state
+}
+
+// openApprovalWindow records the state the approver is shown and when the approval expires
+func openApprovalWindow(ctx context.Context, execution *models.AgentExecution) {
+	capturePreviewState(ctx, execution)
+	expires := time.Now().Add(approvalTTL())
+	execution.ApprovalExpiresAt = &expires
+}
+
+// capturePreviewState records the current state of the services the plan is verified against. Without
+// a reachable health monitor the preview state is empty and the pre-flight check is skipped.
+func capturePreviewState(ctx context.Context, execution *models.AgentExecution) {
+	state, err := fetchSystemState(ctx, monitoredServices(execution))
+	if err != nil {
+		log.Printf("⚠️  [Agent] No preview state for execution %s: %v", execution.ID.String()[:8], err)
+		state = map[string]systemState{}
+	}
+	execution.PreviewState = models.JSONB{Data: state}
+}
+
+// preflightCheck compares the targets' current state with the preview and lists what changed significantly
+func preflightCheck(ctx context.Context, execution *models.AgentExecution) ([]string, error) {
+	preview := previewStateOf(execution)
+	if len(preview) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(preview))
+	for name := range preview {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	current, err := fetchSystemState(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	var changes []string
+	for _, name := range names {
+		before := preview[name]
+		now, ok := current[name]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("%s is no longer reported by the health monitor", name))
+		case now.Status != before.Status:
+			changes = append(changes, fmt.Sprintf("%s is now %s (was %s)", name, now.Status, before.Status))
+		case math.Abs(now.Health-before.Health) >= preflightHealthDelta():
+			changes = append(changes, fmt.Sprintf("%s health is now %.0f (was %.0f)", name, now.Health, before.Health))
+		}
+	}
+	return changes, nil
+}
+
+// checkBeforeApproval refuses approvals after the window closed, and plans whose targets changed since the
+// preview unless the approver confirms the plan still applies
+func (s *AgentService) checkBeforeApproval(execution *models.AgentExecution, actor string, confirmStale bool) error {
+	if execution.ApprovalExpiresAt != nil && time.Now().After(*execution.ApprovalExpiresAt) {
+		s.expireApproval(execution)
+		return fmt.Errorf("%w: the plan waited more than %s; start a new remediation", ErrApprovalExpired, approvalTTL())
+	}
+
+	changes, err := preflightCheck(context.Background(), execution)
+	if err != nil {
+		changes = []string{"current state could not be checked (" + err.Error() + ")"}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	if !confirmStale {
+		return fmt.Errorf("%w: %s", ErrStalePlan, strings.Join(changes, "; "))
+	}
+	services.RecordTimelineEvent(execution.IncidentID, "agent_stale_plan_confirmed", actor,
+		fmt.Sprintf("Approved %s although the system changed since the preview: %s", execution.RecommendedAction, strings.Join(changes, "; ")),
+		map[string]interface{}{"execution_id": execution.ID, "changes": changes})
+	return nil
+}
+
+// expireApproval cancels an execution whose approval window has passed. Only the first caller to
+// expire it records and broadcasts the expiry.
+func (s *AgentService) expireApproval(execution *models.AgentExecution) bool {
+	now := time.Now()
+	reason := fmt.Sprintf("Approval expired after %s without a decision", approvalTTL())
+	result := db.DB.Model(&models.AgentExecution{}).
+		Where("id = ? AND status = ?", execution.ID, models.StatusAwaitingApproval).
+		Updates(map[string]interface{}{
+			"status":        models.StatusCancelled,
+			"error_message": reason,
+			"cancelled_by":  "system",
+			"cancel_reason": reason,
+			"cancelled_at":  now,
+		})
+	if result.Error != nil {
+		log.Printf("❌ [Agent] Failed to expire execution %s: %v", execution.ID.String()[:8], result.Error)
+		return false
+	}
+	if result.RowsAffected == 0 {
+		return false
+	}
+	execution.Status = models.StatusCancelled
+	execution.ErrorMessage = reason
+	releaseTargetLeases(execution.ID)
+
+	log.Printf("⌛ [Agent] Execution %s expired awaiting approval", execution.ID.String()[:8])
+	services.RecordTimelineEvent(execution.IncidentID, "agent_approval_expired", "system",
+		fmt.Sprintf("Agent action %s was not approved within %s and was cancelled", execution.RecommendedAction, approvalTTL()),
+		map[string]interface{}{"execution_id": execution.ID})
+	wshub.WSHub.Broadcast <- map[string]interface{}{
+		"type":         "agent_approval_expired",
+		"execution_id": execution.ID,
+		"incident_id":  execution.IncidentID,
+		"action":       execution.RecommendedAction,
+		"message":      reason,
+	}
+	return true
+}
+
+// StartApprovalExpiry cancels executions left awaiting approval past their deadline
+func StartApprovalExpiry() {
+	log.Printf("⌛ Agent approval expiry started (every %s, TTL %s)", approvalExpiryInterval, approvalTTL())
+	s := NewAgentService()
+	ticker := time.NewTicker(approvalExpiryInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		var executions []models.AgentExecution
+		// Executions that started waiting before the TTL existed expire a TTL after their last update
+		now := time.Now()
+		if err := db.DB.Where("status = ?", models.StatusAwaitingApproval).
+			Where("approval_expires_at <= ? OR (approval_expires_at IS NULL AND updated_at <= ?)", now, now.Add(-approvalTTL())).
+			Find(&executions).Error; err != nil {
+			log.Printf("❌ Failed to load executions awaiting approval: %v", err)
+			continue
+		}
+		for i := range executions {
+			s.expireApproval(&executions[i])
+		}
+	}
+}
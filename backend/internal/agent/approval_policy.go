@@ -0,0 +1,200 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// PolicyActor is recorded as the approver when the policy approves an execution by itself
+const PolicyActor = "approval-policy"
+
+// anonymousActor is who an approval comes from when the request names no user
+const anonymousActor = "anonymous"
+
+// ChatActorSuffix marks decisions made from a signed chat or email link
+const ChatActorSuffix = " (via chat)"
+
+// ErrAlreadyApproved is returned when the same person approves an execution twice
+var ErrAlreadyApproved = errors.New("already approved by this approver")
+
+// ErrApproverRequired is returned when an approval that counts toward the two-person rule names no approver
+var ErrApproverRequired = errors.New("approval requires a named approver")
+
+// riskApprovals is how many distinct approvers each catalog risk level needs
+var riskApprovals = map[string]int{
+	"low":    0,
+	"medium": 1,
+	"high":   2,
+}
+
+// evaluateApprovalPolicy decides how many distinct approvers the plan needs. The base comes from the
+// highest risk level the catalog declares for the plan's actions; the target systems, incident severity
+// and time of day can only raise it. The preview's "cannot be rolled back" note is not counted: the
+// catalog's own levels already account for what an action changes.
+func evaluateApprovalPolicy(execution *models.AgentExecution, incident *models.Incident, now time.Time) models.ApprovalDecision {
+	decision := models.ApprovalDecision{RiskLevel: "low", EvaluatedAt: now}
+	require := func(approvals int, reason string) {
+		if approvals > decision.RequiredApprovals {
+			decision.RequiredApprovals = approvals
+		}
+		decision.Reasons = append(decision.Reasons, reason)
+	}
+
+	steps := planOf(execution)
+	for _, step := range steps {
+		action, _ := GetAction(step.Action)
+		for _, risk := range action.Risks {
+			if riskApprovals[risk.Level] > riskApprovals[decision.RiskLevel] {
+				decision.RiskLevel = risk.Level
+			}
+		}
+	}
+	require(riskApprovals[decision.RiskLevel], fmt.Sprintf("Highest action risk is %s", decision.RiskLevel))
+
+	minimums := systemMinimumApprovals()
+	for _, target := range planTargets(steps, incident) {
+		if n := minimums[target]; n > 0 {
+			require(n, fmt.Sprintf("%s requires at least %d approver(s)", target, n))
+		}
+	}
+
+	if incident.Analysis != nil && incident.Analysis.Severity == "high" {
+		require(1, "High-severity incidents are never auto-approved")
+	}
+
+	if decision.RequiredApprovals == 0 && !inAutoApproveHours(now) {
+		require(1, fmt.Sprintf("Outside auto-approval hours (%s)", os.Getenv("AGENT_AUTO_APPROVE_HOURS")))
+	}
+
+	if decision.RequiredApprovals > 2 {
+		decision.RequiredApprovals = 2
+	}
+	return decision
+}
+
+// systemMinimumApprovals reads per-system minimums from AGENT_APPROVAL_SYSTEM_MINIMUMS,
+// e.g. "postgres-test=1,redis-test=2". Unlisted systems have no minimum.
+func systemMinimumApprovals() map[string]int {
+	return parseNameCounts(os.Getenv("AGENT_APPROVAL_SYSTEM_MINIMUMS"))
+}
+
+// inAutoApproveHours reports whether low-risk plans may auto-approve at t. AGENT_AUTO_APPROVE_HOURS is an
+// "HH:MM-HH:MM" window in AGENT_APPROVAL_TIMEZONE (default UTC); unset or invalid allows any time.
+func inAutoApproveHours(t time.Time) bool {
+	window := strings.SplitN(os.Getenv("AGENT_AUTO_APPROVE_HOURS"), "-", 2)
+	if len(window) != 2 {
+		return true
+	}
+	start, err1 := time.Parse("15:04", strings.TrimSpace(window[0]))
+	end, err2 := time.Parse("15:04", strings.TrimSpace(window[1]))
+	if err1 != nil || err2 != nil || start.Equal(end) {
+		return true
+	}
+
+	loc, err := time.LoadLocation(os.Getenv("AGENT_APPROVAL_TIMEZONE"))
+	if err != nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	now := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from < to {
+		return now >= from && now < to
+	}
+	return now >= from || now < to // Wraps past midnight
+}
+
+func approvalDecisionOf(execution *models.AgentExecution) models.ApprovalDecision {
+	var decision models.ApprovalDecision
+	raw, _ := json.Marshal(execution.ApprovalPolicy.Data)
+	json.Unmarshal(raw, &decision)
+	return decision
+}
+
+func approvalsOf(execution *models.AgentExecution) []models.Approval {
+	var approvals []models.Approval
+	raw, _ := json.Marshal(execution.Approvals.Data)
+	json.Unmarshal(raw, &approvals)
+	return approvals
+}
+
+// requiredApprovals is how many approvers the execution needs before it runs. Executions planned before
+// the policy existed have no decision and keep the old single approval.
+func requiredApprovals(execution *models.AgentExecution) int {
+	decision := approvalDecisionOf(execution)
+	if decision.EvaluatedAt.IsZero() || decision.RequiredApprovals < 1 {
+		return 1
+	}
+	return decision.RequiredApprovals
+}
+
+// addApproval records actor's sign-off on the selected steps (nil: all of them), refusing a second
+// approval from the same person and unnamed approvers where the two-person rule applies
+func addApproval(execution *models.AgentExecution, actor string, selected []int, now time.Time) ([]models.Approval, error) {
+	approvals := approvalsOf(execution)
+	identity := canonicalActor(actor)
+	if requiredApprovals(execution) > 1 && (identity == "" || identity == anonymousActor) {
+		return nil, fmt.Errorf("%w: this plan needs %d distinct approvers, so each must identify themselves", ErrApproverRequired, requiredApprovals(execution))
+	}
+	for _, approval := range approvals {
+		if canonicalActor(approval.Actor) == identity {
+			return nil, fmt.Errorf("%w %s; a different approver is needed", ErrAlreadyApproved, approval.Actor)
+		}
+	}
+
+	steps, err := approvePlanSteps(make([]models.PlanStep, len(planOf(execution))), selected)
+	if err != nil {
+		return nil, err
+	}
+	return append(approvals, models.Approval{Actor: actor, Steps: approvedStepIndexes(steps), At: now}), nil
+}
+
+// canonicalActor is the identity the two-person rule compares. Approver names are display names, so
+// "Alice", " alice " and "alice (via chat)" are the same person.
+func canonicalActor(actor string) string {
+	name := strings.ToLower(strings.Join(strings.Fields(actor), " "))
+	name = strings.TrimSuffix(name, strings.TrimSpace(ChatActorSuffix))
+	return strings.TrimSpace(name)
+}
+
+// applyApprovals approves each plan step that every approver approved
+func applyApprovals(steps []models.PlanStep, approvals []models.Approval) ([]models.PlanStep, error) {
+	for i := range steps {
+		steps[i].Approved = true
+		for _, approval := range approvals {
+			if !containsInt(approval.Steps, i) {
+				steps[i].Approved = false
+				break
+			}
+		}
+	}
+	if len(steps) > 0 && len(approvedStepIndexes(steps)) == 0 {
+		return nil, fmt.Errorf("%w: the approvers selected no step in common", ErrInvalidStepSelection)
+	}
+	return steps, nil
+}
+
+// approverNames lists the approvers for ApprovedBy
+func approverNames(approvals []models.Approval) string {
+	names := make([]string, len(approvals))
+	for i, approval := range approvals {
+		names[i] = approval.Actor
+	}
+	return strings.Join(names, ", ")
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+func TestCanonicalActor(t *testing.T) {
+	tests := map[string]string{
+		"alice":                    "alice",
+		"  Alice ":                 "alice",
+		"ALICE (via chat)":         "alice",
+		"alice(via chat)":          "alice",
+		"Alice  Smith":             "alice smith",
+		"alice\t smith (VIA CHAT)": "alice smith",
+		" (via chat)":              "",
+		"":                         "",
+	}
+	for actor, want := range tests {
+		if got := canonicalActor(actor); got != want {
+			t.Errorf("canonicalActor(%q) = %q, want %q", actor, got, want)
+		}
+	}
+}
+
+// twoPersonExecution needs two distinct approvers and already has one from first
+func twoPersonExecution(first string) *models.AgentExecution {
+	execution := &models.AgentExecution{
+		Plan:           models.JSONB{Data: []models.PlanStep{{Action: "restart_redis"}}},
+		ApprovalPolicy: models.JSONB{Data: models.ApprovalDecision{RiskLevel: "high", RequiredApprovals: 2, EvaluatedAt: time.Now()}},
+	}
+	if first != "" {
+		execution.Approvals = models.JSONB{Data: []models.Approval{{Actor: first, Steps: []int{0}, At: time.Now()}}}
+	}
+	return execution
+}
+
+func TestAddApprovalTwoPersonRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		first   string
+		second  string
+		wantErr error
+	}{
+		{"different people", "alice", "bob", nil},
+		{"same name", "alice", "alice", ErrAlreadyApproved},
+		{"different case", "alice", "Alice", ErrAlreadyApproved},
+		{"padded name", "alice", "  alice ", ErrAlreadyApproved},
+		{"dashboard then chat link", "alice", "Alice (via chat)", ErrAlreadyApproved},
+		{"chat link then dashboard", "alice (via chat)", "ALICE", ErrAlreadyApproved},
+		{"anonymous", "", "anonymous", ErrApproverRequired},
+		{"anonymous in another case", "alice", " Anonymous ", ErrApproverRequired},
+		{"blank chat approver", "alice", " (via chat)", ErrApproverRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			approvals, err := addApproval(twoPersonExecution(tt.first), tt.second, nil, time.Now())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("addApproval = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || len(approvals) != 2 || approvals[1].Actor != tt.second {
+				t.Errorf("addApproval = %v, %v; want a second approval from %s", approvals, err, tt.second)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// Per-phase deadlines. Each phase derives its context from the job's, so cancelling
+// the execution or hitting the job timeout also stops the phase.
+const (
+	thinkingPhaseTimeout     = 90 * time.Second
+	executionPhaseTimeout    = 2 * time.Minute
+	verificationPhaseTimeout = 45 * time.Second
+	agentHTTPTimeout         = 30 * time.Second // Upper bound for any single outbound call
+)
+
+var agentHTTPClient = &http.Client{Timeout: agentHTTPTimeout}
+
+// ErrExecutionStopped is returned by a phase when the execution was cancelled or finished elsewhere
+var ErrExecutionStopped = errors.New("execution is no longer running")
+
+// ErrNotCancellable is returned when cancelling an execution that has already finished
+var ErrNotCancellable = errors.New("execution has already finished")
+
+// ErrCancelReasonRequired is returned when cancelling without saying why
+var ErrCancelReasonRequired = errors.New("reason is required")
+
+// terminalStatuses are final; nothing may write over an execution once it reaches one
+var terminalStatuses = []models.AgentExecutionStatus{
+	models.StatusCompleted,
+	models.StatusFailed,
+	models.StatusCancelled,
+}
+
+// saveProgress persists the execution unless it reached a terminal status meanwhile (e.g. it was
+// cancelled while this phase was running), so a running workflow can never overwrite a cancellation
+func (s *AgentService) saveProgress(execution *models.AgentExecution) error {
+	return s.saveProgressTx(db.DB, execution)
+}
+
+func (s *AgentService) saveProgressTx(tx *gorm.DB, execution *models.AgentExecution) error {
+	result := tx.Model(execution).
+		Where("status NOT IN ?", terminalStatuses).
+		Select("*").Omit("created_at").
+		Updates(execution)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrExecutionStopped
+	}
+	return nil
+}
+
+// saveProgressAndPublish saves the execution as saveProgress does and records eventType for it in the
+// same transaction, so the event's webhooks exist exactly when the new state does
+func (s *AgentService) saveProgressAndPublish(execution *models.AgentExecution, eventType string) error {
+	var outbox services.EventOutbox
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.saveProgressTx(tx, execution); err != nil {
+			return err
+		}
+		return outbox.Add(tx, eventType, *execution)
+	}); err != nil {
+		return err
+	}
+	outbox.Dispatch()
+	return nil
+}
+
+// saveLogs writes only the log and plan columns, with the same terminal-status guard as saveProgress
+func saveLogs(execution *models.AgentExecution) error {
+	result := db.DB.Model(&models.AgentExecution{}).
+		Where("id = ? AND status NOT IN ?", execution.ID, terminalStatuses).
+		Updates(map[string]interface{}{
+			"execution_logs": execution.ExecutionLogs,
+			"plan":           execution.Plan,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrExecutionStopped
+	}
+	return nil
+}
+
+// executionFinished reports whether the execution is in a terminal status in the database
+func executionFinished(id uuid.UUID) bool {
+	var execution models.AgentExecution
+	if err := db.DB.Select("status").First(&execution, "id = ?", id).Error; err != nil {
+		return false
+	}
+	for _, status := range terminalStatuses {
+		if execution.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	runningExecutionsMu sync.Mutex
+	runningExecutions   = map[uuid.UUID]context.CancelFunc{}
+)
+
+// trackExecution makes a running workflow cancellable by CancelExecution in this process
+func trackExecution(ctx context.Context, id uuid.UUID) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	runningExecutionsMu.Lock()
+	runningExecutions[id] = cancel
+	runningExecutionsMu.Unlock()
+
+	return ctx, func() {
+		runningExecutionsMu.Lock()
+		delete(runningExecutions, id)
+		runningExecutionsMu.Unlock()
+		cancel()
+	}
+}
+
+// CancelExecution stops an execution in any non-terminal phase. A workflow running in this process
+// is interrupted immediately; one running on another instance stops at its next save, which while
+// executing is the log written after its current command.
+func (s *AgentService) CancelExecution(executionID uuid.UUID, actor, reason string) (*models.AgentExecution, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrCancelReasonRequired
+	}
+
+	var execution models.AgentExecution
+	if err := db.DB.First(&execution, "id = ?", executionID).Error; err != nil {
+		return nil, ErrExecutionNotFound
+	}
+
+	now := time.Now()
+	result := db.DB.Model(&models.AgentExecution{}).
+		Where("id = ? AND status NOT IN ?", executionID, terminalStatuses).
+		Updates(map[string]interface{}{
+			"status":        models.StatusCancelled,
+			"success":       false,
+			"error_message": fmt.Sprintf("Cancelled by %s: %s", actor, reason),
+			"cancelled_by":  actor,
+			"cancel_reason": reason,
+			"cancelled_at":  now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to cancel execution: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrNotCancellable
+	}
+
+	// A workflow running here releases its leases once its current command has stopped. Otherwise
+	// nothing in this process holds them, so they are released now rather than left to expire.
+	runningExecutionsMu.Lock()
+	cancel, running := runningExecutions[executionID]
+	if running {
+		cancel()
+	}
+	runningExecutionsMu.Unlock()
+	if !running {
+		releaseTargetLeases(executionID)
+	}
+
+	log.Printf("🛑 [Agent] Execution %s cancelled by %s while %s: %s", executionID.String()[:8], actor, execution.Status, reason)
+	services.RecordTimelineEvent(execution.IncidentID, "agent_cancelled", actor,
+		fmt.Sprintf("Cancelled agent action %s while %s: %s", execution.RecommendedAction, execution.Status, reason),
+		map[string]interface{}{"execution_id": execution.ID, "status": execution.Status, "reason": reason})
+
+	if err := db.DB.First(&execution, "id = ?", executionID).Error; err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+func httpPost(ctx context.Context, url string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return agentHTTPClient.Do(req)
+}
+
+func httpGet(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return agentHTTPClient.Do(req)
+}
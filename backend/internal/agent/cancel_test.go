@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// A command's log is saved while the execution runs, but not once it was cancelled, which is
+// what stops the command loop of a workflow running on another instance
+func TestSaveLogsStopsAfterCancel(t *testing.T) {
+	openTestDB(t)
+	incident := seedIncident(t)
+	execution := seedExecution(t, incident, func(e *models.AgentExecution) {
+		e.Status = models.StatusExecuting
+	})
+
+	execution.ExecutionLogs = models.JSONB{Data: []models.ExecutionLog{{Command: "FLUSHALL", Status: "success"}}}
+	if err := saveLogs(execution); err != nil {
+		t.Fatalf("saveLogs while executing: %v", err)
+	}
+
+	s := &AgentService{}
+	if _, err := s.CancelExecution(execution.ID, "alice", "wrong target"); err != nil {
+		t.Fatalf("CancelExecution: %v", err)
+	}
+
+	execution.ExecutionLogs = models.JSONB{Data: []models.ExecutionLog{
+		{Command: "FLUSHALL", Status: "success"},
+		{Command: "INFO memory", Status: "success"},
+	}}
+	if err := saveLogs(execution); !errors.Is(err, ErrExecutionStopped) {
+		t.Fatalf("saveLogs after cancel = %v, want ErrExecutionStopped", err)
+	}
+
+	stored := reloadExecution(t, execution.ID)
+	if stored.Status != models.StatusCancelled {
+		t.Errorf("status = %s, want %s", stored.Status, models.StatusCancelled)
+	}
+	logs, _ := stored.ExecutionLogs.Data.([]interface{})
+	if len(logs) != 1 {
+		t.Errorf("stored %d log entries, want the 1 written before the cancellation", len(logs))
+	}
+}
@@ -0,0 +1,471 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// healthMonitorPlaceholder in a command argument is replaced with the health monitor's URL when
+// the command is generated, since the catalog is declared before the environment is loaded
+const healthMonitorPlaceholder = "{health_monitor}"
+
+// ActionSpec declares a remediation action once. The thinking prompt, the system registry's
+// allowed actions, the command preview, execution, verification and rollback are all built from it.
+type ActionSpec struct {
+	Name    string   `json:"name"`
+	Systems []string `json:"systems"` // Systems the action can target; it is offered only when one is affected
+
+	// Shown to the model in the thinking prompt
+	Description string `json:"description"`
+	Impact      string `json:"impact"`
+	Risk        string `json:"risk"`
+	UseWhen     string `json:"use_when"`
+
+	// Shown to the approver in the command preview
+	EstimatedImpact string           `json:"estimated_impact"`
+	Risks           []models.Risk    `json:"risks"`
+	Commands        []models.Command `json:"commands"`
+
+	Params   []ParamSpec         `json:"params,omitempty"` // What an approver may tune when editing the plan
+	Checks   []CheckSpec         `json:"checks"`           // Verification after execution
+	Rollback models.RollbackPlan `json:"rollback"`         // Snapshot and compensation
+}
+
+// ParamSpec is a whole-number parameter of an action's commands. The value is passed to every command
+// of the action as metadata under Name, so executors read it like any other command setting.
+type ParamSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     int    `json:"default"`
+	Min         int    `json:"min"`
+	Max         int    `json:"max"`
+}
+
+// CheckSpec is a verification check against one field of a service in the health monitor's /status
+type CheckSpec struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Service     string      `json:"service"`
+	Field       string      `json:"field"`
+	Op          string      `json:"op"` // ">=", "<=", ">", "<" for numbers; "==" for strings
+	Value       interface{} `json:"value"`
+	Expected    string      `json:"expected"`
+	Format      string      `json:"format,omitempty"` // fmt verb for the observed value, e.g. "Health: %.0f%%"
+}
+
+// httpParams are the parameters of actions that call the health monitor
+func httpParams() []ParamSpec {
+	return []ParamSpec{{
+		Name:        "timeout_seconds",
+		Description: "Give up on the call after this many seconds",
+		Default:     int(agentHTTPTimeout / time.Second),
+		Min:         5,
+		Max:         int(agentHTTPTimeout / time.Second),
+	}}
+}
+
+// restartParams are the parameters of container restarts
+func restartParams() []ParamSpec {
+	return []ParamSpec{
+		{
+			Name:        "stop_timeout_seconds",
+			Description: "Seconds the container gets to stop before it is killed",
+			Default:     dockerStopTimeoutSeconds,
+			Min:         0,
+			Max:         dockerMaxStopTimeoutSeconds,
+		},
+		{
+			Name:        "health_wait_seconds",
+			Description: "Seconds to wait for the restarted container to report healthy",
+			Default:     int(dockerHealthWait / time.Second),
+			Min:         5,
+			Max:         int(dockerMaxHealthWait / time.Second),
+		},
+	}
+}
+
+func snapshotCommand(target string) models.Command {
+	return models.Command{
+		Name:        "Snapshot " + target,
+		Command:     "http_get",
+		Args:        []string{healthMonitorPlaceholder + "/snapshot/" + target},
+		Target:      target,
+		Description: "Capture current state before making changes",
+	}
+}
+
+// preCheckCommand reads the target's state right before the action changes it, so a step whose target
+// the health monitor cannot see fails before doing anything. Approvers cannot drop it from a plan.
+func preCheckCommand(target string) models.Command {
+	return models.Command{
+		Name:        "Pre-check " + target,
+		Command:     "http_get",
+		Args:        []string{healthMonitorPlaceholder + "/snapshot/" + target},
+		Target:      target,
+		Description: "Confirm the target is reachable before changing it",
+		Required:    true,
+	}
+}
+
+func startContainerCommand(container string) models.Command {
+	return models.Command{
+		Name:        "Start " + container + " Container",
+		Command:     "docker",
+		Args:        []string{"start", container},
+		Target:      container,
+		Description: "Make sure the container is running again",
+	}
+}
+
+func healthCheck(name, service string) CheckSpec {
+	return CheckSpec{
+		Name:        name,
+		Description: "Verify the service health score is back above threshold",
+		Service:     service,
+		Field:       "health",
+		Op:          ">=",
+		Value:       70.0,
+		Expected:    "Health >= 70%",
+		Format:      "Health: %.0f%%",
+	}
+}
+
+func availabilityCheck(name, service string) CheckSpec {
+	return CheckSpec{
+		Name:        name,
+		Description: "Verify the service is responding",
+		Service:     service,
+		Field:       "status",
+		Op:          "==",
+		Value:       "healthy",
+		Expected:    "healthy",
+	}
+}
+
+// ActionCatalog is every action agents may take, in the order they are offered to the model
+var ActionCatalog = []ActionSpec{
+	{
+		Name:            "clear_redis_cache",
+		Systems:         []string{"redis-test"},
+		Description:     "Clears all keys from Redis using FLUSHALL",
+		Impact:          "Immediate memory recovery, ~1-2 second operation",
+		Risk:            "Active sessions/cached data will be lost (medium severity)",
+		UseWhen:         "Redis memory is critically high but service is responsive",
+		EstimatedImpact: "Redis memory will be freed. Active sessions may be temporarily disrupted.",
+		Risks: []models.Risk{
+			{Level: "medium", Description: "Active user sessions will be cleared", Mitigation: "Sessions will be recreated automatically"},
+		},
+		Commands: []models.Command{preCheckCommand("redis-test"), {
+			Name:        "Clear Redis Cache",
+			Command:     "http_post",
+			Args:        []string{healthMonitorPlaceholder + "/clear/redis"},
+			Target:      "redis-test",
+			Description: "Clear all keys from Redis to free up memory",
+		}},
+		Params: httpParams(),
+		Checks: []CheckSpec{
+			healthCheck("Redis Health Check", "redis-test"),
+			availabilityCheck("Redis Availability", "redis-test"),
+		},
+		Rollback: models.RollbackPlan{
+			Mode:     RollbackNone,
+			Snapshot: []models.Command{snapshotCommand("redis-test")},
+			Notes:    "FLUSHALL cannot be undone; the snapshot records how many keys were dropped",
+		},
+	},
+	{
+		Name:            "restart_redis",
+		Systems:         []string{"redis-test"},
+		Description:     "Restarts the Redis container",
+		Impact:          "2-3 second downtime, complete service interruption",
+		Risk:            "Brief outage for all Redis-dependent services (high severity)",
+		UseWhen:         "Redis is unresponsive, crashed, or in an error state that cache clearing won't fix",
+		EstimatedImpact: "Redis will be unavailable for 2-3 seconds during restart.",
+		Risks: []models.Risk{
+			{Level: "high", Description: "Brief service interruption", Mitigation: "Application has retry logic"},
+		},
+		Commands: []models.Command{preCheckCommand("redis-test"), {
+			Name:        "Restart Redis Container",
+			Command:     "docker",
+			Args:        []string{"restart", "redis-test"},
+			Target:      "redis-test",
+			Description: "Restart the Redis container to recover from error state",
+		}},
+		Params: restartParams(),
+		Checks: []CheckSpec{
+			healthCheck("Redis Health Check", "redis-test"),
+			availabilityCheck("Redis Availability", "redis-test"),
+		},
+		Rollback: models.RollbackPlan{
+			Mode:       RollbackAuto,
+			Snapshot:   []models.Command{snapshotCommand("redis-test")},
+			Compensate: []models.Command{startContainerCommand("redis-test")},
+			Notes:      "If the restart leaves Redis down, the container is started again",
+		},
+	},
+	{
+		Name:            "kill_idle_connections",
+		Systems:         []string{"postgres-test"},
+		Description:     "Terminates idle PostgreSQL connections",
+		Impact:          "Frees connection slots immediately, no query interruption",
+		Risk:            "Minimal, only idle connections affected (low severity)",
+		UseWhen:         "Connection pool is exhausted but active queries are fine",
+		EstimatedImpact: "Will terminate idle connections. Active queries will not be affected.",
+		Risks: []models.Risk{
+			{Level: "low", Description: "Only idle connections terminated", Mitigation: "Active queries continue unaffected"},
+		},
+		Commands: []models.Command{preCheckCommand("postgres-test"), {
+			Name:        "Kill Idle PostgreSQL Connections",
+			Command:     "http_post",
+			Args:        []string{healthMonitorPlaceholder + "/clear/postgres"},
+			Target:      "postgres-test",
+			Description: "Terminate idle database connections to free up connection pool",
+		}},
+		Params: httpParams(),
+		Checks: []CheckSpec{
+			healthCheck("PostgreSQL Health Check", "postgres-test"),
+			{
+				Name:        "Idle Connections",
+				Description: "Verify idle connections are at acceptable level",
+				Service:     "postgres-test",
+				Field:       "idle_connections",
+				Op:          "<=",
+				Value:       8.0,
+				Expected:    "<= 8 idle connections",
+				Format:      "%.0f idle connections",
+			},
+			availabilityCheck("PostgreSQL Availability", "postgres-test"),
+		},
+		Rollback: models.RollbackPlan{
+			Mode:     RollbackNone,
+			Snapshot: []models.Command{snapshotCommand("postgres-test")},
+			Notes:    "Terminated connections cannot be restored; clients reconnect on their own",
+		},
+	},
+	{
+		Name:            "vacuum_table",
+		Systems:         []string{"postgres-test"},
+		Description:     "Runs VACUUM ANALYZE on PostgreSQL tables",
+		Impact:          "Reclaims dead tuple space, updates statistics, brief performance impact",
+		Risk:            "Table remains accessible, slight performance degradation during operation (low severity)",
+		UseWhen:         "Table bloat from dead tuples is degrading performance",
+		EstimatedImpact: "Will reclaim space from dead tuples. Brief performance impact during execution.",
+		Risks: []models.Risk{
+			{Level: "low", Description: "Brief performance impact while VACUUM runs", Mitigation: "Operation typically completes in seconds"},
+			{Level: "low", Description: "Table remains accessible during VACUUM", Mitigation: "PostgreSQL VACUUM does not lock tables"},
+		},
+		Commands: []models.Command{preCheckCommand("postgres-test"), {
+			Name:        "Run VACUUM on PostgreSQL Table",
+			Command:     "http_post",
+			Args:        []string{healthMonitorPlaceholder + "/clear/postgres-bloat"},
+			Target:      "postgres-test",
+			Description: "Run VACUUM ANALYZE to reclaim space from dead tuples and update statistics",
+		}},
+		Params: httpParams(),
+		Checks: []CheckSpec{
+			healthCheck("PostgreSQL Health Check", "postgres-test"),
+			availabilityCheck("PostgreSQL Availability", "postgres-test"),
+		},
+		Rollback: models.RollbackPlan{
+			Mode:     RollbackNone,
+			Snapshot: []models.Command{snapshotCommand("postgres-test")},
+			Notes:    "VACUUM only reclaims dead tuples and has nothing to undo",
+		},
+	},
+	{
+		Name:            "restart_postgres",
+		Systems:         []string{"postgres-test"},
+		Description:     "Restarts the PostgreSQL container",
+		Impact:          "2-3 second downtime, all connections dropped",
+		Risk:            "Brief outage for database-dependent services (medium-high severity)",
+		UseWhen:         "PostgreSQL is unresponsive or in a corrupted state that lighter fixes won't resolve",
+		EstimatedImpact: "PostgreSQL will be unavailable for 2-3 seconds during restart.",
+		Risks: []models.Risk{
+			{Level: "medium", Description: "Brief service interruption", Mitigation: "Applications should have connection retry logic"},
+			{Level: "low", Description: "All connections will be dropped", Mitigation: "Expected behavior for restart"},
+		},
+		Commands: []models.Command{preCheckCommand("postgres-test"), {
+			Name:        "Drain Idle PostgreSQL Connections",
+			Command:     "http_post",
+			Args:        []string{healthMonitorPlaceholder + "/clear/postgres"},
+			Target:      "postgres-test",
+			Description: "Close idle connections first so clients are not cut off mid-session; optional",
+		}, {
+			Name:        "Restart PostgreSQL Container",
+			Command:     "docker",
+			Args:        []string{"restart", "postgres-test"},
+			Target:      "postgres-test",
+			Description: "Restart PostgreSQL to clear all connections and reset state",
+		}},
+		Params: restartParams(),
+		Checks: []CheckSpec{
+			healthCheck("PostgreSQL Health Check", "postgres-test"),
+			availabilityCheck("PostgreSQL Availability", "postgres-test"),
+		},
+		Rollback: models.RollbackPlan{
+			Mode:       RollbackApproval,
+			Snapshot:   []models.Command{snapshotCommand("postgres-test")},
+			Compensate: []models.Command{startContainerCommand("postgres-test")},
+			Notes:      "If the restart leaves PostgreSQL down, the container is started again once approved",
+		},
+	},
+	{
+		Name:            "cleanup_old_logs",
+		Systems:         []string{"disk-monitor"},
+		Description:     "Deletes old log files to free disk space",
+		Impact:          "Immediate disk space recovery, no service impact",
+		Risk:            "Historical logs lost (low severity - test logs only)",
+		UseWhen:         "Disk space is critically low",
+		EstimatedImpact: "Will delete test log files. No impact on running services.",
+		Risks: []models.Risk{
+			{Level: "low", Description: "Log files will be deleted", Mitigation: "Only removes test log files created for simulation"},
+			{Level: "low", Description: "No service interruption", Mitigation: "Disk cleanup happens in the background"},
+		},
+		Commands: []models.Command{preCheckCommand("disk-monitor"), {
+			Name:        "Clean Up Old Log Files",
+			Command:     "http_post",
+			Args:        []string{healthMonitorPlaceholder + "/clear/disk"},
+			Target:      "disk-monitor",
+			Description: "Remove old log files to free up disk space",
+		}},
+		Params: httpParams(),
+		Checks: []CheckSpec{
+			healthCheck("Disk Space Health", "disk-space"),
+			{
+				Name:        "Free Space Available",
+				Description: "Verify sufficient free space",
+				Service:     "disk-space",
+				Field:       "free_mb",
+				Op:          ">",
+				Value:       100.0,
+				Expected:    "> 100 MB free",
+				Format:      "%.0f MB free",
+			},
+			availabilityCheck("Disk Availability", "disk-space"),
+		},
+		Rollback: models.RollbackPlan{
+			Mode:     RollbackNone,
+			Snapshot: []models.Command{snapshotCommand("disk-monitor")},
+			Notes:    "Deleted log files cannot be restored",
+		},
+	},
+}
+
+// The registry's allowed actions per system come from the catalog
+func init() {
+	for _, action := range ActionCatalog {
+		for _, system := range action.Systems {
+			info, ok := ActionableSystems[system]
+			if !ok {
+				panic(fmt.Sprintf("action %s targets unregistered system %s", action.Name, system))
+			}
+			info.Actions = append(info.Actions, action.Name)
+			ActionableSystems[system] = info
+		}
+	}
+}
+
+// GetAction looks up an action in the catalog
+func GetAction(name string) (ActionSpec, bool) {
+	for _, action := range ActionCatalog {
+		if action.Name == name {
+			return action, true
+		}
+	}
+	return ActionSpec{}, false
+}
+
+// actionsForIncident returns the catalog actions that target one of the incident's actionable systems
+func actionsForIncident(incident *models.Incident) []ActionSpec {
+	var actions []ActionSpec
+	for _, action := range ActionCatalog {
+		for _, system := range action.Systems {
+			if IsSystemActionable(system) && containsString(incident.AffectedSystems, system) {
+				actions = append(actions, action)
+				break
+			}
+		}
+	}
+	return actions
+}
+
+// actionTarget is the first of the action's systems the incident affects
+func actionTarget(action ActionSpec, incident *models.Incident) string {
+	for _, system := range action.Systems {
+		if containsString(incident.AffectedSystems, system) {
+			return system
+		}
+	}
+	return ""
+}
+
+// promptActions renders actions as the numbered list in the thinking prompt
+func promptActions(actions []ActionSpec) string {
+	var b strings.Builder
+	for i, action := range actions {
+		fmt.Fprintf(&b, "%d. %q - %s\n", i+1, action.Name, action.Description)
+		fmt.Fprintf(&b, "   - Impact: %s\n", action.Impact)
+		fmt.Fprintf(&b, "   - Risk: %s\n", action.Risk)
+		fmt.Fprintf(&b, "   - Use when: %s\n", action.UseWhen)
+		checks := make([]string, len(action.Checks))
+		for j, check := range action.Checks {
+			checks[j] = fmt.Sprintf("%q", check.Name)
+		}
+		fmt.Fprintf(&b, "   - Verification checks: %s\n\n", strings.Join(checks, ", "))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// expandCommands copies commands with placeholders filled in
+func expandCommands(commands []models.Command) []models.Command {
+	expanded := make([]models.Command, len(commands))
+	for i, cmd := range commands {
+		cmd.Args = append([]string(nil), cmd.Args...)
+		for j, arg := range cmd.Args {
+			cmd.Args[j] = strings.ReplaceAll(arg, healthMonitorPlaceholder, healthMonitorURL())
+		}
+		expanded[i] = cmd
+	}
+	return expanded
+}
+
+// rollbackPlanFor is the action's rollback with its commands ready to run
+func rollbackPlanFor(action ActionSpec) models.RollbackPlan {
+	plan := action.Rollback
+	if plan.Mode == "" {
+		plan.Mode = RollbackNone
+	}
+	if plan.Notes == "" && plan.Mode == RollbackNone {
+		plan.Notes = "No rollback declared for this action"
+	}
+	plan.Snapshot = expandCommands(plan.Snapshot)
+	plan.Compensate = expandCommands(plan.Compensate)
+	return plan
+}
+
+// containerActionable reports whether a catalog action may start or restart this container
+func containerActionable(container string) bool {
+	if !IsSystemActionable(container) {
+		return false
+	}
+	for _, action := range ActionCatalog {
+		for _, cmd := range append(append([]models.Command{}, action.Commands...), action.Rollback.Compensate...) {
+			if cmd.Command == "docker" && len(cmd.Args) == 2 && cmd.Args[1] == container {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// ErrUnknownCommandType is returned for a command no executor is registered for. Such commands
+// fail closed: they are refused at preview and never reported as having run.
+var ErrUnknownCommandType = errors.New("unknown command type")
+
+const maxCommandOutput = 64 * 1024 // Bytes of output kept per command
+
+// CommandResult is the structured output of a single command
+type CommandResult struct {
+	Output string                 // Human-readable summary, shown in the execution log
+	Data   map[string]interface{} // Structured details (status code, exit code, rows, ...)
+}
+
+// Executor runs one type of command. Each executor parses models.Command.Args into its own typed
+// arguments, so a malformed command is rejected by Validate before anything runs.
+type Executor interface {
+	Validate(cmd models.Command) error
+	Describe(cmd models.Command) string // What Execute would do, for previews and dry runs
+	Execute(ctx context.Context, cmd models.Command) (CommandResult, error)
+	Timeout() time.Duration // Default deadline for one command
+}
+
+// executors maps models.Command.Command to the executor that runs it
+var executors = map[string]Executor{}
+
+// RegisterExecutor makes commands of the given type runnable
+func RegisterExecutor(commandType string, executor Executor) {
+	executors[commandType] = executor
+}
+
+// RegisterExecutors registers the built-in executors. It runs at startup, after .env is loaded,
+// since the Docker, SQL and script executors are configured from the environment.
+func RegisterExecutors() {
+	http := &httpExecutor{}
+	RegisterExecutor("http_get", http)
+	RegisterExecutor("http_post", http)
+	RegisterExecutor("docker", newDockerExecutorFromEnv())
+	RegisterExecutor("sql", newSQLExecutorFromEnv())
+	RegisterExecutor("script", newScriptExecutorFromEnv())
+}
+
+func executorFor(cmd models.Command) (Executor, error) {
+	executor, ok := executors[cmd.Command]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCommandType, cmd.Command)
+	}
+	return executor, nil
+}
+
+// validateCommand checks that a command has an executor and well-formed arguments
+func validateCommand(cmd models.Command) error {
+	executor, err := executorFor(cmd)
+	if err != nil {
+		return err
+	}
+	return executor.Validate(cmd)
+}
+
+// runCommand validates and runs a command under its executor's deadline, or the command's own
+// "timeout_seconds" metadata when set
+func runCommand(ctx context.Context, cmd models.Command) (CommandResult, error) {
+	executor, err := executorFor(cmd)
+	if err != nil {
+		return CommandResult{}, err
+	}
+	if err := executor.Validate(cmd); err != nil {
+		return CommandResult{}, fmt.Errorf("invalid %s command: %w", cmd.Command, err)
+	}
+
+	timeout := executor.Timeout()
+	if seconds, ok := cmd.Metadata["timeout_seconds"].(float64); ok && seconds > 0 {
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return executor.Execute(ctx, cmd)
+}
+
+// metadataInt reads a whole-number setting from the command's metadata, clamped to [min, max]
+func metadataInt(cmd models.Command, key string, def, min, max int) int {
+	value, ok := cmd.Metadata[key].(float64)
+	if !ok {
+		return def
+	}
+	switch {
+	case int(value) < min:
+		return min
+	case int(value) > max:
+		return max
+	}
+	return int(value)
+}
+
+// describeCommand reports what a command would do, without side effects
+func describeCommand(cmd models.Command) string {
+	executor, err := executorFor(cmd)
+	if err != nil {
+		return fmt.Sprintf("Cannot run %q: %v", cmd.Command, err)
+	}
+	if err := executor.Validate(cmd); err != nil {
+		return fmt.Sprintf("Cannot run %q: %v", cmd.Command, err)
+	}
+	return executor.Describe(cmd)
+}
+
+// readLimited reads at most maxCommandOutput bytes, noting when output was cut
+func readLimited(r io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(r, maxCommandOutput+1))
+	if len(data) > maxCommandOutput {
+		return string(data[:maxCommandOutput]) + "\n[output truncated]"
+	}
+	return string(data)
+}
+
+// limitedWriter keeps the first maxCommandOutput bytes written to it and discards the rest, so a
+// chatty process cannot grow its captured output without bound
+type limitedWriter struct {
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if room := maxCommandOutput - w.buf.Len(); len(p) > room {
+		w.buf.Write(p[:room])
+		w.truncated = true
+	} else {
+		w.buf.Write(p)
+	}
+	return len(p), nil // Report everything as written so the process is never stopped by a short write
+}
+
+// String returns the kept output, noting when output was cut
+func (w *limitedWriter) String() string {
+	if w.truncated {
+		return w.buf.String() + "\n[output truncated]"
+	}
+	return w.buf.String()
+}
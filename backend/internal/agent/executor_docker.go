@@ -0,0 +1,245 @@
+package agent
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// ErrDockerUnavailable is returned when the Docker Engine API socket cannot be reached. Container
+// commands fail rather than report a restart that never happened.
+var ErrDockerUnavailable = errors.New("docker engine API is not reachable")
+
+const (
+	dockerStopTimeoutSeconds    = 10               // Grace period the engine gives a container before killing it on restart
+	dockerMaxStopTimeoutSeconds = 30               // Longest grace period a command's "stop_timeout_seconds" may ask for
+	dockerHealthWait            = 30 * time.Second // How long to wait for a restarted container to report healthy
+	dockerMaxHealthWait         = 60 * time.Second // Longest wait a command's "health_wait_seconds" may ask for
+	dockerLogTail               = 50               // Lines of container logs recorded after the action
+)
+
+// dockerExecutor runs "docker" commands through the Docker Engine HTTP API on a Unix socket.
+// Args are the action ("restart" or "start") and the container, which must be an actionable
+// system that a catalog action starts or restarts.
+type dockerExecutor struct {
+	socket string
+	client *http.Client
+}
+
+type dockerArgs struct {
+	Action    string
+	Container string
+}
+
+func newDockerExecutor(socket string) *dockerExecutor {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &dockerExecutor{socket: socket, client: &http.Client{Transport: transport}}
+}
+
+// newDockerExecutorFromEnv uses DOCKER_SOCKET, or the engine's default socket path
+func newDockerExecutorFromEnv() *dockerExecutor {
+	socket := os.Getenv("DOCKER_SOCKET")
+	if socket == "" {
+		socket = "/var/run/docker.sock"
+	}
+	return newDockerExecutor(socket)
+}
+
+func parseDockerArgs(cmd models.Command) (dockerArgs, error) {
+	if len(cmd.Args) != 2 {
+		return dockerArgs{}, errors.New("expected an action and a container name")
+	}
+	args := dockerArgs{Action: cmd.Args[0], Container: cmd.Args[1]}
+	if args.Action != "restart" && args.Action != "start" {
+		return args, fmt.Errorf("unsupported container action %q", args.Action)
+	}
+	if !containerActionable(args.Container) {
+		return args, fmt.Errorf("container %q is not in the allowlist", args.Container)
+	}
+	return args, nil
+}
+
+func (e *dockerExecutor) Validate(cmd models.Command) error {
+	_, err := parseDockerArgs(cmd)
+	return err
+}
+
+func (e *dockerExecutor) Describe(cmd models.Command) string {
+	args, _ := parseDockerArgs(cmd)
+	return fmt.Sprintf("Would %s container %s via the Docker Engine API", args.Action, args.Container)
+}
+
+func (e *dockerExecutor) Timeout() time.Duration {
+	return dockerMaxStopTimeoutSeconds*time.Second + dockerMaxHealthWait + 20*time.Second
+}
+
+func (e *dockerExecutor) Execute(ctx context.Context, cmd models.Command) (CommandResult, error) {
+	args, err := parseDockerArgs(cmd)
+	if err != nil {
+		return CommandResult{}, err
+	}
+
+	path := fmt.Sprintf("/containers/%s/%s", url.PathEscape(args.Container), args.Action)
+	if args.Action == "restart" {
+		path += fmt.Sprintf("?t=%d", metadataInt(cmd, "stop_timeout_seconds", dockerStopTimeoutSeconds, 0, dockerMaxStopTimeoutSeconds))
+	}
+	status, body, err := e.do(ctx, http.MethodPost, path)
+	if err != nil {
+		return CommandResult{}, err
+	}
+	// 304 from start means the container was already running
+	if status != http.StatusNoContent && !(args.Action == "start" && status == http.StatusNotModified) {
+		return CommandResult{Output: engineError(body)}, fmt.Errorf("%s %s failed with status %d: %s", args.Action, args.Container, status, engineError(body))
+	}
+
+	wait := metadataInt(cmd, "health_wait_seconds", int(dockerHealthWait/time.Second), 1, int(dockerMaxHealthWait/time.Second))
+	state, healthErr := e.waitHealthy(ctx, args.Container, time.Duration(wait)*time.Second)
+	logs, logsErr := e.logs(ctx, args.Container)
+
+	data := map[string]interface{}{
+		"container": args.Container,
+		"action":    args.Action,
+		"state":     state.Status,
+		"health":    state.health(),
+	}
+	output := fmt.Sprintf("Container %s %s: state %s, health %s", args.Container, args.Action, state.Status, state.health())
+	if logsErr != nil {
+		data["logs_error"] = logsErr.Error()
+	} else {
+		data["logs"] = logs
+		output += fmt.Sprintf("\n--- last %d log lines ---\n%s", dockerLogTail, logs)
+	}
+
+	if healthErr != nil {
+		return CommandResult{Output: output, Data: data}, healthErr
+	}
+	return CommandResult{Output: output, Data: data}, nil
+}
+
+// dockerState is the part of GET /containers/{id}/json the executor uses
+type dockerState struct {
+	Status string `json:"Status"`
+	Health *struct {
+		Status string `json:"Status"`
+	} `json:"Health"`
+}
+
+func (s dockerState) health() string {
+	if s.Health == nil {
+		return "none"
+	}
+	return s.Health.Status
+}
+
+func (e *dockerExecutor) inspect(ctx context.Context, container string) (dockerState, error) {
+	status, body, err := e.do(ctx, http.MethodGet, fmt.Sprintf("/containers/%s/json", url.PathEscape(container)))
+	if err != nil {
+		return dockerState{}, err
+	}
+	if status != http.StatusOK {
+		return dockerState{}, fmt.Errorf("inspect %s failed with status %d: %s", container, status, engineError(body))
+	}
+	var payload struct {
+		State dockerState `json:"State"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return dockerState{}, fmt.Errorf("invalid inspect response for %s: %w", container, err)
+	}
+	return payload.State, nil
+}
+
+// waitHealthy polls the container until it is running and, if it has a healthcheck, healthy
+func (e *dockerExecutor) waitHealthy(ctx context.Context, container string, wait time.Duration) (dockerState, error) {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	for {
+		state, err := e.inspect(ctx, container)
+		if err != nil {
+			return state, err
+		}
+		switch {
+		case state.Status == "running" && (state.health() == "none" || state.health() == "healthy"):
+			return state, nil
+		case state.Status == "exited" || state.Status == "dead" || state.health() == "unhealthy":
+			return state, fmt.Errorf("container %s is %s (health %s) after the action", container, state.Status, state.health())
+		}
+
+		select {
+		case <-ctx.Done():
+			return state, fmt.Errorf("container %s did not become healthy (state %s, health %s): %w", container, state.Status, state.health(), ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// logs fetches the container's recent stdout and stderr
+func (e *dockerExecutor) logs(ctx context.Context, container string) (string, error) {
+	status, body, err := e.do(ctx, http.MethodGet, fmt.Sprintf("/containers/%s/logs?stdout=1&stderr=1&timestamps=1&tail=%d", url.PathEscape(container), dockerLogTail))
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("logs for %s failed with status %d: %s", container, status, engineError(body))
+	}
+	return demuxDockerLogs(body), nil
+}
+
+func (e *dockerExecutor) do(ctx context.Context, method, path string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, "http://docker"+path, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, nil, ctx.Err()
+		}
+		return 0, nil, fmt.Errorf("%w at %s: %v", ErrDockerUnavailable, e.socket, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxCommandOutput))
+	return resp.StatusCode, body, nil
+}
+
+// demuxDockerLogs strips the 8-byte stream headers the engine adds to logs of containers without a TTY
+func demuxDockerLogs(raw []byte) string {
+	var out strings.Builder
+	for len(raw) >= 8 && raw[0] <= 2 && raw[1] == 0 && raw[2] == 0 && raw[3] == 0 {
+		size := int(binary.BigEndian.Uint32(raw[4:8]))
+		raw = raw[8:]
+		if size > len(raw) {
+			size = len(raw)
+		}
+		out.Write(raw[:size])
+		raw = raw[size:]
+	}
+	out.Write(raw) // TTY containers send plain text
+	return out.String()
+}
+
+func engineError(body []byte) string {
+	var payload struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &payload) == nil && payload.Message != "" {
+		return payload.Message
+	}
+	return strings.TrimSpace(string(body))
+}
@@ -0,0 +1,263 @@
+package agent
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// fakeEngine serves the parts of the Docker Engine API the executor uses on a Unix socket
+type fakeEngine struct {
+	mu       sync.Mutex
+	requests []string                 // "METHOD path?query" of every call
+	states   map[string][]dockerState // Successive inspect results per container; the last one repeats
+	action   int                      // Status returned for restart and start
+	logs     []byte
+}
+
+func newFakeEngine(t *testing.T) (*fakeEngine, *dockerExecutor) {
+	t.Helper()
+	// Unix socket paths are limited to ~100 bytes, which t.TempDir can exceed
+	dir, err := os.MkdirTemp("", "docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "engine.sock")
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", socket, err)
+	}
+	engine := &fakeEngine{states: map[string][]dockerState{}, action: http.StatusNoContent}
+	server := &http.Server{Handler: engine}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	return engine, newDockerExecutor(socket)
+}
+
+func (f *fakeEngine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.RequestURI())
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/containers/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	container, op := parts[0], parts[1]
+	states, known := f.states[container]
+	if !known {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "No such container: " + container})
+		return
+	}
+
+	switch op {
+	case "restart", "start":
+		w.WriteHeader(f.action)
+	case "json":
+		state := states[0]
+		if len(states) > 1 {
+			f.states[container] = states[1:]
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"State": state})
+	case "logs":
+		w.Write(f.logs)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeEngine) calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.requests...)
+}
+
+func containerState(status, health string) dockerState {
+	state := dockerState{Status: status}
+	if health != "" {
+		state.Health = &struct {
+			Status string `json:"Status"`
+		}{Status: health}
+	}
+	return state
+}
+
+// logFrame is one multiplexed log record: stream 1 is stdout, 2 is stderr
+func logFrame(stream byte, text string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(text)))
+	return append(header, text...)
+}
+
+func TestDockerExecutorRestart(t *testing.T) {
+	engine, e := newFakeEngine(t)
+	engine.states["redis-test"] = []dockerState{containerState("running", "starting"), containerState("running", "healthy")}
+	engine.logs = append(logFrame(1, "Ready to accept connections\n"), logFrame(2, "WARNING overcommit_memory is 0\n")...)
+
+	cmd := models.Command{Command: "docker", Args: []string{"restart", "redis-test"},
+		Metadata: map[string]interface{}{"stop_timeout_seconds": float64(5), "health_wait_seconds": float64(5)}}
+	result, err := e.Execute(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	calls := engine.calls()
+	if len(calls) == 0 || calls[0] != "POST /containers/redis-test/restart?t=5" {
+		t.Errorf("first call = %v, want a restart with the requested stop timeout", calls)
+	}
+	if result.Data["state"] != "running" || result.Data["health"] != "healthy" {
+		t.Errorf("data = %v, want running and healthy", result.Data)
+	}
+	logs, _ := result.Data["logs"].(string)
+	if logs != "Ready to accept connections\nWARNING overcommit_memory is 0\n" {
+		t.Errorf("logs = %q, want stdout and stderr without stream headers", logs)
+	}
+}
+
+func TestDockerExecutorStart(t *testing.T) {
+	engine, e := newFakeEngine(t)
+	engine.states["postgres-test"] = []dockerState{containerState("running", "")}
+	engine.action = http.StatusNotModified // Already running
+
+	result, err := e.Execute(context.Background(), models.Command{Command: "docker", Args: []string{"start", "postgres-test"}})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if calls := engine.calls(); calls[0] != "POST /containers/postgres-test/start" {
+		t.Errorf("first call = %s, want a start without a stop timeout", calls[0])
+	}
+	if result.Data["health"] != "none" {
+		t.Errorf("health = %v, want none for a container without a healthcheck", result.Data["health"])
+	}
+}
+
+func TestDockerExecutorEngineError(t *testing.T) {
+	engine, e := newFakeEngine(t)
+	engine.states["redis-test"] = []dockerState{containerState("exited", "")}
+	engine.action = http.StatusInternalServerError
+
+	if _, err := e.Execute(context.Background(), models.Command{Command: "docker", Args: []string{"restart", "redis-test"}}); err == nil ||
+		!strings.Contains(err.Error(), "status 500") {
+		t.Errorf("Execute = %v, want the engine's failure reported", err)
+	}
+	if calls := engine.calls(); len(calls) != 1 {
+		t.Errorf("calls = %v, want no health wait after a failed restart", calls)
+	}
+}
+
+func TestDockerWaitHealthy(t *testing.T) {
+	tests := []struct {
+		name    string
+		states  []dockerState
+		wait    time.Duration
+		wantErr string
+	}{
+		{"healthy", []dockerState{containerState("restarting", ""), containerState("running", "healthy")}, 5 * time.Second, ""},
+		{"no healthcheck", []dockerState{containerState("running", "")}, time.Second, ""},
+		{"unhealthy", []dockerState{containerState("running", "unhealthy")}, 5 * time.Second, "health unhealthy"},
+		{"exited", []dockerState{containerState("exited", "")}, 5 * time.Second, "is exited"},
+		{"timeout", []dockerState{containerState("running", "starting")}, 200 * time.Millisecond, "did not become healthy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, e := newFakeEngine(t)
+			engine.states["redis-test"] = tt.states
+
+			state, err := e.waitHealthy(context.Background(), "redis-test", tt.wait)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("waitHealthy = %v, want healthy", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("waitHealthy = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if tt.name == "timeout" && (!errors.Is(err, context.DeadlineExceeded) || state.health() != "starting") {
+				t.Errorf("waitHealthy = %v with health %s, want a deadline while still starting", err, state.health())
+			}
+		})
+	}
+}
+
+// Containers are only reachable when they are actionable systems that a catalog action starts or restarts
+func TestDockerExecutorRejectsContainersOutsideTheAllowlist(t *testing.T) {
+	engine, e := newFakeEngine(t)
+	for _, name := range []string{"api-gateway", "health-monitor", "backend", "redis-test/../backend"} {
+		engine.states[name] = []dockerState{containerState("running", "")}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"not an actionable system", []string{"restart", "api-gateway"}},
+		{"actionable but no catalog action restarts it", []string{"restart", "health-monitor"}},
+		{"the backend itself", []string{"restart", "backend"}},
+		{"path in the name", []string{"restart", "redis-test/../backend"}},
+		{"unsupported action", []string{"kill", "redis-test"}},
+		{"missing container", []string{"restart"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := models.Command{Command: "docker", Args: tt.args}
+			if err := e.Validate(cmd); err == nil {
+				t.Error("Validate accepted the command")
+			}
+			if _, err := e.Execute(context.Background(), cmd); err == nil {
+				t.Error("Execute ran the command")
+			}
+		})
+	}
+	if calls := engine.calls(); len(calls) != 0 {
+		t.Errorf("engine was called for refused commands: %v", calls)
+	}
+	if !containerActionable("redis-test") || !containerActionable("postgres-test") {
+		t.Error("catalog containers are not actionable")
+	}
+}
+
+func TestDockerExecutorUnavailable(t *testing.T) {
+	e := newDockerExecutor(filepath.Join(t.TempDir(), "missing.sock"))
+	_, err := e.Execute(context.Background(), models.Command{Command: "docker", Args: []string{"restart", "redis-test"}})
+	if !errors.Is(err, ErrDockerUnavailable) {
+		t.Errorf("Execute = %v, want ErrDockerUnavailable", err)
+	}
+}
+
+func TestDemuxDockerLogs(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []byte
+		want string
+	}{
+		{"stdout and stderr", append(logFrame(1, "out\n"), logFrame(2, "err\n")...), "out\nerr\n"},
+		{"TTY container", []byte("plain text\n"), "plain text\n"},
+		{"truncated frame", logFrame(1, "cut off at the output limit")[:15], "cut off"},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := demuxDockerLogs(tt.raw); got != tt.want {
+				t.Errorf("demuxDockerLogs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
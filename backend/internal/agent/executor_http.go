@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// httpExecutor runs "http_get" and "http_post" commands: Args[0] is the URL, and an optional
+// Args[1] is a JSON request body for POST
+type httpExecutor struct{}
+
+type httpArgs struct {
+	Method string
+	URL    string
+	Body   string
+}
+
+func parseHTTPArgs(cmd models.Command) (httpArgs, error) {
+	args := httpArgs{Method: http.MethodGet}
+	switch cmd.Command {
+	case "http_get":
+		if len(cmd.Args) != 1 {
+			return args, errors.New("expected exactly one argument: the URL")
+		}
+	case "http_post":
+		args.Method = http.MethodPost
+		if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+			return args, errors.New("expected the URL and an optional JSON body")
+		}
+		if len(cmd.Args) == 2 {
+			if !json.Valid([]byte(cmd.Args[1])) {
+				return args, errors.New("request body is not valid JSON")
+			}
+			args.Body = cmd.Args[1]
+		}
+	default:
+		return args, fmt.Errorf("not an HTTP command: %q", cmd.Command)
+	}
+
+	u, err := url.Parse(cmd.Args[0])
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return args, fmt.Errorf("invalid URL %q", cmd.Args[0])
+	}
+	args.URL = u.String()
+	return args, nil
+}
+
+func (e *httpExecutor) Validate(cmd models.Command) error {
+	_, err := parseHTTPArgs(cmd)
+	return err
+}
+
+func (e *httpExecutor) Describe(cmd models.Command) string {
+	args, _ := parseHTTPArgs(cmd)
+	return fmt.Sprintf("Would send %s %s", args.Method, args.URL)
+}
+
+func (e *httpExecutor) Timeout() time.Duration {
+	return agentHTTPTimeout
+}
+
+func (e *httpExecutor) Execute(ctx context.Context, cmd models.Command) (CommandResult, error) {
+	args, err := parseHTTPArgs(cmd)
+	if err != nil {
+		return CommandResult{}, err
+	}
+
+	var resp *http.Response
+	if args.Method == http.MethodPost {
+		resp, err = httpPost(ctx, args.URL, strings.NewReader(args.Body))
+	} else {
+		resp, err = httpGet(ctx, args.URL)
+	}
+	if err != nil {
+		return CommandResult{}, fmt.Errorf("failed to call %s: %w", args.URL, err)
+	}
+	defer resp.Body.Close()
+
+	body := readLimited(resp.Body)
+	data := map[string]interface{}{"method": args.Method, "url": args.URL, "status_code": resp.StatusCode}
+	var parsed interface{}
+	if json.Unmarshal([]byte(body), &parsed) == nil {
+		data["body"] = parsed
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return CommandResult{Output: fmt.Sprintf("Failed with status %d: %s", resp.StatusCode, body), Data: data},
+			fmt.Errorf("%s %s returned status %d", args.Method, args.URL, resp.StatusCode)
+	}
+	return CommandResult{Output: body, Data: data}, nil
+}
@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+func TestHTTPExecutorGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/health" {
+			t.Errorf("got %s %s, want GET /health", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer server.Close()
+
+	result, err := (&httpExecutor{}).Execute(context.Background(),
+		models.Command{Command: "http_get", Args: []string{server.URL + "/health"}})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Data["status_code"] != http.StatusOK {
+		t.Errorf("status_code = %v, want 200", result.Data["status_code"])
+	}
+	body, ok := result.Data["body"].(map[string]interface{})
+	if !ok || body["status"] != "ok" {
+		t.Errorf("body = %v, want the parsed JSON response", result.Data["body"])
+	}
+}
+
+func TestHTTPExecutorPost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s with Content-Type %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		if string(body) != `{"mode":"drain"}` {
+			t.Errorf("body = %s, want the command's JSON body", body)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	result, err := (&httpExecutor{}).Execute(context.Background(),
+		models.Command{Command: "http_post", Args: []string{server.URL + "/drain", `{"mode":"drain"}`}})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Data["method"] != http.MethodPost || result.Data["status_code"] != http.StatusAccepted {
+		t.Errorf("data = %v, want a POST answered with 202", result.Data)
+	}
+}
+
+func TestHTTPExecutorFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		http.Error(w, "redis unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	t.Run("error status", func(t *testing.T) {
+		result, err := (&httpExecutor{}).Execute(context.Background(),
+			models.Command{Command: "http_post", Args: []string{server.URL + "/clear"}})
+		if err == nil || !strings.Contains(err.Error(), "status 503") {
+			t.Fatalf("Execute error = %v, want a 503 failure", err)
+		}
+		if result.Data["status_code"] != http.StatusServiceUnavailable || !strings.Contains(result.Output, "redis unavailable") {
+			t.Errorf("result = %+v, want the status and response body kept", result)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if _, err := (&httpExecutor{}).Execute(ctx, models.Command{Command: "http_get", Args: []string{server.URL + "/slow"}}); err == nil {
+			t.Fatal("Execute outlived its deadline")
+		}
+	})
+}
+
+func TestHTTPExecutorValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  models.Command
+		ok   bool
+	}{
+		{"get", models.Command{Command: "http_get", Args: []string{"http://redis-test:8080/health"}}, true},
+		{"post with body", models.Command{Command: "http_post", Args: []string{"https://example.com/x", `{"a":1}`}}, true},
+		{"post without body", models.Command{Command: "http_post", Args: []string{"https://example.com/x"}}, true},
+		{"no URL", models.Command{Command: "http_get"}, false},
+		{"get with a body", models.Command{Command: "http_get", Args: []string{"http://example.com", "{}"}}, false},
+		{"invalid JSON body", models.Command{Command: "http_post", Args: []string{"http://example.com", "{"}}, false},
+		{"file URL", models.Command{Command: "http_get", Args: []string{"file:///etc/passwd"}}, false},
+		{"no host", models.Command{Command: "http_get", Args: []string{"http:///health"}}, false},
+		{"other method", models.Command{Command: "http_delete", Args: []string{"http://example.com"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&httpExecutor{}).Validate(tt.cmd)
+			if (err == nil) != tt.ok {
+				t.Errorf("Validate = %v, want ok=%v", err, tt.ok)
+			}
+		})
+	}
+}
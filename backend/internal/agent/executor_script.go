@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// scriptExecutor runs "script" commands: Args[0] is the name of an executable in the allowlisted
+// directory (AGENT_SCRIPTS_DIR) and the rest are passed to it. Scripts run with a minimal
+// environment so they never see the backend's credentials.
+type scriptExecutor struct {
+	dir string
+}
+
+type scriptArgs struct {
+	Path string
+	Args []string
+}
+
+const scriptWaitDelay = 2 * time.Second // How long a stopped script's output may stay open before it is closed
+
+func newScriptExecutorFromEnv() *scriptExecutor {
+	return &scriptExecutor{dir: os.Getenv("AGENT_SCRIPTS_DIR")}
+}
+
+func (e *scriptExecutor) parseArgs(cmd models.Command) (scriptArgs, error) {
+	if e.dir == "" {
+		return scriptArgs{}, errors.New("no scripts directory is configured (AGENT_SCRIPTS_DIR)")
+	}
+	if len(cmd.Args) < 1 {
+		return scriptArgs{}, errors.New("expected a script name")
+	}
+	name := cmd.Args[0]
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return scriptArgs{}, fmt.Errorf("invalid script name %q", name)
+	}
+
+	dir, err := filepath.EvalSymlinks(e.dir)
+	if err != nil {
+		return scriptArgs{}, fmt.Errorf("scripts directory is not available: %w", err)
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(dir, name))
+	if err != nil {
+		return scriptArgs{}, fmt.Errorf("script %q not found", name)
+	}
+	if filepath.Dir(path) != dir {
+		return scriptArgs{}, fmt.Errorf("script %q resolves outside the scripts directory", name)
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+		return scriptArgs{}, fmt.Errorf("script %q is not an executable file", name)
+	}
+	return scriptArgs{Path: path, Args: cmd.Args[1:]}, nil
+}
+
+func (e *scriptExecutor) Validate(cmd models.Command) error {
+	_, err := e.parseArgs(cmd)
+	return err
+}
+
+func (e *scriptExecutor) Describe(cmd models.Command) string {
+	args, _ := e.parseArgs(cmd)
+	return fmt.Sprintf("Would run script %s %s", filepath.Base(args.Path), strings.Join(args.Args, " "))
+}
+
+func (e *scriptExecutor) Timeout() time.Duration {
+	return 60 * time.Second
+}
+
+func (e *scriptExecutor) Execute(ctx context.Context, cmd models.Command) (CommandResult, error) {
+	args, err := e.parseArgs(cmd)
+	if err != nil {
+		return CommandResult{}, err
+	}
+
+	var output limitedWriter // Stdout and Stderr share it, so exec writes to it from one goroutine
+	process := exec.CommandContext(ctx, args.Path, args.Args...)
+	process.Dir = filepath.Dir(args.Path)
+	process.Env = []string{"PATH=/usr/local/bin:/usr/bin:/bin"}
+	process.Stdout = &output
+	process.Stderr = &output
+	process.WaitDelay = scriptWaitDelay // Children of the script may hold its output open after it is killed
+
+	runErr := process.Run()
+	exitCode := -1
+	if process.ProcessState != nil {
+		exitCode = process.ProcessState.ExitCode()
+	}
+	result := CommandResult{
+		Output: output.String(),
+		Data:   map[string]interface{}{"script": filepath.Base(args.Path), "exit_code": exitCode},
+	}
+	if runErr != nil {
+		if ctx.Err() != nil {
+			return result, fmt.Errorf("script %s stopped: %w", filepath.Base(args.Path), ctx.Err())
+		}
+		return result, fmt.Errorf("script %s failed: %w", filepath.Base(args.Path), runErr)
+	}
+	return result, nil
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// scriptFixture lays out an allowlisted scripts directory next to a directory it must not reach
+func scriptFixture(t *testing.T) *scriptExecutor {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, "scripts")
+	outside := filepath.Join(root, "outside")
+	for _, d := range []string{dir, outside, filepath.Join(dir, "nested")} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write := func(path, body string, mode os.FileMode) {
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(dir, "env.sh"), `env; echo "args: $*"`, 0755)
+	write(filepath.Join(dir, "fail.sh"), `echo "disk full" >&2; exit 3`, 0755)
+	write(filepath.Join(dir, "sleep.sh"), `sleep 5`, 0755)
+	write(filepath.Join(dir, "chatty.sh"), `head -c 1000000 /dev/zero | tr '\0' x`, 0755)
+	write(filepath.Join(dir, "notes.sh"), `echo not executable`, 0644)
+	write(filepath.Join(dir, ".hidden.sh"), `echo hidden`, 0755)
+	write(filepath.Join(dir, "nested", "deep.sh"), `echo nested`, 0755)
+	write(filepath.Join(outside, "secret.sh"), `echo escaped`, 0755)
+
+	symlink := func(target, name string) {
+		if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	symlink(filepath.Join(outside, "secret.sh"), "escape.sh")
+	symlink("../outside/secret.sh", "relative-escape.sh")
+	symlink("env.sh", "alias.sh")
+
+	return &scriptExecutor{dir: dir}
+}
+
+func TestScriptExecutorAllowlist(t *testing.T) {
+	e := scriptFixture(t)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"script in the directory", []string{"env.sh", "--check"}, ""},
+		{"symlink within the directory", []string{"alias.sh"}, ""},
+		{"no script", nil, "expected a script name"},
+		{"path traversal", []string{"../outside/secret.sh"}, "invalid script name"},
+		{"absolute path", []string{"/bin/sh"}, "invalid script name"},
+		{"subdirectory", []string{"nested/deep.sh"}, "invalid script name"},
+		{"hidden file", []string{".hidden.sh"}, "invalid script name"},
+		{"symlink escape", []string{"escape.sh"}, "resolves outside the scripts directory"},
+		{"relative symlink escape", []string{"relative-escape.sh"}, "resolves outside the scripts directory"},
+		{"missing script", []string{"missing.sh"}, "not found"},
+		{"not executable", []string{"notes.sh"}, "not an executable file"},
+		{"directory", []string{"nested"}, "not an executable file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.Validate(models.Command{Command: "script", Args: tt.args})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate = %v, want the script allowed", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if result, err := e.Execute(context.Background(), models.Command{Command: "script", Args: tt.args}); err == nil || result.Output != "" {
+				t.Errorf("Execute ran a refused script: %+v, %v", result, err)
+			}
+		})
+	}
+}
+
+func TestScriptExecutorWithoutDirectory(t *testing.T) {
+	t.Setenv("AGENT_SCRIPTS_DIR", "")
+	err := newScriptExecutorFromEnv().Validate(models.Command{Command: "script", Args: []string{"env.sh"}})
+	if err == nil || !strings.Contains(err.Error(), "AGENT_SCRIPTS_DIR") {
+		t.Errorf("Validate = %v, want scripts refused when no directory is configured", err)
+	}
+}
+
+func TestScriptExecutorMinimalEnvironment(t *testing.T) {
+	e := scriptFixture(t)
+	t.Setenv("DB_PASSWORD", "hunter2")
+	t.Setenv("GROQ_API_KEY", "gsk_secret")
+
+	result, err := e.Execute(context.Background(), models.Command{Command: "script", Args: []string{"env.sh", "--dry", "redis"}})
+	if err != nil {
+		t.Fatalf("Execute: %v (%s)", err, result.Output)
+	}
+	for _, secret := range []string{"DB_PASSWORD", "hunter2", "GROQ_API_KEY", "gsk_secret"} {
+		if strings.Contains(result.Output, secret) {
+			t.Errorf("script saw %s in its environment:\n%s", secret, result.Output)
+		}
+	}
+	if !strings.Contains(result.Output, "PATH=/usr/local/bin:/usr/bin:/bin") {
+		t.Errorf("script did not get the minimal PATH:\n%s", result.Output)
+	}
+	if !strings.Contains(result.Output, "args: --dry redis") {
+		t.Errorf("script did not get its arguments:\n%s", result.Output)
+	}
+	if result.Data["exit_code"] != 0 || result.Data["script"] != "env.sh" {
+		t.Errorf("data = %v, want env.sh exiting 0", result.Data)
+	}
+}
+
+func TestScriptExecutorFailures(t *testing.T) {
+	e := scriptFixture(t)
+
+	result, err := e.Execute(context.Background(), models.Command{Command: "script", Args: []string{"fail.sh"}})
+	if err == nil {
+		t.Fatal("Execute succeeded for a script that exited 3")
+	}
+	if result.Data["exit_code"] != 3 || !strings.Contains(result.Output, "disk full") {
+		t.Errorf("result = %+v, want exit code 3 and the script's stderr", result)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := e.Execute(ctx, models.Command{Command: "script", Args: []string{"sleep.sh"}}); err == nil || !strings.Contains(err.Error(), "stopped") {
+		t.Errorf("Execute = %v, want the script stopped at its deadline", err)
+	}
+	// sleep outlives the killed shell and holds its output open; Execute must not wait for it
+	if elapsed := time.Since(start); elapsed > scriptWaitDelay+time.Second {
+		t.Errorf("Execute returned after %s, want it back within the wait delay", elapsed)
+	}
+}
+
+func TestScriptExecutorCapsOutput(t *testing.T) {
+	e := scriptFixture(t)
+
+	result, err := e.Execute(context.Background(), models.Command{Command: "script", Args: []string{"chatty.sh"}})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.HasSuffix(result.Output, "[output truncated]") {
+		t.Errorf("output of %d bytes does not note that it was truncated", len(result.Output))
+	}
+	if kept := strings.TrimSuffix(result.Output, "\n[output truncated]"); len(kept) != maxCommandOutput || strings.Trim(kept, "x") != "" {
+		t.Errorf("kept %d bytes, want the first %d bytes of output", len(kept), maxCommandOutput)
+	}
+	if result.Data["exit_code"] != 0 {
+		t.Errorf("exit code = %v, want the script to run to completion", result.Data["exit_code"])
+	}
+}
@@ -0,0 +1,157 @@
+package agent
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+const maxSQLRows = 100 // Rows kept from a query's result
+
+// sqlExecutor runs "sql" commands against an allowlisted database: Args are the database name and
+// a single statement. Only databases listed in AGENT_SQL_DSNS can be reached; the agent's own
+// database is never implicitly available.
+type sqlExecutor struct {
+	dsns   map[string]string // Allowlisted database name -> DSN
+	driver string            // database/sql driver; Postgres when empty
+}
+
+type sqlArgs struct {
+	Database  string
+	Statement string
+}
+
+// newSQLExecutorFromEnv reads AGENT_SQL_DSNS, formatted as "name=dsn;name=dsn"
+func newSQLExecutorFromEnv() *sqlExecutor {
+	e := &sqlExecutor{dsns: map[string]string{}}
+	for _, entry := range strings.Split(os.Getenv("AGENT_SQL_DSNS"), ";") {
+		name, dsn, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if ok && name != "" && dsn != "" {
+			e.dsns[strings.TrimSpace(name)] = strings.TrimSpace(dsn)
+		}
+	}
+	return e
+}
+
+func (e *sqlExecutor) parseArgs(cmd models.Command) (sqlArgs, error) {
+	if len(cmd.Args) != 2 {
+		return sqlArgs{}, errors.New("expected a database name and a statement")
+	}
+	args := sqlArgs{Database: cmd.Args[0], Statement: strings.TrimSpace(cmd.Args[1])}
+	if _, ok := e.dsns[args.Database]; !ok {
+		return args, fmt.Errorf("database %q is not in the allowlist", args.Database)
+	}
+	if args.Statement == "" {
+		return args, errors.New("statement is required")
+	}
+	if strings.Contains(strings.TrimSuffix(args.Statement, ";"), ";") {
+		return args, errors.New("only a single statement is allowed")
+	}
+	return args, nil
+}
+
+func (e *sqlExecutor) Validate(cmd models.Command) error {
+	_, err := e.parseArgs(cmd)
+	return err
+}
+
+func (e *sqlExecutor) Describe(cmd models.Command) string {
+	args, _ := e.parseArgs(cmd)
+	return fmt.Sprintf("Would run on %s: %s", args.Database, args.Statement)
+}
+
+func (e *sqlExecutor) Timeout() time.Duration {
+	return 30 * time.Second
+}
+
+func (e *sqlExecutor) Execute(ctx context.Context, cmd models.Command) (CommandResult, error) {
+	args, err := e.parseArgs(cmd)
+	if err != nil {
+		return CommandResult{}, err
+	}
+
+	driver := e.driver
+	if driver == "" {
+		driver = "postgres"
+	}
+	conn, err := sql.Open(driver, e.dsns[args.Database])
+	if err != nil {
+		return CommandResult{}, fmt.Errorf("failed to open %s: %w", args.Database, err)
+	}
+	defer conn.Close()
+
+	if returnsRows(args.Statement) {
+		return e.query(ctx, conn, args)
+	}
+
+	result, err := conn.ExecContext(ctx, args.Statement)
+	if err != nil {
+		return CommandResult{}, fmt.Errorf("statement failed on %s: %w", args.Database, err)
+	}
+	affected, _ := result.RowsAffected()
+	return CommandResult{
+		Output: fmt.Sprintf("%d row(s) affected", affected),
+		Data:   map[string]interface{}{"database": args.Database, "rows_affected": affected},
+	}, nil
+}
+
+func (e *sqlExecutor) query(ctx context.Context, conn *sql.DB, args sqlArgs) (CommandResult, error) {
+	rows, err := conn.QueryContext(ctx, args.Statement)
+	if err != nil {
+		return CommandResult{}, fmt.Errorf("query failed on %s: %w", args.Database, err)
+	}
+	defer rows.Close()
+
+	columns, _ := rows.Columns()
+	var results []map[string]interface{}
+	total := 0
+	for rows.Next() {
+		total++
+		if len(results) >= maxSQLRows {
+			continue
+		}
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return CommandResult{}, fmt.Errorf("failed to read row: %w", err)
+		}
+		row := map[string]interface{}{}
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[column] = values[i]
+		}
+		results = append(results, row)
+	}
+	if err := rows.Err(); err != nil {
+		return CommandResult{}, fmt.Errorf("query failed on %s: %w", args.Database, err)
+	}
+
+	return CommandResult{
+		Output: fmt.Sprintf("%d row(s) returned", total),
+		Data:   map[string]interface{}{"database": args.Database, "columns": columns, "rows": results, "row_count": total},
+	}, nil
+}
+
+func returnsRows(statement string) bool {
+	fields := strings.Fields(strings.ToLower(statement))
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "select", "with", "show", "explain", "values":
+		return true
+	}
+	return strings.Contains(strings.ToLower(statement), " returning ")
+}
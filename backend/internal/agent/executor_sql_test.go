@@ -0,0 +1,252 @@
+package agent
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+func TestNewSQLExecutorFromEnv(t *testing.T) {
+	t.Setenv("AGENT_SQL_DSNS", " app = postgres://app@db/app ;broken; =postgres://x;reports=postgres://r@db/reports?sslmode=disable")
+
+	e := newSQLExecutorFromEnv()
+	want := map[string]string{
+		"app":     "postgres://app@db/app",
+		"reports": "postgres://r@db/reports?sslmode=disable",
+	}
+	if len(e.dsns) != len(want) {
+		t.Fatalf("dsns = %v, want %v", e.dsns, want)
+	}
+	for name, dsn := range want {
+		if e.dsns[name] != dsn {
+			t.Errorf("dsns[%q] = %q, want %q", name, e.dsns[name], dsn)
+		}
+	}
+}
+
+func TestSQLExecutorAllowlist(t *testing.T) {
+	// The DSN is never dialled: every case here must be refused before a connection is opened
+	e := &sqlExecutor{dsns: map[string]string{"app": "postgres://app@127.0.0.1:1/app?sslmode=disable&connect_timeout=1"}}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"database not allowlisted", []string{"incident_db", "SELECT 1"}, "not in the allowlist"},
+		{"DSN instead of a name", []string{"postgres://app@127.0.0.1:1/app", "SELECT 1"}, "not in the allowlist"},
+		{"several statements", []string{"app", "SELECT 1; DROP TABLE users"}, "single statement"},
+		{"empty statement", []string{"app", "  "}, "statement is required"},
+		{"missing statement", []string{"app"}, "database name and a statement"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := models.Command{Command: "sql", Args: tt.args}
+			if err := e.Validate(cmd); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if _, err := e.Execute(context.Background(), cmd); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Execute = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := e.Validate(models.Command{Command: "sql", Args: []string{"app", "SELECT pg_terminate_backend(pid) FROM pg_stat_activity;"}}); err != nil {
+		t.Errorf("Validate rejected an allowlisted single statement: %v", err)
+	}
+}
+
+func TestSQLExecutorWithoutConfiguration(t *testing.T) {
+	t.Setenv("AGENT_SQL_DSNS", "")
+	if err := newSQLExecutorFromEnv().Validate(models.Command{Command: "sql", Args: []string{"app", "SELECT 1"}}); err == nil {
+		t.Error("an unconfigured SQL executor accepted a command")
+	}
+}
+
+func TestReturnsRows(t *testing.T) {
+	tests := map[string]bool{
+		"SELECT 1":                                       true,
+		"  with x as (select 1) select * from x":         true,
+		"EXPLAIN ANALYZE SELECT 1":                       true,
+		"UPDATE jobs SET status = 'failed' RETURNING id": true,
+		"VACUUM ANALYZE":                                 false,
+		"DELETE FROM sessions WHERE expired":             false,
+		"":                                               false,
+	}
+	for statement, want := range tests {
+		if got := returnsRows(statement); got != want {
+			t.Errorf("returnsRows(%q) = %v, want %v", statement, got, want)
+		}
+	}
+}
+
+// fakeSQLDriver stands in for Postgres. The DSN sets up the database: "rows=N" answers every query
+// with N rows of (id, name), "affected=N" reports N rows changed by every other statement, and
+// "fail" makes every statement fail.
+type fakeSQLDriver struct{}
+
+type fakeSQLConn struct {
+	rows, affected int
+	fail           bool
+	statements     *[]string
+}
+
+// fakeSQLStatements records what reached the fake database, per DSN
+var fakeSQLStatements = map[string]*[]string{}
+
+func init() {
+	sql.Register("agent-fake-sql", fakeSQLDriver{})
+}
+
+func (fakeSQLDriver) Open(dsn string) (driver.Conn, error) {
+	conn := &fakeSQLConn{statements: fakeSQLStatements[dsn]}
+	for _, option := range strings.Fields(dsn) {
+		key, value, _ := strings.Cut(option, "=")
+		n, _ := strconv.Atoi(value)
+		switch key {
+		case "rows":
+			conn.rows = n
+		case "affected":
+			conn.affected = n
+		case "fail":
+			conn.fail = true
+		}
+	}
+	return conn, nil
+}
+
+func (c *fakeSQLConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fake driver: prepared statements are not supported")
+}
+func (c *fakeSQLConn) Close() error { return nil }
+func (c *fakeSQLConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("fake driver: no transactions")
+}
+
+func (c *fakeSQLConn) record(query string) error {
+	if c.statements != nil {
+		*c.statements = append(*c.statements, query)
+	}
+	if c.fail {
+		return errors.New("relation does not exist")
+	}
+	return nil
+}
+
+func (c *fakeSQLConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if err := c.record(query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(c.affected), nil
+}
+
+func (c *fakeSQLConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if err := c.record(query); err != nil {
+		return nil, err
+	}
+	return &fakeSQLRows{total: c.rows}, nil
+}
+
+type fakeSQLRows struct {
+	next, total int
+}
+
+func (r *fakeSQLRows) Columns() []string { return []string{"id", "name"} }
+func (r *fakeSQLRows) Close() error      { return nil }
+
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if r.next >= r.total {
+		return io.EOF
+	}
+	r.next++
+	dest[0] = int64(r.next)
+	dest[1] = []byte(fmt.Sprintf("row-%d", r.next))
+	return nil
+}
+
+// fakeSQLExecutor allowlists one fake database under the name "app"
+func fakeSQLExecutor(t *testing.T, dsn string) (*sqlExecutor, *[]string) {
+	t.Helper()
+	statements := &[]string{}
+	fakeSQLStatements[dsn] = statements
+	t.Cleanup(func() { delete(fakeSQLStatements, dsn) })
+	return &sqlExecutor{dsns: map[string]string{"app": dsn}, driver: "agent-fake-sql"}, statements
+}
+
+func TestSQLExecutorExec(t *testing.T) {
+	e, statements := fakeSQLExecutor(t, "affected=7")
+
+	result, err := e.Execute(context.Background(), models.Command{Command: "sql", Args: []string{"app", "DELETE FROM sessions WHERE expired"}})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Output != "7 row(s) affected" {
+		t.Errorf("output = %q, want 7 rows affected", result.Output)
+	}
+	if result.Data["rows_affected"] != int64(7) || result.Data["database"] != "app" {
+		t.Errorf("data = %v, want 7 rows affected on app", result.Data)
+	}
+	if len(*statements) != 1 || (*statements)[0] != "DELETE FROM sessions WHERE expired" {
+		t.Errorf("database received %q, want the statement once", *statements)
+	}
+}
+
+func TestSQLExecutorQuery(t *testing.T) {
+	e, _ := fakeSQLExecutor(t, "rows=3")
+
+	result, err := e.Execute(context.Background(), models.Command{Command: "sql", Args: []string{"app", "SELECT id, name FROM users"}})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Output != "3 row(s) returned" || result.Data["row_count"] != 3 {
+		t.Errorf("result = %q %v, want 3 rows returned", result.Output, result.Data["row_count"])
+	}
+	rows, _ := result.Data["rows"].([]map[string]interface{})
+	if len(rows) != 3 {
+		t.Fatalf("kept %d rows, want 3", len(rows))
+	}
+	if rows[0]["id"] != int64(1) || rows[0]["name"] != "row-1" {
+		t.Errorf("first row = %v, want id 1 and the name as text", rows[0])
+	}
+	if columns, _ := result.Data["columns"].([]string); strings.Join(columns, ",") != "id,name" {
+		t.Errorf("columns = %v, want id,name", result.Data["columns"])
+	}
+}
+
+func TestSQLExecutorQueryKeepsAtMostMaxRows(t *testing.T) {
+	e, _ := fakeSQLExecutor(t, fmt.Sprintf("rows=%d", maxSQLRows*2+5))
+
+	result, err := e.Execute(context.Background(), models.Command{Command: "sql", Args: []string{"app", "SELECT id, name FROM events"}})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	rows, _ := result.Data["rows"].([]map[string]interface{})
+	if len(rows) != maxSQLRows {
+		t.Errorf("kept %d rows, want %d", len(rows), maxSQLRows)
+	}
+	if result.Data["row_count"] != maxSQLRows*2+5 {
+		t.Errorf("row_count = %v, want every row counted (%d)", result.Data["row_count"], maxSQLRows*2+5)
+	}
+	if last := rows[len(rows)-1]; last["id"] != int64(maxSQLRows) {
+		t.Errorf("last kept row = %v, want row %d", last, maxSQLRows)
+	}
+}
+
+func TestSQLExecutorStatementFails(t *testing.T) {
+	e, _ := fakeSQLExecutor(t, "fail")
+
+	if _, err := e.Execute(context.Background(), models.Command{Command: "sql", Args: []string{"app", "UPDATE jobs SET status = 'failed'"}}); err == nil || !strings.Contains(err.Error(), "statement failed on app") {
+		t.Errorf("Execute = %v, want the statement's failure", err)
+	}
+	if _, err := e.Execute(context.Background(), models.Command{Command: "sql", Args: []string{"app", "SELECT 1"}}); err == nil || !strings.Contains(err.Error(), "query failed on app") {
+		t.Errorf("Execute = %v, want the query's failure", err)
+	}
+}
@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// Commands without a registered executor are refused everywhere: validation, previews and runs
+func TestUnknownCommandTypeFailsClosed(t *testing.T) {
+	cmd := models.Command{Name: "Delete pods", Command: "kubectl", Args: []string{"delete", "pods", "--all"}}
+
+	if err := validateCommand(cmd); !errors.Is(err, ErrUnknownCommandType) {
+		t.Errorf("validateCommand = %v, want ErrUnknownCommandType", err)
+	}
+	if result, err := runCommand(context.Background(), cmd); !errors.Is(err, ErrUnknownCommandType) {
+		t.Errorf("runCommand = %v, want ErrUnknownCommandType", err)
+	} else if result.Output != "" || result.Data != nil {
+		t.Errorf("runCommand reported a result for a command that cannot run: %+v", result)
+	}
+	if described := describeCommand(cmd); !strings.HasPrefix(described, "Cannot run") {
+		t.Errorf("describeCommand = %q, want it to refuse the command", described)
+	}
+}
+
+// Each executor refuses command types other than its own, even when called directly
+func TestExecutorsRejectOtherCommandTypes(t *testing.T) {
+	dir := t.TempDir()
+	executors := map[string]Executor{
+		"http":   &httpExecutor{},
+		"sql":    &sqlExecutor{dsns: map[string]string{"app": "postgres://unused"}},
+		"script": &scriptExecutor{dir: dir},
+		"docker": newDockerExecutor(filepath.Join(dir, "docker.sock")),
+	}
+	cmd := models.Command{Command: "kubectl", Args: []string{"delete"}}
+
+	for name, executor := range executors {
+		t.Run(name, func(t *testing.T) {
+			if err := executor.Validate(cmd); err == nil {
+				t.Error("Validate accepted an unknown command type")
+			}
+			if _, err := executor.Execute(context.Background(), cmd); err == nil {
+				t.Error("Execute ran an unknown command type")
+			}
+		})
+	}
+}
+
+func TestMetadataInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		want     int
+	}{
+		{"missing", nil, 10},
+		{"within range", map[string]interface{}{"n": float64(20)}, 20},
+		{"below minimum", map[string]interface{}{"n": float64(1)}, 5},
+		{"above maximum", map[string]interface{}{"n": float64(100)}, 30},
+		{"wrong type", map[string]interface{}{"n": "20"}, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := models.Command{Metadata: tt.metadata}
+			if got := metadataInt(cmd, "n", 10, 5, 30); got != tt.want {
+				t.Errorf("metadataInt = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitedWriter(t *testing.T) {
+	var w limitedWriter
+	chunk := strings.Repeat("a", maxCommandOutput/2+1)
+	for i := 0; i < 3; i++ {
+		if n, err := w.Write([]byte(chunk)); n != len(chunk) || err != nil {
+			t.Fatalf("Write = %d, %v, want every byte accepted", n, err)
+		}
+	}
+	if got := w.String(); got != strings.Repeat("a", maxCommandOutput)+"\n[output truncated]" {
+		t.Errorf("kept %d bytes, want %d and a truncation note", len(got), maxCommandOutput)
+	}
+
+	var short limitedWriter
+	short.Write([]byte("ok\n"))
+	if got := short.String(); got != "ok\n" {
+		t.Errorf("String = %q, want the output unchanged", got)
+	}
+}
@@ -0,0 +1,438 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+	wshub "github.com/tri27pham/incident-management-simulator/backend/internal/websocket"
+	"gorm.io/gorm"
+)
+
+const (
+	// Leases are renewed while held, so they only expire when the worker holding them died. The TTL is
+	// how long a crashed worker's targets stay locked if startup recovery does not release them first.
+	targetLeaseTTL           = 5 * time.Minute
+	targetLeaseRenewInterval = time.Minute
+
+	breakerStateID = 1
+
+	defaultActionRateLimit = 3
+	defaultSystemRateLimit = 6
+)
+
+// ErrTargetLocked is returned when another execution holds a lease on one of the plan's targets
+var ErrTargetLocked = errors.New("target is in use by another agent execution")
+
+// ErrRateLimited is returned when an action or target system has run too often recently
+var ErrRateLimited = errors.New("agent rate limit reached")
+
+// ErrCircuitOpen is returned while too many recent executions failed for the agent to act
+var ErrCircuitOpen = errors.New("agent circuit breaker is open")
+
+// rateLimitWindow is the sliding window the rate limits count over (AGENT_RATE_LIMIT_WINDOW_MINUTES, default 60)
+func rateLimitWindow() time.Duration {
+	return envMinutes("AGENT_RATE_LIMIT_WINDOW_MINUTES", 60)
+}
+
+// actionRateLimit is how many times an action may run per window. AGENT_ACTION_RATE_LIMITS overrides
+// it per action, e.g. "restart_redis=1,clear_redis_cache=5".
+func actionRateLimit(action string) int {
+	if n := parseNameCounts(os.Getenv("AGENT_ACTION_RATE_LIMITS"))[action]; n > 0 {
+		return n
+	}
+	return defaultActionRateLimit
+}
+
+// systemRateLimit is how many actions may run against one system per window, whatever the action.
+// AGENT_SYSTEM_RATE_LIMITS overrides it per system, e.g. "postgres-test=2".
+func systemRateLimit(system string) int {
+	if n := parseNameCounts(os.Getenv("AGENT_SYSTEM_RATE_LIMITS"))[system]; n > 0 {
+		return n
+	}
+	return defaultSystemRateLimit
+}
+
+// breakerThreshold is how many failed executions inside breakerWindow open the breaker (AGENT_BREAKER_FAILURES, default 3)
+func breakerThreshold() int {
+	if n, err := strconv.Atoi(os.Getenv("AGENT_BREAKER_FAILURES")); err == nil && n > 0 {
+		return n
+	}
+	return 3
+}
+
+// breakerWindow is how far back failures count toward the breaker (AGENT_BREAKER_WINDOW_MINUTES, default 30)
+func breakerWindow() time.Duration {
+	return envMinutes("AGENT_BREAKER_WINDOW_MINUTES", 30)
+}
+
+func envMinutes(key string, def int) time.Duration {
+	if minutes, err := strconv.Atoi(os.Getenv(key)); err == nil && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return time.Duration(def) * time.Minute
+}
+
+// parseNameCounts reads a "name=n,name=n" list; entries that are malformed or not positive are ignored
+func parseNameCounts(value string) map[string]int {
+	counts := map[string]int{}
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(parts[1])); err == nil && n > 0 {
+			counts[strings.TrimSpace(parts[0])] = n
+		}
+	}
+	return counts
+}
+
+// stepTarget is the system a plan step acts on, or "" for an action no longer in the catalog
+func stepTarget(actionName string, incident *models.Incident) string {
+	action, ok := GetAction(actionName)
+	if !ok {
+		return ""
+	}
+	return actionTarget(action, incident)
+}
+
+// planTargets lists the systems the plan's steps act on, sorted so leases are always taken in the same order
+func planTargets(steps []models.PlanStep, incident *models.Incident) []string {
+	var targets []string
+	for _, step := range steps {
+		if target := stepTarget(step.Action, incident); target != "" && !containsString(targets, target) {
+			targets = append(targets, target)
+		}
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// guardKind names which guard refused an attempt, for the history entry
+func guardKind(err error) string {
+	switch {
+	case errors.Is(err, ErrTargetLocked):
+		return "lock"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limit"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_breaker"
+	default:
+		return "other"
+	}
+}
+
+// isGuardError reports whether err is a refusal by one of the guards rather than a failure
+func isGuardError(err error) bool {
+	return guardKind(err) != "other"
+}
+
+// recordBlocked writes the incident history entry for an attempt a guard refused. executionID is
+// uuid.Nil when the attempt was refused before an execution existed.
+func recordBlocked(incidentID, executionID uuid.UUID, actor, attempt string, err error) {
+	log.Printf("🚧 [Agent] %s blocked: %v", attempt, err)
+	metadata := map[string]interface{}{"kind": guardKind(err)}
+	if executionID != uuid.Nil {
+		metadata["execution_id"] = executionID
+	}
+	services.RecordTimelineEvent(incidentID, "agent_blocked", actor, fmt.Sprintf("%s blocked: %v", attempt, err), metadata)
+}
+
+// checkGuards is the check made before an execution is queued: the breaker must be closed, no other
+// execution may hold one of the plan's targets, and no step may already be over its rate limits.
+// Each step is checked on its own; the limits are checked again as each step starts.
+func checkGuards(execution *models.AgentExecution, incident *models.Incident, steps []models.PlanStep) error {
+	now := time.Now()
+	if err := checkCircuitBreaker(now); err != nil {
+		return err
+	}
+	for _, target := range planTargets(steps, incident) {
+		if lease := foreignLease(target, execution.ID, now); lease != nil {
+			return targetLockedError(lease)
+		}
+	}
+	for _, step := range steps {
+		if err := checkRateLimits(step.Action, stepTarget(step.Action, incident), now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStepGuards is checked before each step of a real execution runs its commands
+func checkStepGuards(action, target string) error {
+	now := time.Now()
+	if err := checkCircuitBreaker(now); err != nil {
+		return err
+	}
+	return checkRateLimits(action, target, now)
+}
+
+// checkRateLimits counts the runs of the action, and of anything against the target, in the window
+func checkRateLimits(action, target string, now time.Time) error {
+	window := rateLimitWindow()
+	since := now.Add(-window)
+
+	var count int64
+	if err := db.DB.Model(&models.AgentActionRun{}).Where("action = ? AND started_at >= ?", action, since).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check rate limit for %s: %w", action, err)
+	}
+	if limit := actionRateLimit(action); count >= int64(limit) {
+		return fmt.Errorf("%w: %s already ran %d time(s) in the last %s (limit %d)", ErrRateLimited, action, count, window, limit)
+	}
+
+	if target == "" {
+		return nil
+	}
+	if err := db.DB.Model(&models.AgentActionRun{}).Where("target = ? AND started_at >= ?", target, since).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check rate limit for %s: %w", target, err)
+	}
+	if limit := systemRateLimit(target); count >= int64(limit) {
+		return fmt.Errorf("%w: %d action(s) already ran against %s in the last %s (limit %d)", ErrRateLimited, count, target, window, limit)
+	}
+	return nil
+}
+
+// recordActionRun counts a step toward the rate limits as it starts running its commands
+func recordActionRun(execution *models.AgentExecution, action, target string) {
+	run := models.AgentActionRun{
+		ExecutionID: execution.ID,
+		IncidentID:  execution.IncidentID,
+		Action:      action,
+		Target:      target,
+		StartedAt:   time.Now(),
+	}
+	if err := db.DB.Create(&run).Error; err != nil {
+		log.Printf("⚠️  [Agent] Failed to record run of %s for rate limits: %v", action, err)
+	}
+}
+
+// --- Target leases ---
+
+// holdTargets leases every target of the execution's plan to it, all or none, for as long as it runs and
+// verifies its commands, however long a plan and its rollback take: the leases are renewed until the
+// returned release is called, which must happen once it is done with them.
+func (s *AgentService) holdTargets(execution *models.AgentExecution, incident *models.Incident, attempt string) (func(), error) {
+	targets := planTargets(planOf(execution), incident)
+	if err := acquireTargetLeases(execution.ID, targets, time.Now()); err != nil {
+		recordBlocked(execution.IncidentID, execution.ID, "system", attempt, err)
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return func() {}, nil
+	}
+	log.Printf("🔒 [Agent] Execution %s holds %s", execution.ID.String()[:8], strings.Join(targets, ", "))
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(targetLeaseRenewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				if renewed, err := renewTargetLeases(execution.ID, now); err != nil {
+					log.Printf("⚠️  [Agent] Failed to renew leases of execution %s: %v", execution.ID.String()[:8], err)
+				} else if renewed < len(targets) {
+					log.Printf("⚠️  [Agent] Execution %s lost %d of its %d target lease(s)", execution.ID.String()[:8], len(targets)-renewed, len(targets))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+		releaseTargetLeases(execution.ID)
+	}, nil
+}
+
+// acquireTargetLeases takes or renews a lease on each target in one transaction. A lease held by another
+// execution is only taken over once it has expired.
+func acquireTargetLeases(executionID uuid.UUID, targets []string, now time.Time) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		for _, target := range targets {
+			result := tx.Exec(`INSERT INTO agent_target_leases (target, execution_id, acquired_at, expires_at)
+				VALUES (?, ?, ?, ?)
+				ON CONFLICT (target) DO UPDATE SET execution_id = EXCLUDED.execution_id,
+					acquired_at = EXCLUDED.acquired_at, expires_at = EXCLUDED.expires_at
+				WHERE agent_target_leases.expires_at < ? OR agent_target_leases.execution_id = EXCLUDED.execution_id`,
+				target, executionID, now, now.Add(targetLeaseTTL), now)
+			if result.Error != nil {
+				return fmt.Errorf("failed to lease %s: %w", target, result.Error)
+			}
+			if result.RowsAffected == 0 {
+				var lease models.AgentTargetLease
+				if err := tx.First(&lease, "target = ?", target).Error; err != nil {
+					return fmt.Errorf("%w: %s", ErrTargetLocked, target)
+				}
+				return targetLockedError(&lease)
+			}
+		}
+		return nil
+	})
+}
+
+// renewTargetLeases extends every lease the execution still holds, returning how many it holds
+func renewTargetLeases(executionID uuid.UUID, now time.Time) (int, error) {
+	result := db.DB.Model(&models.AgentTargetLease{}).
+		Where("execution_id = ?", executionID).
+		Update("expires_at", now.Add(targetLeaseTTL))
+	return int(result.RowsAffected), result.Error
+}
+
+// releaseTargetLeases gives up every lease the execution holds. Every path that ends an execution calls
+// it, directly or through holdTargets' release, so its targets are free as soon as it is over.
+func releaseTargetLeases(executionID uuid.UUID) {
+	if err := db.DB.Where("execution_id = ?", executionID).Delete(&models.AgentTargetLease{}).Error; err != nil {
+		log.Printf("⚠️  [Agent] Failed to release leases of execution %s: %v", executionID.String()[:8], err)
+	}
+}
+
+// foreignLease returns the unexpired lease another execution holds on target, or nil
+func foreignLease(target string, executionID uuid.UUID, now time.Time) *models.AgentTargetLease {
+	var lease models.AgentTargetLease
+	if err := db.DB.Where("target = ? AND execution_id <> ? AND expires_at > ?", target, executionID, now).First(&lease).Error; err != nil {
+		return nil
+	}
+	return &lease
+}
+
+func targetLockedError(lease *models.AgentTargetLease) error {
+	return fmt.Errorf("%w: %s is held by execution %s until %s", ErrTargetLocked,
+		lease.Target, lease.ExecutionID.String()[:8], lease.ExpiresAt.Format(time.RFC3339))
+}
+
+// --- Circuit breaker ---
+
+// BreakerStatus is the circuit breaker and target leases as reported by GET /agent/guards
+type BreakerStatus struct {
+	Open             bool                      `json:"open"`
+	Breaker          models.AgentBreakerState  `json:"breaker"`
+	RecentFailures   int64                     `json:"recent_failures"`
+	FailureThreshold int                       `json:"failure_threshold"`
+	WindowMinutes    int                       `json:"window_minutes"`
+	Leases           []models.AgentTargetLease `json:"leases"`
+}
+
+// checkCircuitBreaker refuses while the breaker is open, opening it first if recent failures reached the threshold
+func checkCircuitBreaker(now time.Time) error {
+	state, err := evaluateCircuitBreaker(now)
+	if err != nil {
+		return fmt.Errorf("failed to check the circuit breaker: %w", err)
+	}
+	if state.OpenedAt != nil {
+		return fmt.Errorf("%w since %s (%s); an operator must reset it", ErrCircuitOpen, state.OpenedAt.Format(time.RFC3339), state.Reason)
+	}
+	return nil
+}
+
+// recentFailures counts real executions that ran commands and failed inside the window, ignoring
+// any from before the last reset
+func recentFailures(state models.AgentBreakerState, now time.Time) (int64, error) {
+	since := now.Add(-breakerWindow())
+	if state.ResetAt != nil && state.ResetAt.After(since) {
+		since = *state.ResetAt
+	}
+	var count int64
+	err := db.DB.Model(&models.AgentExecution{}).
+		Where("status = ? AND dry_run = ? AND started_at IS NOT NULL AND updated_at >= ?", models.StatusFailed, false, since).
+		Count(&count).Error
+	return count, err
+}
+
+// evaluateCircuitBreaker opens the breaker once recent failures reach the threshold. It stays open until
+// reset, so a run of failures stops the agent until someone has looked at why.
+func evaluateCircuitBreaker(now time.Time) (models.AgentBreakerState, error) {
+	var state models.AgentBreakerState
+	opened := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		// Serialise evaluations so the breaker opens, and is announced, once
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "agent_circuit_breaker").Error; err != nil {
+			return err
+		}
+		if err := tx.Where(models.AgentBreakerState{ID: breakerStateID}).FirstOrInit(&state).Error; err != nil {
+			return err
+		}
+		if state.OpenedAt != nil {
+			return nil
+		}
+		failures, err := recentFailures(state, now)
+		if err != nil {
+			return err
+		}
+		if failures < int64(breakerThreshold()) {
+			return nil
+		}
+		state.OpenedAt = &now
+		state.Reason = fmt.Sprintf("%d executions failed in the last %s", failures, breakerWindow())
+		opened = true
+		return tx.Save(&state).Error
+	})
+	if err != nil {
+		return state, err
+	}
+
+	if opened {
+		log.Printf("🛑 [Agent] Circuit breaker opened: %s", state.Reason)
+		wshub.WSHub.Broadcast <- map[string]interface{}{
+			"type":    "agent_circuit_open",
+			"message": "Agent actions are suspended: " + state.Reason,
+		}
+	}
+	return state, nil
+}
+
+// GetBreakerStatus reports the circuit breaker and the targets currently leased
+func GetBreakerStatus() (*BreakerStatus, error) {
+	now := time.Now()
+	state, err := evaluateCircuitBreaker(now)
+	if err != nil {
+		return nil, err
+	}
+	failures, err := recentFailures(state, now)
+	if err != nil {
+		return nil, err
+	}
+	leases := []models.AgentTargetLease{}
+	if err := db.DB.Where("expires_at > ?", now).Order("target").Find(&leases).Error; err != nil {
+		return nil, err
+	}
+	return &BreakerStatus{
+		Open:             state.OpenedAt != nil,
+		Breaker:          state,
+		RecentFailures:   failures,
+		FailureThreshold: breakerThreshold(),
+		WindowMinutes:    int(breakerWindow() / time.Minute),
+		Leases:           leases,
+	}, nil
+}
+
+// ResetCircuitBreaker closes the breaker; failures before the reset no longer count toward opening it again
+func ResetCircuitBreaker(actor string) (*BreakerStatus, error) {
+	now := time.Now()
+	state := models.AgentBreakerState{ID: breakerStateID, ResetAt: &now, ResetBy: actor}
+	if err := db.DB.Save(&state).Error; err != nil {
+		return nil, fmt.Errorf("failed to reset the circuit breaker: %w", err)
+	}
+
+	log.Printf("🟢 [Agent] Circuit breaker reset by %s", actor)
+	wshub.WSHub.Broadcast <- map[string]interface{}{
+		"type":    "agent_circuit_closed",
+		"message": "Agent circuit breaker reset by " + actor,
+	}
+	return GetBreakerStatus()
+}
@@ -0,0 +1,166 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+func leasesHeldBy(t *testing.T, execution *models.AgentExecution) []models.AgentTargetLease {
+	t.Helper()
+	var leases []models.AgentTargetLease
+	if err := db.DB.Where("execution_id = ?", execution.ID).Find(&leases).Error; err != nil {
+		t.Fatalf("failed to load leases: %v", err)
+	}
+	return leases
+}
+
+func TestTargetLeasesAreExclusiveAndRenewed(t *testing.T) {
+	openTestDB(t)
+	incident := seedIncident(t, "redis-test")
+	first := seedExecution(t, incident, nil)
+	second := seedExecution(t, incident, nil)
+
+	// Taken just under a TTL ago, so it is about to expire
+	acquired := time.Now().Add(-targetLeaseTTL + time.Second)
+	if err := acquireTargetLeases(first.ID, []string{"redis-test"}, acquired); err != nil {
+		t.Fatalf("acquireTargetLeases: %v", err)
+	}
+	if err := acquireTargetLeases(second.ID, []string{"redis-test"}, time.Now()); !errors.Is(err, ErrTargetLocked) {
+		t.Fatalf("second execution got the lease: %v", err)
+	}
+
+	now := time.Now()
+	renewed, err := renewTargetLeases(first.ID, now)
+	if err != nil || renewed != 1 {
+		t.Fatalf("renewTargetLeases = %d, %v; want 1 lease renewed", renewed, err)
+	}
+	leases := leasesHeldBy(t, first)
+	if len(leases) != 1 || leases[0].ExpiresAt.Before(now.Add(targetLeaseTTL-time.Second)) {
+		t.Errorf("leases = %+v, want one expiring a full TTL from the renewal", leases)
+	}
+	// Still held after the original expiry
+	if err := acquireTargetLeases(second.ID, []string{"redis-test"}, acquired.Add(targetLeaseTTL+time.Second)); !errors.Is(err, ErrTargetLocked) {
+		t.Errorf("renewed lease was taken over: %v", err)
+	}
+
+	if renewed, _ := renewTargetLeases(second.ID, now); renewed != 0 {
+		t.Errorf("renewTargetLeases renewed %d lease(s) the execution never held", renewed)
+	}
+}
+
+func TestHoldTargetsReleasesOnRelease(t *testing.T) {
+	openTestDB(t)
+	incident := seedIncident(t, "redis-test")
+	execution := seedExecution(t, incident, func(e *models.AgentExecution) {
+		e.Plan = models.JSONB{Data: []models.PlanStep{{Action: "clear_redis_cache"}}}
+	})
+
+	release, err := NewAgentService().holdTargets(execution, incident, "test")
+	if err != nil {
+		t.Fatalf("holdTargets: %v", err)
+	}
+	if len(leasesHeldBy(t, execution)) != 1 {
+		t.Fatal("holdTargets did not lease redis-test")
+	}
+	release()
+	if leases := leasesHeldBy(t, execution); len(leases) != 0 {
+		t.Errorf("leases after release = %+v, want none", leases)
+	}
+}
+
+// Every path that ends an execution outside a running workflow frees its targets
+func TestTerminalPathsReleaseLeases(t *testing.T) {
+	s := NewAgentService()
+	tests := []struct {
+		name  string
+		setup func(*models.AgentExecution)
+		end   func(t *testing.T, execution *models.AgentExecution)
+	}{
+		{
+			name:  "interrupted execution",
+			setup: crashCases[4].setup,
+			end:   func(t *testing.T, e *models.AgentExecution) { s.failInterruptedExecution(e) },
+		},
+		{
+			name:  "interrupted rollback",
+			setup: crashCases[8].setup,
+			end:   func(t *testing.T, e *models.AgentExecution) { s.failInterruptedRollback(e) },
+		},
+		{
+			name:  "approval expired",
+			setup: func(e *models.AgentExecution) { e.Status = models.StatusAwaitingApproval },
+			end: func(t *testing.T, e *models.AgentExecution) {
+				if !s.expireApproval(e) {
+					t.Fatal("expireApproval did not expire the execution")
+				}
+			},
+		},
+		{
+			name:  "cancelled with no workflow running here",
+			setup: func(e *models.AgentExecution) { e.Status = models.StatusExecuting },
+			end: func(t *testing.T, e *models.AgentExecution) {
+				if _, err := s.CancelExecution(e.ID, "alice", "wrong target"); err != nil {
+					t.Fatalf("CancelExecution: %v", err)
+				}
+			},
+		},
+		{
+			name:  "job dead-lettered",
+			setup: func(e *models.AgentExecution) { e.Status = models.StatusExecuting },
+			end: func(t *testing.T, e *models.AgentExecution) {
+				job, err := services.EnqueueJob(services.JobAgentExecute, executionJobPayload{ExecutionID: e.ID})
+				if err != nil {
+					t.Fatalf("failed to queue job: %v", err)
+				}
+				s.failExecutionForJob(job, errors.New("gave up"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+			incident := seedIncident(t, "redis-test")
+			execution := seedExecution(t, incident, tt.setup)
+			if err := acquireTargetLeases(execution.ID, []string{"redis-test"}, time.Now()); err != nil {
+				t.Fatalf("acquireTargetLeases: %v", err)
+			}
+
+			tt.end(t, execution)
+
+			if after := reloadExecution(t, execution.ID); after.Status != models.StatusFailed && after.Status != models.StatusCancelled {
+				t.Errorf("status = %s, want the execution ended", after.Status)
+			}
+			if leases := leasesHeldBy(t, execution); len(leases) != 0 {
+				t.Errorf("leases = %+v, want them released", leases)
+			}
+		})
+	}
+}
+
+// A workflow running in this process keeps its leases until its current command stops and it releases them
+func TestCancelKeepsLeasesOfARunningWorkflow(t *testing.T) {
+	openTestDB(t)
+	incident := seedIncident(t, "redis-test")
+	execution := seedExecution(t, incident, func(e *models.AgentExecution) { e.Status = models.StatusExecuting })
+	if err := acquireTargetLeases(execution.ID, []string{"redis-test"}, time.Now()); err != nil {
+		t.Fatalf("acquireTargetLeases: %v", err)
+	}
+	ctx, untrack := trackExecution(context.Background(), execution.ID)
+	defer untrack()
+
+	if _, err := NewAgentService().CancelExecution(execution.ID, "alice", "wrong target"); err != nil {
+		t.Fatalf("CancelExecution: %v", err)
+	}
+	if ctx.Err() == nil {
+		t.Error("the running workflow was not interrupted")
+	}
+	if len(leasesHeldBy(t, execution)) != 1 {
+		t.Error("leases were released while the workflow may still be running a command")
+	}
+}
@@ -0,0 +1,173 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+type executionJobPayload struct {
+	ExecutionID uuid.UUID `json:"execution_id"`
+}
+
+// RegisterJobs registers the agent workflow phases with the job queue.
+// Planning (thinking + preview) is safe to retry. Execution runs commands against live systems,
+// so its failures are permanent; its second attempt exists only so a job reclaimed after a restart
+// reaches the handler, which decides whether it can safely resume (see RecoverExecutions).
+func RegisterJobs() {
+	s := NewAgentService()
+
+	services.RegisterJobType(services.JobAgentPlan, services.JobType{
+		Concurrency: 2,
+		MaxAttempts: 3,
+		Timeout:     3 * time.Minute,
+		Handler: func(ctx context.Context, job *models.Job) error {
+			execution, incident, err := loadExecutionForJob(job)
+			if err != nil {
+				return err
+			}
+			// A reclaimed job may find the execution already past planning or cancelled
+			if execution.Status != models.StatusThinking && execution.Status != models.StatusPreviewing {
+				return nil
+			}
+
+			ctx, untrack := trackExecution(ctx, execution.ID)
+			defer untrack()
+			err = s.runWorkflow(ctx, execution, incident)
+			if err == nil || s.workflowStopped(ctx, execution) {
+				return nil
+			}
+			return err
+		},
+		OnDead: s.failExecutionForJob,
+	})
+
+	services.RegisterJobType(services.JobAgentExecute, services.JobType{
+		Concurrency: 1,
+		MaxAttempts: 2,
+		Timeout:     5 * time.Minute,
+		Handler:     s.executeJob,
+		OnDead:      s.failExecutionForJob,
+	})
+
+	services.RegisterJobType(services.JobAgentRollback, services.JobType{
+		Concurrency: 1,
+		MaxAttempts: 2,
+		Timeout:     3 * time.Minute,
+		Handler:     s.rollbackJob,
+		OnDead:      s.failExecutionForJob,
+	})
+}
+
+// executeJob runs the approved plan (agent.execute). A job reclaimed after a restart only resumes where
+// that is safe; see planExecuteJob.
+func (s *AgentService) executeJob(ctx context.Context, job *models.Job) error {
+	execution, incident, err := loadExecutionForJob(job)
+	if err != nil {
+		return err
+	}
+
+	ctx, untrack := trackExecution(ctx, execution.ID)
+	defer untrack()
+
+	switch planExecuteJob(execution) {
+	case recoveryFailRollback:
+		s.failInterruptedRollback(execution)
+		return nil
+	case recoveryFailExecution:
+		s.failInterruptedExecution(execution)
+		return nil
+	case recoveryNone:
+		return nil
+	}
+	err = s.continueWorkflowAfterApproval(ctx, execution, incident)
+	if err == nil || s.workflowStopped(ctx, execution) {
+		return nil
+	}
+	return services.Permanent(err)
+}
+
+// rollbackJob runs an approved rollback (agent.rollback); one a previous attempt started is failed instead
+func (s *AgentService) rollbackJob(ctx context.Context, job *models.Job) error {
+	execution, incident, err := loadExecutionForJob(job)
+	if err != nil {
+		return err
+	}
+	switch planRollbackJob(execution) {
+	case recoveryFailRollback:
+		s.failInterruptedRollback(execution)
+		return nil
+	case recoveryNone:
+		return nil
+	}
+
+	// A rollback changes the targets too, so it waits its turn like any execution
+	if !execution.DryRun {
+		release, err := s.holdTargets(execution, incident, "Rollback of "+execution.RecommendedAction)
+		if err != nil {
+			return services.Permanent(err)
+		}
+		defer release()
+	}
+
+	ctx, untrack := trackExecution(ctx, execution.ID)
+	defer untrack()
+	err = s.runRollback(ctx, execution, incident)
+	if err == nil || s.workflowStopped(ctx, execution) {
+		return nil
+	}
+	return services.Permanent(err)
+}
+
+// workflowStopped reports whether a phase error came from the execution or its job being cancelled
+// rather than a failure worth retrying. A cancelled job leaves no dead letter, so the execution is failed here.
+func (s *AgentService) workflowStopped(ctx context.Context, execution *models.AgentExecution) bool {
+	if executionFinished(execution.ID) {
+		return true
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		s.failExecution(execution, "Stopped because its background job was cancelled")
+		return true
+	}
+	return false
+}
+
+func loadExecutionForJob(job *models.Job) (*models.AgentExecution, *models.Incident, error) {
+	var payload executionJobPayload
+	if err := services.DecodeJobPayload(job, &payload); err != nil {
+		return nil, nil, err
+	}
+
+	var execution models.AgentExecution
+	if err := db.DB.First(&execution, "id = ?", payload.ExecutionID).Error; err != nil {
+		return nil, nil, services.Permanent(fmt.Errorf("agent execution %s not found", payload.ExecutionID))
+	}
+	var incident models.Incident
+	if err := db.DB.Preload("Analysis").First(&incident, "id = ?", execution.IncidentID).Error; err != nil {
+		return nil, nil, services.Permanent(fmt.Errorf("incident %s not found", execution.IncidentID))
+	}
+	return &execution, &incident, nil
+}
+
+// failExecutionForJob marks the execution failed once its job is dead-lettered
+func (s *AgentService) failExecutionForJob(job *models.Job, err error) {
+	var payload executionJobPayload
+	if services.DecodeJobPayload(job, &payload) != nil {
+		return
+	}
+	var execution models.AgentExecution
+	if db.DB.First(&execution, "id = ?", payload.ExecutionID).Error != nil {
+		return
+	}
+	if execution.Status == models.StatusCompleted || execution.Status == models.StatusFailed || execution.Status == models.StatusCancelled {
+		return
+	}
+	s.failExecution(&execution, err.Error())
+	releaseTargetLeases(execution.ID)
+}
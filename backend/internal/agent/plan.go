@@ -0,0 +1,317 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// maxPlanSteps bounds how many actions the model may chain in one plan
+const maxPlanSteps = 3
+
+// ErrInvalidStepSelection is returned when approving steps that are not in the plan
+var ErrInvalidStepSelection = errors.New("invalid plan step selection")
+
+// planStepDecision is one step of the plan the model proposes
+type planStepDecision struct {
+	Action    string                `json:"action"`
+	Condition *models.StepCondition `json:"condition,omitempty"`
+	Reason    string                `json:"reason"`
+}
+
+// validatePlan checks proposed steps, from the agent or an approver's edit: catalog actions only, the first
+// step unconditional, and every condition naming a check that the previous step's action actually runs
+func validatePlan(steps []planStepDecision, recommended string, incident *models.Incident, dryRun bool, requester string) error {
+	if len(steps) > maxPlanSteps {
+		return fmt.Errorf("plan has %d steps; at most %d are allowed", len(steps), maxPlanSteps)
+	}
+	if steps[0].Action != recommended {
+		return fmt.Errorf("plan must start with recommended_action %q", recommended)
+	}
+	if steps[0].Condition != nil {
+		return errors.New("the first plan step cannot have a condition")
+	}
+
+	for i, step := range steps {
+		action, ok := GetAction(step.Action)
+		if !ok {
+			return fmt.Errorf("step %d: %q is not an available action", i+1, step.Action)
+		}
+		err := ValidateAgentAction(AgentAction{
+			IncidentID: incident.ID.String(),
+			Action:     action.Name,
+			Target:     actionTarget(action, incident),
+			DryRun:     dryRun,
+			Requester:  requester,
+		}, incident)
+		if err != nil {
+			return fmt.Errorf("step %d: %v", i+1, err)
+		}
+		if i == 0 || step.Condition == nil || step.Condition.Check == "" {
+			continue
+		}
+
+		previous, _ := GetAction(steps[i-1].Action)
+		var names []string
+		found := false
+		for _, check := range previous.Checks {
+			names = append(names, fmt.Sprintf("%q", check.Name))
+			found = found || check.Name == step.Condition.Check
+		}
+		if !found {
+			return fmt.Errorf("step %d: condition check %q is not a check of %s (checks: %s)",
+				i+1, step.Condition.Check, previous.Name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+func planOf(execution *models.AgentExecution) []models.PlanStep {
+	var steps []models.PlanStep
+	raw, _ := json.Marshal(execution.Plan.Data)
+	json.Unmarshal(raw, &steps)
+	return steps
+}
+
+func setPlan(execution *models.AgentExecution, steps []models.PlanStep) {
+	execution.Plan = models.JSONB{Data: steps}
+}
+
+// commandsStarted reports whether the current step had started running commands. Executions from
+// before plans existed have no steps, so StartedAt is all there is to go on.
+func commandsStarted(execution *models.AgentExecution) bool {
+	steps := planOf(execution)
+	if len(steps) == 0 {
+		return execution.StartedAt != nil
+	}
+	if execution.CurrentStep >= len(steps) {
+		return false
+	}
+	return steps[execution.CurrentStep].Status == models.StepRunning
+}
+
+// lastRunStep is the most recent step before index that ran, or nil if none did
+func lastRunStep(steps []models.PlanStep, index int) *models.PlanStep {
+	for i := index - 1; i >= 0; i-- {
+		if steps[i].Status == models.StepPassed || steps[i].Status == models.StepFailed {
+			return &steps[i]
+		}
+	}
+	return nil
+}
+
+// stepSkipReason says why a step should not run, or "" if it should
+func stepSkipReason(steps []models.PlanStep, index int) string {
+	step := steps[index]
+	if !step.Approved {
+		return "Not approved"
+	}
+	if step.Condition == nil {
+		return ""
+	}
+
+	previous := lastRunStep(steps, index)
+	if previous == nil {
+		return "Condition not met: no earlier step ran"
+	}
+	passed, matched := true, false
+	for _, check := range previous.Checks {
+		if step.Condition.Check == "" || check.CheckName == step.Condition.Check {
+			passed = passed && check.Passed
+			matched = true
+		}
+	}
+	if !matched {
+		return fmt.Sprintf("Condition not met: %s did not run check %q", previous.Action, step.Condition.Check)
+	}
+	if passed == step.Condition.Passed {
+		return ""
+	}
+	subject := "the previous step's checks"
+	if step.Condition.Check != "" {
+		subject = fmt.Sprintf("%q", step.Condition.Check)
+	}
+	if passed {
+		return fmt.Sprintf("Condition not met: %s passed", subject)
+	}
+	return fmt.Sprintf("Condition not met: %s failed", subject)
+}
+
+// describeCondition renders a step condition for logs and the timeline
+func describeCondition(condition *models.StepCondition) string {
+	if condition == nil {
+		return "always"
+	}
+	result := "failed"
+	if condition.Passed {
+		result = "passed"
+	}
+	if condition.Check == "" {
+		return "if the previous step's checks " + result
+	}
+	return fmt.Sprintf("if %q %s", condition.Check, result)
+}
+
+// approvePlanSteps marks the selected steps approved; nil selects every step
+func approvePlanSteps(steps []models.PlanStep, selected []int) ([]models.PlanStep, error) {
+	if selected == nil {
+		for i := range steps {
+			steps[i].Approved = true
+		}
+		return steps, nil
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("%w: approve at least one step, or reject the plan", ErrInvalidStepSelection)
+	}
+	for _, index := range selected {
+		if index < 0 || index >= len(steps) {
+			return nil, fmt.Errorf("%w: step %d does not exist (plan has %d)", ErrInvalidStepSelection, index+1, len(steps))
+		}
+		steps[index].Approved = true
+	}
+	return steps, nil
+}
+
+func approvedStepIndexes(steps []models.PlanStep) []int {
+	indexes := []int{}
+	for i, step := range steps {
+		if step.Approved {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// approvedSteps is the steps that will run unless a condition skips them
+func approvedSteps(steps []models.PlanStep) []models.PlanStep {
+	var approved []models.PlanStep
+	for _, step := range steps {
+		if step.Approved {
+			approved = append(approved, step)
+		}
+	}
+	return approved
+}
+
+// describeApproval notes a partial approval for the timeline
+func describeApproval(steps []models.PlanStep) string {
+	approved := approvedStepIndexes(steps)
+	if len(steps) <= 1 || len(approved) == len(steps) {
+		return ""
+	}
+	labels := make([]string, len(approved))
+	for i, index := range approved {
+		labels[i] = fmt.Sprintf("%d (%s)", index+1, steps[index].Action)
+	}
+	return fmt.Sprintf(" (steps %s of %d)", strings.Join(labels, ", "), len(steps))
+}
+
+// previewPlan builds every step's commands, risks and rollback from the catalog, and the execution's
+// combined commands, risks and impact that the approver reviews
+func previewPlan(execution *models.AgentExecution, steps []models.PlanStep) error {
+	var commands []models.Command
+	var risks []models.Risk
+	var impacts []string
+	for i := range steps {
+		step := &steps[i]
+		action, ok := GetAction(step.Action)
+		if !ok {
+			return fmt.Errorf("action %q is not in the action catalog", step.Action)
+		}
+		step.Params = withDefaultParams(action, step.Params)
+		step.Commands = stepCommands(action, step.Params, step.Dropped)
+		step.Risks = append([]models.Risk{}, action.Risks...)
+
+		// Fail closed: refuse to offer a plan with a command that has no executor or bad arguments
+		for _, cmd := range step.Commands {
+			if err := validateCommand(cmd); err != nil {
+				return fmt.Errorf("command %q cannot be run: %w", cmd.Name, err)
+			}
+		}
+
+		// Declare up front how the change is snapshotted and undone, so the approver sees it
+		step.Rollback = rollbackPlanFor(action)
+		if step.Rollback.Mode == RollbackNone {
+			step.Risks = append(step.Risks, models.Risk{Level: "medium", Description: "This action cannot be rolled back", Mitigation: step.Rollback.Notes})
+		}
+
+		commands = append(commands, step.Commands...)
+		if len(steps) == 1 {
+			risks = append(risks, step.Risks...)
+			impacts = append(impacts, action.EstimatedImpact)
+			continue
+		}
+		for _, risk := range step.Risks {
+			risk.Description = fmt.Sprintf("Step %d (%s): %s", i+1, step.Action, risk.Description)
+			risks = append(risks, risk)
+		}
+		impacts = append(impacts, fmt.Sprintf("Step %d (%s, %s): %s", i+1, step.Action, describeCondition(step.Condition), action.EstimatedImpact))
+	}
+
+	// Store as JSONB
+	setPlan(execution, steps)
+	execution.Commands = models.JSONB{Data: commands}
+	execution.Risks = models.JSONB{Data: risks}
+	execution.RollbackPlan = models.JSONB{Data: steps[0].Rollback}
+	execution.EstimatedImpact = strings.Join(impacts, "\n")
+	return nil
+}
+
+// withDefaultParams fills in the catalog default for every parameter the step does not set
+func withDefaultParams(action ActionSpec, params map[string]int) map[string]int {
+	if len(action.Params) == 0 {
+		return nil
+	}
+	filled := map[string]int{}
+	for _, spec := range action.Params {
+		filled[spec.Name] = spec.Default
+		if value, ok := params[spec.Name]; ok {
+			filled[spec.Name] = value
+		}
+	}
+	return filled
+}
+
+// validateParams checks that every parameter is declared by the action and within its limits
+func validateParams(action ActionSpec, params map[string]int) error {
+	for name, value := range params {
+		var spec *ParamSpec
+		for i := range action.Params {
+			if action.Params[i].Name == name {
+				spec = &action.Params[i]
+			}
+		}
+		if spec == nil {
+			return fmt.Errorf("%s has no parameter %q", action.Name, name)
+		}
+		if value < spec.Min || value > spec.Max {
+			return fmt.Errorf("%s: %s must be between %d and %d, got %d", action.Name, name, spec.Min, spec.Max, value)
+		}
+	}
+	return nil
+}
+
+// stepCommands is the action's commands, less any dropped, with the parameters as metadata
+func stepCommands(action ActionSpec, params map[string]int, dropped []string) []models.Command {
+	var commands []models.Command
+	for _, cmd := range expandCommands(action.Commands) {
+		if containsString(dropped, cmd.Name) {
+			continue
+		}
+		if len(params) > 0 {
+			metadata := map[string]interface{}{}
+			for key, value := range cmd.Metadata {
+				metadata[key] = value
+			}
+			for name, value := range params {
+				metadata[name] = float64(value) // As it reads back from JSON
+			}
+			cmd.Metadata = metadata
+		}
+		commands = append(commands, cmd)
+	}
+	return commands
+}
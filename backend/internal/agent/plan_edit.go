@@ -0,0 +1,273 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidPlanEdit is returned when an edited plan does not pass the same checks as the agent's
+var ErrInvalidPlanEdit = errors.New("invalid plan edit")
+
+// PlanStepEdit is one step of the plan as an approver wants it. The edit replaces the whole plan,
+// so steps can also be removed, reordered or swapped for other catalog actions. Commands the catalog
+// marks required (pre-checks) cannot be dropped, and a step must keep at least one other command.
+type PlanStepEdit struct {
+	Action       string                `json:"action"`
+	Condition    *models.StepCondition `json:"condition,omitempty"`
+	Params       map[string]int        `json:"params,omitempty"`        // Within the catalog's limits; unset ones keep their default
+	DropCommands []string              `json:"drop_commands,omitempty"` // Names of the action's commands to leave out
+}
+
+// EditPlan replaces the plan of an execution awaiting approval with an approver's version. The edit is
+// validated like the agent's own plan, previewed again, and the approval policy re-evaluated; approvals
+// already given were for the old plan, so they are cleared. The agent's plan is kept along with the diff.
+func (s *AgentService) EditPlan(executionID uuid.UUID, actor string, edits []PlanStepEdit) (*models.AgentExecution, error) {
+	var execution models.AgentExecution
+	if err := db.DB.First(&execution, "id = ?", executionID).Error; err != nil {
+		return nil, ErrExecutionNotFound
+	}
+	if execution.Status != models.StatusAwaitingApproval {
+		return nil, ErrNotAwaitingApproval
+	}
+	if execution.ApprovalExpiresAt != nil && time.Now().After(*execution.ApprovalExpiresAt) {
+		s.expireApproval(&execution)
+		return nil, fmt.Errorf("%w: the plan waited more than %s; start a new remediation", ErrApprovalExpired, approvalTTL())
+	}
+
+	var incident models.Incident
+	if err := db.DB.Preload("Analysis").First(&incident, "id = ?", execution.IncidentID).Error; err != nil {
+		return nil, fmt.Errorf("incident not found: %w", err)
+	}
+	if safetyCheck := CanAgentActOnIncident(&incident); !safetyCheck.Allowed {
+		return nil, fmt.Errorf("%w: %s", ErrNotActionable, safetyCheck.Reason)
+	}
+
+	steps, err := editedSteps(planOf(&execution), edits, &incident, actor)
+	if err != nil {
+		return nil, err
+	}
+	edited := execution
+	if err := previewPlan(&edited, steps); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPlanEdit, err)
+	}
+	capturePreviewState(context.Background(), &edited)
+
+	now := time.Now()
+	var changes []models.PlanChange
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the execution so an edit cannot race an approval or another edit
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&execution, "id = ?", executionID).Error; err != nil {
+			return ErrExecutionNotFound
+		}
+		if execution.Status != models.StatusAwaitingApproval {
+			return ErrNotAwaitingApproval
+		}
+
+		// The agent's plan is kept from the first edit; later edits are diffed against it too
+		aiPlan := aiPlanOf(&execution)
+		if len(aiPlan) == 0 {
+			aiPlan = planOf(&execution)
+		}
+		changes = diffPlans(aiPlan, planOf(&execution), steps)
+
+		decision := evaluateApprovalPolicy(&edited, &incident, now)
+		if decision.RequiredApprovals == 0 {
+			decision.RequiredApprovals = 1
+			decision.Reasons = append(decision.Reasons, "Edited plans are never auto-approved")
+		}
+		decision.Reasons = append(decision.Reasons, "Plan edited by "+actor)
+
+		// The first step is what the timeline, chat messages and rollback refer to as the execution's action
+		execution.RecommendedAction = steps[0].Action
+		execution.Plan = edited.Plan
+		execution.CurrentStep = 0
+		execution.Commands = edited.Commands
+		execution.Risks = edited.Risks
+		execution.EstimatedImpact = edited.EstimatedImpact
+		execution.RollbackPlan = edited.RollbackPlan
+		execution.PreviewState = edited.PreviewState
+		execution.AIPlan = models.JSONB{Data: aiPlan}
+		execution.PlanDiff = models.JSONB{Data: changes}
+		execution.PlanEditedBy = actor
+		execution.PlanEditedAt = &now
+		execution.ApprovalPolicy = models.JSONB{Data: decision}
+		execution.Approvals = models.JSONB{Data: []models.Approval{}}
+		return tx.Model(&models.AgentExecution{}).Where("id = ?", execution.ID).Updates(map[string]interface{}{
+			"recommended_action": execution.RecommendedAction,
+			"plan":               execution.Plan,
+			"current_step":       0,
+			"commands":           execution.Commands,
+			"risks":              execution.Risks,
+			"estimated_impact":   execution.EstimatedImpact,
+			"rollback_plan":      execution.RollbackPlan,
+			"preview_state":      execution.PreviewState,
+			"ai_plan":            execution.AIPlan,
+			"plan_diff":          execution.PlanDiff,
+			"plan_edited_by":     actor,
+			"plan_edited_at":     now,
+			"approval_policy":    execution.ApprovalPolicy,
+			"approvals":          execution.Approvals,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := make([]string, len(changes))
+	for i, change := range changes {
+		summary[i] = change.Detail
+	}
+	if len(summary) == 0 {
+		summary = []string{"no changes from the agent's plan"}
+	}
+	log.Printf("✏️  [Agent] Plan of execution %s edited by %s (%d change(s))", execution.ID.String()[:8], actor, len(changes))
+	services.RecordTimelineEvent(execution.IncidentID, "agent_plan_edited", actor,
+		fmt.Sprintf("Edited the agent's plan: %s", strings.Join(summary, "; ")),
+		map[string]interface{}{"execution_id": execution.ID, "changes": changes})
+
+	return &execution, nil
+}
+
+// editedSteps validates the edit and turns it into plan steps, keeping the agent's reasons for steps it left alone
+func editedSteps(current []models.PlanStep, edits []PlanStepEdit, incident *models.Incident, actor string) ([]models.PlanStep, error) {
+	if len(edits) == 0 {
+		return nil, fmt.Errorf("%w: the plan needs at least one step; reject the execution instead", ErrInvalidPlanEdit)
+	}
+	decisions := make([]planStepDecision, len(edits))
+	for i, edit := range edits {
+		decisions[i] = planStepDecision{Action: edit.Action, Condition: edit.Condition}
+	}
+	if err := validatePlan(decisions, edits[0].Action, incident, false, actor); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPlanEdit, err)
+	}
+
+	steps := make([]models.PlanStep, len(edits))
+	for i, edit := range edits {
+		action, _ := GetAction(edit.Action)
+		if err := validateParams(action, edit.Params); err != nil {
+			return nil, fmt.Errorf("%w: step %d: %v", ErrInvalidPlanEdit, i+1, err)
+		}
+		kept := 0
+		for _, cmd := range action.Commands {
+			if !cmd.Required && !containsString(edit.DropCommands, cmd.Name) {
+				kept++
+			}
+		}
+		for _, name := range edit.DropCommands {
+			cmd, ok := actionCommand(action, name)
+			if !ok {
+				return nil, fmt.Errorf("%w: step %d: %s has no command %q", ErrInvalidPlanEdit, i+1, action.Name, name)
+			}
+			if cmd.Required {
+				return nil, fmt.Errorf("%w: step %d: %q is required by %s and cannot be dropped", ErrInvalidPlanEdit, i+1, name, action.Name)
+			}
+		}
+		if kept == 0 {
+			return nil, fmt.Errorf("%w: step %d: dropping every command of %s leaves nothing to run; remove the step instead", ErrInvalidPlanEdit, i+1, action.Name)
+		}
+
+		reason := "Added by " + actor
+		if i < len(current) && current[i].Action == edit.Action {
+			reason = current[i].Reason
+		}
+		steps[i] = models.PlanStep{
+			Action:    edit.Action,
+			Reason:    reason,
+			Condition: edit.Condition,
+			Params:    edit.Params,
+			Dropped:   edit.DropCommands,
+			Status:    models.StepPending,
+		}
+	}
+	return steps, nil
+}
+
+func actionCommand(action ActionSpec, name string) (models.Command, bool) {
+	for _, cmd := range action.Commands {
+		if cmd.Name == name {
+			return cmd, true
+		}
+	}
+	return models.Command{}, false
+}
+
+func aiPlanOf(execution *models.AgentExecution) []models.PlanStep {
+	var steps []models.PlanStep
+	raw, _ := json.Marshal(execution.AIPlan.Data)
+	json.Unmarshal(raw, &steps)
+	return steps
+}
+
+// diffPlans lists what a person changed, step by step, going from the agent's plan to theirs. previous
+// is the plan being replaced, so a command an earlier edit dropped is recorded when it is put back.
+func diffPlans(ai, previous, human []models.PlanStep) []models.PlanChange {
+	changes := []models.PlanChange{}
+	for i := 0; i < len(ai) || i < len(human); i++ {
+		switch {
+		case i >= len(human):
+			changes = append(changes, models.PlanChange{Step: i, Kind: "step_removed", From: ai[i].Action,
+				Detail: fmt.Sprintf("removed step %d (%s)", i+1, ai[i].Action)})
+			continue
+		case i >= len(ai):
+			changes = append(changes, models.PlanChange{Step: i, Kind: "step_added", To: human[i].Action,
+				Detail: fmt.Sprintf("added step %d (%s)", i+1, human[i].Action)})
+			continue
+		case ai[i].Action != human[i].Action:
+			changes = append(changes, models.PlanChange{Step: i, Kind: "action_changed", From: ai[i].Action, To: human[i].Action,
+				Detail: fmt.Sprintf("step %d: %s instead of %s", i+1, human[i].Action, ai[i].Action)})
+			continue
+		}
+
+		from, to := describeCondition(ai[i].Condition), describeCondition(human[i].Condition)
+		if from != to {
+			changes = append(changes, models.PlanChange{Step: i, Kind: "condition_changed", From: ai[i].Condition, To: human[i].Condition,
+				Detail: fmt.Sprintf("step %d: runs %s instead of %s", i+1, to, from)})
+		}
+
+		names := make([]string, 0, len(human[i].Params))
+		for name := range human[i].Params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			before, value := ai[i].Params[name], human[i].Params[name]
+			if before != value {
+				changes = append(changes, models.PlanChange{Step: i, Kind: "param_changed", From: before, To: value,
+					Detail: fmt.Sprintf("step %d: %s %d → %d", i+1, name, before, value)})
+			}
+		}
+
+		for _, name := range human[i].Dropped {
+			if !containsString(ai[i].Dropped, name) {
+				changes = append(changes, models.PlanChange{Step: i, Kind: "command_dropped", From: name,
+					Detail: fmt.Sprintf("step %d: dropped %q", i+1, name)})
+			}
+		}
+		restorable := ai[i].Dropped
+		if i < len(previous) && previous[i].Action == human[i].Action {
+			restorable = append(append([]string{}, restorable...), previous[i].Dropped...)
+		}
+		var restored []string
+		for _, name := range restorable {
+			if !containsString(human[i].Dropped, name) && !containsString(restored, name) {
+				restored = append(restored, name)
+				changes = append(changes, models.PlanChange{Step: i, Kind: "command_restored", To: name,
+					Detail: fmt.Sprintf("step %d: restored %q", i+1, name)})
+			}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+func TestDiffPlans(t *testing.T) {
+	ai := []models.PlanStep{
+		{Action: "clear_redis_cache"},
+		{Action: "restart_redis", Condition: &models.StepCondition{Passed: false}, Params: map[string]int{"stop_timeout_seconds": 10}},
+	}
+	dropped := []models.PlanStep{ai[0], {Action: "restart_redis", Condition: ai[1].Condition, Params: ai[1].Params, Dropped: []string{"Restart Redis Container"}}}
+	tests := []struct {
+		name     string
+		previous []models.PlanStep // The plan the edit replaces; nil: the agent's
+		human    []models.PlanStep
+		want     []string // Kinds of the changes, in order
+	}{
+		{"unchanged", nil, ai, []string{}},
+		{"step removed", nil, ai[:1], []string{"step_removed"}},
+		{"step added", nil, append(append([]models.PlanStep{}, ai...), models.PlanStep{Action: "kill_idle_connections"}), []string{"step_added"}},
+		{"action swapped", nil, []models.PlanStep{{Action: "restart_redis"}, ai[1]}, []string{"action_changed"}},
+		{"condition and param", nil, []models.PlanStep{ai[0], {
+			Action:    "restart_redis",
+			Condition: &models.StepCondition{Passed: true},
+			Params:    map[string]int{"stop_timeout_seconds": 20},
+		}}, []string{"condition_changed", "param_changed"}},
+		{"command dropped", nil, dropped, []string{"command_dropped"}},
+		{"dropped command kept dropped", dropped, dropped, []string{"command_dropped"}},
+		{"dropped command restored", dropped, ai, []string{"command_restored"}},
+		{"restored under another action", dropped, []models.PlanStep{ai[0], {Action: "clear_redis_cache"}}, []string{"action_changed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := tt.previous
+			if previous == nil {
+				previous = ai
+			}
+			changes := diffPlans(ai, previous, tt.human)
+			if len(changes) != len(tt.want) {
+				t.Fatalf("changes = %+v, want kinds %v", changes, tt.want)
+			}
+			for i, change := range changes {
+				if change.Kind != tt.want[i] {
+					t.Errorf("change %d kind = %s, want %s", i, change.Kind, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// The execution's action follows the edited plan's first step, so messages and rollback name what will run
+func TestEditPlanUpdatesRecommendedAction(t *testing.T) {
+	openTestDB(t)
+	incident := seedIncident(t, "redis-test")
+	execution := seedExecution(t, incident, func(e *models.AgentExecution) {
+		e.Status = models.StatusAwaitingApproval
+		e.RecommendedAction = "clear_redis_cache"
+		e.Plan = models.JSONB{Data: []models.PlanStep{{Action: "clear_redis_cache", Status: models.StepPending}}}
+	})
+
+	edited, err := NewAgentService().EditPlan(execution.ID, "alice", []PlanStepEdit{{Action: "restart_redis"}})
+	if err != nil {
+		t.Fatalf("EditPlan: %v", err)
+	}
+	if edited.RecommendedAction != "restart_redis" {
+		t.Errorf("returned action = %s, want restart_redis", edited.RecommendedAction)
+	}
+	stored := reloadExecution(t, execution.ID)
+	if stored.RecommendedAction != "restart_redis" {
+		t.Errorf("stored action = %s, want restart_redis", stored.RecommendedAction)
+	}
+	if steps := planOf(&stored); len(steps) != 1 || steps[0].Action != "restart_redis" {
+		t.Errorf("stored plan = %+v, want one restart_redis step", steps)
+	}
+}
+
+func TestEditedStepsDroppedCommands(t *testing.T) {
+	openTestDB(t)
+	incident := &models.Incident{
+		AffectedSystems: []string{"postgres-test"},
+		Actionable:      true,
+		IncidentType:    "real_system",
+	}
+	current := []models.PlanStep{{Action: "restart_postgres", Reason: "Connections are stuck"}}
+	edit := func(drop ...string) error {
+		_, err := editedSteps(current, []PlanStepEdit{{Action: "restart_postgres", DropCommands: drop}}, incident, "alice")
+		return err
+	}
+
+	if err := edit("Drain Idle PostgreSQL Connections"); err != nil {
+		t.Errorf("dropping an optional command: %v", err)
+	}
+	for _, drop := range [][]string{
+		{"Pre-check postgres-test"}, // Required by the catalog
+		{"Drain Idle PostgreSQL Connections", "Restart PostgreSQL Container"}, // Only the pre-check would run
+		{"Not A Command"},
+	} {
+		if err := edit(drop...); !errors.Is(err, ErrInvalidPlanEdit) {
+			t.Errorf("dropping %v = %v, want ErrInvalidPlanEdit", drop, err)
+		}
+	}
+}
+
+// Required commands always run, and dropped ones are left out of the step
+func TestStepCommandsLeavesOutDropped(t *testing.T) {
+	action, _ := GetAction("restart_postgres")
+	commands := stepCommands(action, nil, []string{"Drain Idle PostgreSQL Connections"})
+	if len(commands) != 2 || commands[0].Name != "Pre-check postgres-test" || commands[1].Name != "Restart PostgreSQL Container" {
+		t.Errorf("step commands = %+v, want the pre-check then the restart", commands)
+	}
+}
@@ -0,0 +1,195 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+// inFlightStatuses are the statuses a workflow is actively moving through. awaiting_approval and
+// awaiting_rollback_approval are not in-flight: they wait on a person, and approval queues its own job.
+var inFlightStatuses = []models.AgentExecutionStatus{
+	models.StatusThinking,
+	models.StatusPreviewing,
+	models.StatusExecuting,
+	models.StatusVerifying,
+	models.StatusRollingBack,
+}
+
+// RecoverExecutions runs once at startup, before the job workers, and deals with executions left
+// in-flight by a restart. Executions that still have a queued or running job are left to the queue,
+// whose handlers apply the same rules when they pick a reclaimed job back up:
+//   - thinking / previewing: planning only reads state, so the planning phases are re-run
+//   - verifying: every command of the current step already ran, so its verification is re-run and the plan continues
+//   - executing: a step's commands may have partly run, so the execution is failed with what is known to have run
+//     (dry runs have no side effects and are simply re-run)
+//   - rolling_back: an approved rollback that never started is queued again; one cut off part-way is failed
+func RecoverExecutions() {
+	var executions []models.AgentExecution
+	if err := db.DB.Where("status IN ?", inFlightStatuses).Find(&executions).Error; err != nil {
+		log.Printf("❌ [Agent] Failed to load in-flight executions for recovery: %v", err)
+		return
+	}
+	if len(executions) == 0 {
+		return
+	}
+
+	s := NewAgentService()
+	for i := range executions {
+		execution := &executions[i]
+		pending, err := services.HasPendingJob([]string{services.JobAgentPlan, services.JobAgentExecute, services.JobAgentRollback}, "execution_id", execution.ID.String())
+		if err != nil {
+			log.Printf("⚠️  [Agent] Failed to check jobs for execution %s: %v", execution.ID.String()[:8], err)
+			continue
+		}
+		if pending {
+			log.Printf("♻️  [Agent] Execution %s (%s) will be resumed by its queued job", execution.ID.String()[:8], execution.Status)
+			continue
+		}
+		s.recoverExecution(execution)
+	}
+}
+
+// recoveryAction is what happens to an execution a restart interrupted
+type recoveryAction int
+
+const (
+	recoveryNone          recoveryAction = iota // Not in-flight; nothing to do
+	recoveryResume                              // Safe to run the phase again
+	recoveryFailExecution                       // Commands were cut off part-way: failInterruptedExecution
+	recoveryFailRollback                        // Compensating commands were cut off part-way: failInterruptedRollback
+)
+
+// planRecovery decides what to do with an in-flight execution that has no job left, and which job
+// resumes it
+func planRecovery(execution *models.AgentExecution) (recoveryAction, string) {
+	switch execution.Status {
+	case models.StatusThinking, models.StatusPreviewing:
+		return recoveryResume, services.JobAgentPlan
+	case models.StatusVerifying:
+		return recoveryResume, services.JobAgentExecute
+	case models.StatusExecuting:
+		return planExecuteJob(execution), services.JobAgentExecute
+	case models.StatusRollingBack:
+		return planRollbackJob(execution), services.JobAgentRollback
+	}
+	return recoveryNone, ""
+}
+
+// planExecuteJob decides what an agent.execute job does with its execution, including a job reclaimed
+// after a restart
+func planExecuteJob(execution *models.AgentExecution) recoveryAction {
+	switch {
+	case execution.Status == models.StatusRollingBack:
+		// An automatic rollback started by a previous attempt never finished
+		return recoveryFailRollback
+	case execution.Status == models.StatusVerifying:
+		return recoveryResume
+	case execution.Status != models.StatusExecuting:
+		return recoveryNone
+	case commandsStarted(execution) && !execution.DryRun:
+		// A previous attempt started running a step's commands and never finished
+		return recoveryFailExecution
+	default:
+		// Approved (or between plan steps) with no command running, or only simulated commands ran
+		return recoveryResume
+	}
+}
+
+// planRollbackJob decides what an agent.rollback job does with its execution
+func planRollbackJob(execution *models.AgentExecution) recoveryAction {
+	switch {
+	case execution.Status != models.StatusRollingBack:
+		return recoveryNone
+	case execution.RollbackStartedAt != nil:
+		return recoveryFailRollback
+	default:
+		return recoveryResume
+	}
+}
+
+// recoverExecution re-queues or fails an in-flight execution that has no job left to drive it
+func (s *AgentService) recoverExecution(execution *models.AgentExecution) {
+	action, jobType := planRecovery(execution)
+	switch action {
+	case recoveryNone:
+		return
+	case recoveryFailExecution:
+		s.failInterruptedExecution(execution)
+		return
+	case recoveryFailRollback:
+		s.failInterruptedRollback(execution)
+		return
+	}
+
+	if _, err := services.EnqueueJob(jobType, executionJobPayload{ExecutionID: execution.ID}); err != nil {
+		s.failExecution(execution, fmt.Sprintf("Interrupted by a backend restart while %s and could not be resumed: %v", execution.Status, err))
+		return
+	}
+	log.Printf("♻️  [Agent] Resuming execution %s from %s", execution.ID.String()[:8], execution.Status)
+	services.RecordTimelineEvent(execution.IncidentID, "agent_resumed", "system",
+		fmt.Sprintf("Agent remediation resumed after a backend restart (was %s)", execution.Status),
+		map[string]interface{}{"execution_id": execution.ID, "status": execution.Status})
+}
+
+// failInterruptedExecution fails an execution whose commands were cut off part-way. Re-running them is
+// not safe (they may not be idempotent), so it records which commands are known to have finished.
+func (s *AgentService) failInterruptedExecution(execution *models.AgentExecution) {
+	msg, completed := interruptedExecutionMessage(execution)
+	s.failExecution(execution, msg)
+	releaseTargetLeases(execution.ID) // The worker that held them is gone
+	services.RecordTimelineEvent(execution.IncidentID, "agent_interrupted", "system", msg,
+		map[string]interface{}{"execution_id": execution.ID, "completed_commands": completed})
+}
+
+// interruptedExecutionMessage explains how far the interrupted step got. Only the current step's
+// commands and logs count: earlier steps finished and were verified before it started.
+func interruptedExecutionMessage(execution *models.AgentExecution) (string, []string) {
+	var commands []models.Command
+	var logs []models.ExecutionLog
+	where := ""
+	steps := planOf(execution)
+	if execution.CurrentStep < len(steps) {
+		step := steps[execution.CurrentStep]
+		commands, logs = step.Commands, step.Logs
+		if len(steps) > 1 {
+			where = fmt.Sprintf(" of step %d of %d (%s)", execution.CurrentStep+1, len(steps), step.Action)
+		}
+	} else {
+		// Executions from before plans existed keep their commands and logs on the execution
+		commandsJSON, _ := json.Marshal(execution.Commands.Data)
+		json.Unmarshal(commandsJSON, &commands)
+		logsJSON, _ := json.Marshal(execution.ExecutionLogs.Data)
+		json.Unmarshal(logsJSON, &logs)
+	}
+
+	var completed []string
+	for i, entry := range logs {
+		if entry.Status != "success" {
+			continue
+		}
+		name := entry.Command
+		if i < len(commands) && commands[i].Name != "" {
+			name = commands[i].Name
+		}
+		completed = append(completed, name)
+	}
+
+	msg := fmt.Sprintf("Interrupted by a backend restart during execution%s: %d of %d command(s) are known to have completed",
+		where, len(completed), len(commands))
+	if len(completed) > 0 {
+		msg += fmt.Sprintf(" (%s)", strings.Join(completed, ", "))
+	}
+	if len(logs) < len(commands) {
+		msg += fmt.Sprintf(". Command %d may have partly run.", len(logs)+1)
+	} else {
+		msg += "."
+	}
+	msg += " Commands were not re-run; check the target systems before retrying."
+	return msg, completed
+}
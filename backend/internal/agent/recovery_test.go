@@ -0,0 +1,393 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+// crashedPlan is a one-step plan whose step was left in the given status when the backend stopped
+func crashedPlan(status string) models.JSONB {
+	step := models.PlanStep{
+		Action:   "clear_redis_cache",
+		Approved: true,
+		Status:   status,
+		Commands: []models.Command{
+			{Name: "Flush cache", Command: "FLUSHALL", Target: "redis-test"},
+			{Name: "Check memory", Command: "INFO memory", Target: "redis-test"},
+		},
+	}
+	if status == models.StepRunning {
+		step.StartedAt = timePtr(time.Now())
+		step.Logs = []models.ExecutionLog{{Command: "FLUSHALL", Status: "success"}}
+	}
+	return models.JSONB{Data: []models.PlanStep{step}}
+}
+
+// betweenStepsPlan has a first step that passed and a second that had not started
+func betweenStepsPlan() models.JSONB {
+	return models.JSONB{Data: []models.PlanStep{
+		{Action: "clear_redis_cache", Approved: true, Status: models.StepPassed},
+		{Action: "restart_redis", Approved: true, Status: models.StepPending},
+	}}
+}
+
+type recoveryCase struct {
+	name    string
+	setup   func(*models.AgentExecution)
+	want    recoveryAction
+	wantJob string // Job that resumes the execution; only meaningful for recoveryResume
+}
+
+// crashCases is a crash in every phase of the workflow
+var crashCases = []recoveryCase{
+	{
+		name:    "thinking",
+		setup:   func(e *models.AgentExecution) { e.Status = models.StatusThinking },
+		want:    recoveryResume,
+		wantJob: services.JobAgentPlan,
+	},
+	{
+		name:    "previewing",
+		setup:   func(e *models.AgentExecution) { e.Status = models.StatusPreviewing },
+		want:    recoveryResume,
+		wantJob: services.JobAgentPlan,
+	},
+	{
+		name: "executing, approved but no command started",
+		setup: func(e *models.AgentExecution) {
+			e.Status = models.StatusExecuting
+			e.Plan = crashedPlan(models.StepPending)
+		},
+		want:    recoveryResume,
+		wantJob: services.JobAgentExecute,
+	},
+	{
+		name: "executing, between plan steps",
+		setup: func(e *models.AgentExecution) {
+			e.Status = models.StatusExecuting
+			e.Plan = betweenStepsPlan()
+			e.CurrentStep = 1
+			e.StartedAt = timePtr(time.Now())
+		},
+		want:    recoveryResume,
+		wantJob: services.JobAgentExecute,
+	},
+	{
+		name: "executing, commands started",
+		setup: func(e *models.AgentExecution) {
+			e.Status = models.StatusExecuting
+			e.Plan = crashedPlan(models.StepRunning)
+			e.StartedAt = timePtr(time.Now())
+		},
+		want: recoveryFailExecution,
+	},
+	{
+		name: "executing, commands started in a dry run",
+		setup: func(e *models.AgentExecution) {
+			e.Status = models.StatusExecuting
+			e.DryRun = true
+			e.Plan = crashedPlan(models.StepRunning)
+			e.StartedAt = timePtr(time.Now())
+		},
+		want:    recoveryResume,
+		wantJob: services.JobAgentExecute,
+	},
+	{
+		name: "executing, planned before multi-step plans and started",
+		setup: func(e *models.AgentExecution) {
+			e.Status = models.StatusExecuting
+			e.StartedAt = timePtr(time.Now())
+		},
+		want: recoveryFailExecution,
+	},
+	{
+		name: "verifying",
+		setup: func(e *models.AgentExecution) {
+			e.Status = models.StatusVerifying
+			e.Plan = crashedPlan(models.StepRunning)
+			e.StartedAt = timePtr(time.Now())
+		},
+		want:    recoveryResume,
+		wantJob: services.JobAgentExecute,
+	},
+	{
+		name: "rolling back, rollback started",
+		setup: func(e *models.AgentExecution) {
+			e.Status = models.StatusRollingBack
+			e.StartedAt = timePtr(time.Now())
+			e.RollbackStartedAt = timePtr(time.Now())
+			e.RollbackReason = "Verification failed"
+		},
+		want: recoveryFailRollback,
+	},
+	{
+		name: "rolling back, rollback not started",
+		setup: func(e *models.AgentExecution) {
+			e.Status = models.StatusRollingBack
+			e.StartedAt = timePtr(time.Now())
+			e.RollbackReason = "Verification failed"
+		},
+		want:    recoveryResume,
+		wantJob: services.JobAgentRollback,
+	},
+	{
+		name:  "awaiting approval",
+		setup: func(e *models.AgentExecution) { e.Status = models.StatusAwaitingApproval },
+		want:  recoveryNone,
+	},
+	{
+		name:  "completed",
+		setup: func(e *models.AgentExecution) { e.Status = models.StatusCompleted },
+		want:  recoveryNone,
+	},
+}
+
+func TestPlanRecovery(t *testing.T) {
+	for _, tc := range crashCases {
+		t.Run(tc.name, func(t *testing.T) {
+			execution := &models.AgentExecution{}
+			tc.setup(execution)
+			action, jobType := planRecovery(execution)
+			if action != tc.want {
+				t.Fatalf("action = %d, want %d", action, tc.want)
+			}
+			if action == recoveryResume && jobType != tc.wantJob {
+				t.Errorf("job = %q, want %q", jobType, tc.wantJob)
+			}
+		})
+	}
+}
+
+// A reclaimed execute job applies the same rules as startup recovery
+func TestPlanExecuteJobReclaimed(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*models.AgentExecution)
+		want  recoveryAction
+	}{
+		{
+			name: "commands started",
+			setup: func(e *models.AgentExecution) {
+				e.Status = models.StatusExecuting
+				e.Plan = crashedPlan(models.StepRunning)
+			},
+			want: recoveryFailExecution,
+		},
+		{
+			name: "dry run with commands started",
+			setup: func(e *models.AgentExecution) {
+				e.Status = models.StatusExecuting
+				e.DryRun = true
+				e.Plan = crashedPlan(models.StepRunning)
+			},
+			want: recoveryResume,
+		},
+		{
+			name: "automatic rollback started",
+			setup: func(e *models.AgentExecution) {
+				e.Status = models.StatusRollingBack
+				e.RollbackStartedAt = timePtr(time.Now())
+			},
+			want: recoveryFailRollback,
+		},
+		{
+			name:  "verifying",
+			setup: func(e *models.AgentExecution) { e.Status = models.StatusVerifying },
+			want:  recoveryResume,
+		},
+		{
+			name:  "cancelled meanwhile",
+			setup: func(e *models.AgentExecution) { e.Status = models.StatusCancelled },
+			want:  recoveryNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execution := &models.AgentExecution{}
+			tt.setup(execution)
+			if got := planExecuteJob(execution); got != tt.want {
+				t.Errorf("planExecuteJob = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecoverExecutions(t *testing.T) {
+	openTestDB(t)
+	incident := seedIncident(t, "redis-test")
+
+	seeded := make([]*models.AgentExecution, len(crashCases))
+	for i, tc := range crashCases {
+		seeded[i] = seedExecution(t, incident, tc.setup)
+	}
+	// An execution whose job is still queued is left to the queue, even mid-command
+	withJob := seedExecution(t, incident, crashCases[4].setup)
+	if _, err := services.EnqueueJob(services.JobAgentExecute, executionJobPayload{ExecutionID: withJob.ID}); err != nil {
+		t.Fatalf("failed to queue job: %v", err)
+	}
+
+	RecoverExecutions()
+
+	for i, tc := range crashCases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := seeded[i]
+			after := reloadExecution(t, before.ID)
+			jobs := jobTypesFor(t, before)
+
+			switch tc.want {
+			case recoveryResume:
+				if after.Status != before.Status {
+					t.Errorf("status = %s, want it left at %s", after.Status, before.Status)
+				}
+				if len(jobs) != 1 || jobs[0] != tc.wantJob {
+					t.Errorf("queued jobs = %v, want [%s]", jobs, tc.wantJob)
+				}
+			case recoveryFailExecution, recoveryFailRollback:
+				if after.Status != models.StatusFailed {
+					t.Errorf("status = %s, want failed", after.Status)
+				}
+				if len(jobs) != 0 {
+					t.Errorf("queued jobs = %v, want none", jobs)
+				}
+				if tc.want == recoveryFailRollback && !strings.Contains(after.ErrorMessage, "Rollback was interrupted") {
+					t.Errorf("error message %q is not from failInterruptedRollback", after.ErrorMessage)
+				}
+				if tc.want == recoveryFailExecution && !strings.Contains(after.ErrorMessage, "during execution") {
+					t.Errorf("error message %q is not from failInterruptedExecution", after.ErrorMessage)
+				}
+			case recoveryNone:
+				if after.Status != before.Status || len(jobs) != 0 {
+					t.Errorf("status = %s with jobs %v, want it untouched", after.Status, jobs)
+				}
+			}
+		})
+	}
+
+	if after := reloadExecution(t, withJob.ID); after.Status != models.StatusExecuting {
+		t.Errorf("execution with a queued job: status = %s, want executing", after.Status)
+	}
+}
+
+// A job reclaimed after a restart finds its step's commands started and fails the execution without re-running them
+func TestExecuteJobReclaimedAfterCommandsStarted(t *testing.T) {
+	openTestDB(t)
+	incident := seedIncident(t, "redis-test")
+	execution := seedExecution(t, incident, crashCases[4].setup)
+
+	job, err := services.EnqueueJob(services.JobAgentExecute, executionJobPayload{ExecutionID: execution.ID})
+	if err != nil {
+		t.Fatalf("failed to queue job: %v", err)
+	}
+	job.Attempts = 2 // The first attempt's worker died mid-command
+
+	if err := NewAgentService().executeJob(context.Background(), job); err != nil {
+		t.Fatalf("executeJob returned %v, want nil so the job is not retried", err)
+	}
+	after := reloadExecution(t, execution.ID)
+	if after.Status != models.StatusFailed || !strings.Contains(after.ErrorMessage, "during execution") {
+		t.Errorf("status = %s (%q), want failed by failInterruptedExecution", after.Status, after.ErrorMessage)
+	}
+
+	var interrupted int64
+	db.DB.Model(&models.TimelineEntry{}).Where("incident_id = ? AND event_type = ?", incident.ID, "agent_interrupted").Count(&interrupted)
+	if interrupted != 1 {
+		t.Errorf("agent_interrupted timeline entries = %d, want 1", interrupted)
+	}
+}
+
+// jobTypesFor lists the types of the queued jobs that drive an execution
+func jobTypesFor(t *testing.T, execution *models.AgentExecution) []string {
+	t.Helper()
+	var jobs []models.Job
+	if err := db.DB.Where("status = ? AND payload->>'execution_id' = ?", models.JobQueued, execution.ID.String()).Find(&jobs).Error; err != nil {
+		t.Fatalf("failed to load jobs: %v", err)
+	}
+	types := make([]string, len(jobs))
+	for i, job := range jobs {
+		types[i] = job.Type
+	}
+	return types
+}
+
+func TestInterruptedExecutionMessage(t *testing.T) {
+	twoCommands := []models.Command{{Name: "Flush cache", Command: "redis"}, {Name: "Check memory", Command: "redis"}}
+	earlier := models.PlanStep{
+		Action:   "restart_redis",
+		Status:   models.StepPassed,
+		Commands: []models.Command{{Name: "Restart Redis Container", Command: "docker"}},
+		Logs:     []models.ExecutionLog{{Command: "docker", Status: "success"}},
+	}
+
+	tests := []struct {
+		name          string
+		execution     models.AgentExecution
+		wantParts     []string
+		wantCompleted []string
+	}{
+		{
+			name: "second step, first command done",
+			execution: models.AgentExecution{
+				CurrentStep: 1,
+				Plan: models.JSONB{Data: []models.PlanStep{earlier, {
+					Action:   "clear_redis_cache",
+					Status:   models.StepRunning,
+					Commands: twoCommands,
+					Logs:     []models.ExecutionLog{{Command: "redis", Status: "success"}},
+				}}},
+				// The execution-wide log also holds the earlier step's command
+				ExecutionLogs: models.JSONB{Data: []models.ExecutionLog{{Command: "docker", Status: "success"}, {Command: "redis", Status: "success"}}},
+			},
+			wantParts:     []string{"step 2 of 2 (clear_redis_cache)", "1 of 2 command(s)", "(Flush cache)", "Command 2 may have partly run"},
+			wantCompleted: []string{"Flush cache"},
+		},
+		{
+			name: "second step, nothing logged yet",
+			execution: models.AgentExecution{
+				CurrentStep: 1,
+				Plan: models.JSONB{Data: []models.PlanStep{earlier, {
+					Action: "clear_redis_cache", Status: models.StepRunning, Commands: twoCommands,
+				}}},
+			},
+			wantParts: []string{"0 of 2 command(s)", "Command 1 may have partly run"},
+		},
+		{
+			name: "single step, last command failed",
+			execution: models.AgentExecution{
+				Plan: models.JSONB{Data: []models.PlanStep{{
+					Action: "clear_redis_cache", Status: models.StepRunning, Commands: twoCommands,
+					Logs: []models.ExecutionLog{{Command: "redis", Status: "success"}, {Command: "redis", Status: "failed"}},
+				}}},
+			},
+			wantParts:     []string{"during execution: 1 of 2 command(s)", "(Flush cache).", "not re-run"},
+			wantCompleted: []string{"Flush cache"},
+		},
+		{
+			name: "planned before multi-step plans",
+			execution: models.AgentExecution{
+				Commands:      models.JSONB{Data: twoCommands},
+				ExecutionLogs: models.JSONB{Data: []models.ExecutionLog{{Command: "redis", Status: "success"}}},
+			},
+			wantParts:     []string{"1 of 2 command(s)", "Command 2 may have partly run"},
+			wantCompleted: []string{"Flush cache"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, completed := interruptedExecutionMessage(&tt.execution)
+			for _, part := range tt.wantParts {
+				if !strings.Contains(msg, part) {
+					t.Errorf("message %q does not contain %q", msg, part)
+				}
+			}
+			if strings.Join(completed, ",") != strings.Join(tt.wantCompleted, ",") {
+				t.Errorf("completed = %v, want %v", completed, tt.wantCompleted)
+			}
+		})
+	}
+}
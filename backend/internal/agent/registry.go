@@ -5,7 +5,7 @@ type SystemInfo struct {
 	Name        string   `json:"name"`
 	Actionable  bool     `json:"actionable"` // Can agents take actions on this system?
 	Description string   `json:"description"`
-	Actions     []string `json:"actions"` // Available remediation actions
+	Actions     []string `json:"actions"` // Available remediation actions, filled in from ActionCatalog
 }
 
 // ActionableSystems is the registry of all systems in the platform
@@ -15,25 +15,21 @@ var ActionableSystems = map[string]SystemInfo{
 		Name:        "redis-test",
 		Actionable:  true,
 		Description: "Mock Redis instance for testing agent actions",
-		Actions:     []string{"clear_cache", "restart", "adjust_memory_limit"},
 	},
 	"health-monitor": {
 		Name:        "health-monitor",
 		Actionable:  true,
 		Description: "System health monitoring service",
-		Actions:     []string{"reset_thresholds", "clear_alerts"},
 	},
 	"postgres-test": {
 		Name:        "postgres-test",
 		Actionable:  true,
 		Description: "Mock PostgreSQL instance for testing agent actions",
-		Actions:     []string{"kill_idle_connections", "vacuum_table", "restart"},
 	},
 	"disk-monitor": {
 		Name:        "disk-monitor",
 		Actionable:  true,
 		Description: "Disk space monitoring and cleanup",
-		Actions:     []string{"cleanup_old_logs", "restart"},
 	},
 
 	// Synthetic systems from incident generator (NOT actionable)
@@ -0,0 +1,313 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+	"gorm.io/gorm"
+)
+
+const rollbackPhaseTimeout = 90 * time.Second
+
+// Rollback modes
+const (
+	RollbackAuto     = "auto"     // Compensate as soon as execution or verification fails
+	RollbackApproval = "approval" // Wait for a person to approve the compensating commands
+	RollbackNone     = "none"     // The action cannot be undone; only a snapshot is taken
+)
+
+// ErrNotAwaitingRollback is returned when approving or declining a rollback that is not pending
+var ErrNotAwaitingRollback = errors.New("execution is not awaiting rollback approval")
+
+func healthMonitorURL() string {
+	if url := os.Getenv("HEALTH_MONITOR_URL"); url != "" {
+		return url
+	}
+	return "http://localhost:8002"
+}
+
+func rollbackPlanOf(execution *models.AgentExecution) models.RollbackPlan {
+	var plan models.RollbackPlan
+	raw, _ := json.Marshal(execution.RollbackPlan.Data)
+	json.Unmarshal(raw, &plan)
+	return plan
+}
+
+// takeSnapshot runs the plan's read-only captures. A failed capture is recorded, not fatal.
+func (s *AgentService) takeSnapshot(ctx context.Context, plan models.RollbackPlan) []models.SnapshotEntry {
+	entries := []models.SnapshotEntry{}
+	for _, cmd := range plan.Snapshot {
+		entry := models.SnapshotEntry{Target: cmd.Target, TakenAt: time.Now()}
+		state, err := s.fetchSnapshot(ctx, cmd)
+		if err != nil {
+			entry.Error = err.Error()
+			log.Printf("⚠️  [Agent] Snapshot of %s failed: %v", cmd.Target, err)
+		}
+		entry.State = state
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (s *AgentService) fetchSnapshot(ctx context.Context, cmd models.Command) (map[string]interface{}, error) {
+	result, err := runCommand(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	state, ok := result.Data["body"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid snapshot response: %s", result.Output)
+	}
+	return state, nil
+}
+
+// rollbackOrFail handles a failed command or failed verification: it compensates straight away,
+// waits for approval, or just fails the execution when the action declares no compensation
+func (s *AgentService) rollbackOrFail(ctx context.Context, execution *models.AgentExecution, incident *models.Incident, reason string) error {
+	plan := rollbackPlanOf(execution)
+	if execution.DryRun || plan.Mode == RollbackNone || len(plan.Compensate) == 0 {
+		s.failExecution(execution, reason)
+		return nil
+	}
+
+	execution.RollbackReason = reason
+	if plan.Mode == RollbackApproval {
+		execution.Status = models.StatusRollbackPending
+		if err := s.saveProgress(execution); err != nil {
+			return err
+		}
+		log.Printf("⏳ [Agent] Execution %s needs approval to roll back: %s", execution.ID.String()[:8], reason)
+		services.RecordTimelineEvent(execution.IncidentID, "agent_rollback_pending", "system",
+			fmt.Sprintf("%s. Rollback needs approval: %s", reason, plan.Notes),
+			map[string]interface{}{"execution_id": execution.ID})
+		return nil
+	}
+
+	return s.runRollback(ctx, execution, incident)
+}
+
+// runRollback runs the compensating commands, then checks the targets against the pre-change snapshot.
+// The execution always ends failed; the rollback outcome is part of its error message.
+func (s *AgentService) runRollback(ctx context.Context, execution *models.AgentExecution, incident *models.Incident) error {
+	log.Printf("⏪ [Agent] Rolling back execution %s...", execution.ID.String()[:8])
+	ctx, cancel := context.WithTimeout(ctx, rollbackPhaseTimeout)
+	defer cancel()
+
+	plan := rollbackPlanOf(execution)
+	execution.Status = models.StatusRollingBack
+	now := time.Now()
+	execution.RollbackStartedAt = &now
+	if err := s.saveProgress(execution); err != nil {
+		return err
+	}
+
+	var logs []models.ExecutionLog
+	var commandErr error
+	for _, cmd := range plan.Compensate {
+		if err := ctx.Err(); err != nil {
+			commandErr = fmt.Errorf("stopped before %s: %w", cmd.Name, err)
+			break
+		}
+		log.Printf("⏪ [Agent] Compensating: %s", cmd.Name)
+		execution.RollbackPerformed = true
+
+		startTime := time.Now()
+		result, err := runCommand(ctx, cmd)
+		entry := models.ExecutionLog{
+			Timestamp:  startTime,
+			Command:    cmd.Command,
+			Output:     result.Output,
+			Result:     result.Data,
+			Status:     "success",
+			DurationMs: time.Since(startTime).Milliseconds(),
+		}
+		if err != nil {
+			entry.Status = "failed"
+			entry.ErrorDetail = err.Error()
+		}
+		logs = append(logs, entry)
+		execution.RollbackLogs = models.JSONB{Data: logs}
+		db.DB.Model(&models.AgentExecution{}).Where("id = ?", execution.ID).Updates(map[string]interface{}{
+			"rollback_logs":      execution.RollbackLogs,
+			"rollback_performed": true,
+		})
+		if err != nil {
+			commandErr = fmt.Errorf("%s failed: %w", cmd.Name, err)
+			break
+		}
+	}
+	if errors.Is(commandErr, context.Canceled) {
+		return commandErr
+	}
+
+	// Rollback verification: every target should respond and be back to its pre-change container state
+	checks := s.rollbackChecks(ctx, execution)
+	passed := commandErr == nil
+	for _, check := range checks {
+		if !check.Passed {
+			passed = false
+		}
+	}
+	completed := time.Now()
+	execution.RollbackChecks = models.JSONB{Data: checks}
+	execution.RollbackPassed = &passed
+	execution.RollbackCompletedAt = &completed
+
+	outcome := "rolled back; targets are back to their pre-change state"
+	switch {
+	case commandErr != nil:
+		outcome = fmt.Sprintf("rollback failed: %v", commandErr)
+	case !passed:
+		outcome = "rolled back, but targets did not return to their pre-change state (see rollback checks)"
+	}
+	msg := fmt.Sprintf("%s. Automatic %s", execution.RollbackReason, outcome)
+	if execution.RollbackApprovedBy != "" {
+		msg = fmt.Sprintf("%s. Rollback approved by %s: %s", execution.RollbackReason, execution.RollbackApprovedBy, outcome)
+	}
+
+	log.Printf("⏪ [Agent] Rollback of execution %s finished (passed: %v)", execution.ID.String()[:8], passed)
+	services.RecordTimelineEvent(execution.IncidentID, "agent_rolled_back", "system", msg,
+		map[string]interface{}{"execution_id": execution.ID, "rollback_passed": passed})
+	s.failExecution(execution, msg)
+	return nil
+}
+
+// rollbackChecks re-snapshots each target and compares it with the pre-change snapshot
+func (s *AgentService) rollbackChecks(ctx context.Context, execution *models.AgentExecution) []models.VerificationCheck {
+	var before []models.SnapshotEntry
+	raw, _ := json.Marshal(execution.Snapshot.Data)
+	json.Unmarshal(raw, &before)
+
+	checks := []models.VerificationCheck{}
+	after := s.takeSnapshot(ctx, rollbackPlanOf(execution))
+	for i, entry := range after {
+		checks = append(checks, models.VerificationCheck{
+			CheckName:   entry.Target + " responding",
+			Description: "Target can be snapshotted after rollback",
+			Passed:      entry.Error == "",
+			Result:      firstNonEmpty(entry.Error, "ok"),
+			Expected:    "ok",
+		})
+		if i >= len(before) || before[i].State == nil || entry.State == nil {
+			continue
+		}
+		if expected, ok := before[i].State["container_status"].(string); ok {
+			actual, _ := entry.State["container_status"].(string)
+			checks = append(checks, models.VerificationCheck{
+				CheckName:   entry.Target + " container state",
+				Description: "Container is in the state it was in before the change",
+				Passed:      actual == expected,
+				Result:      actual,
+				Expected:    expected,
+			})
+		}
+	}
+	return checks
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ApproveRollback runs the pending compensating commands as an agent.rollback job
+func (s *AgentService) ApproveRollback(executionID uuid.UUID, actor string) (*models.AgentExecution, error) {
+	var execution models.AgentExecution
+	if err := db.DB.First(&execution, "id = ?", executionID).Error; err != nil {
+		return nil, ErrExecutionNotFound
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.AgentExecution{}).
+			Where("id = ? AND status = ?", executionID, models.StatusRollbackPending).
+			Updates(map[string]interface{}{
+				"status":               models.StatusRollingBack,
+				"rollback_approved_by": actor,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to record rollback approval: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotAwaitingRollback
+		}
+		if _, err := services.EnqueueJobTx(tx, services.JobAgentRollback, executionJobPayload{ExecutionID: executionID}); err != nil {
+			return fmt.Errorf("failed to queue rollback: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ [Agent] Rollback of execution %s approved by %s", executionID.String()[:8], actor)
+	services.RecordTimelineEvent(execution.IncidentID, "agent_rollback_approved", actor,
+		fmt.Sprintf("Approved rollback of agent action %s", execution.RecommendedAction),
+		map[string]interface{}{"execution_id": executionID})
+
+	if err := db.DB.First(&execution, "id = ?", executionID).Error; err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// DeclineRollback leaves the system as it is and fails the execution
+func (s *AgentService) DeclineRollback(executionID uuid.UUID, actor string) (*models.AgentExecution, error) {
+	var execution models.AgentExecution
+	if err := db.DB.First(&execution, "id = ?", executionID).Error; err != nil {
+		return nil, ErrExecutionNotFound
+	}
+
+	msg := fmt.Sprintf("%s. Rollback declined by %s", execution.RollbackReason, actor)
+	var outbox services.EventOutbox
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.AgentExecution{}).
+			Where("id = ? AND status = ?", executionID, models.StatusRollbackPending).
+			Updates(map[string]interface{}{
+				"status":        models.StatusFailed,
+				"success":       false,
+				"error_message": msg,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to record rollback decision: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotAwaitingRollback
+		}
+		if err := tx.First(&execution, "id = ?", executionID).Error; err != nil {
+			return err
+		}
+		return outbox.Add(tx, events.AgentFailed, execution)
+	}); err != nil {
+		return nil, err
+	}
+
+	log.Printf("❌ [Agent] Rollback of execution %s declined by %s", executionID.String()[:8], actor)
+	services.RecordTimelineEvent(execution.IncidentID, "agent_rollback_declined", actor, msg,
+		map[string]interface{}{"execution_id": executionID})
+	outbox.Dispatch()
+	return &execution, nil
+}
+
+// failInterruptedRollback fails an execution whose compensating commands were cut off part-way
+func (s *AgentService) failInterruptedRollback(execution *models.AgentExecution) {
+	msg := fmt.Sprintf("%s. Rollback was interrupted by a backend restart and was not re-run; check the target systems",
+		execution.RollbackReason)
+	s.failExecution(execution, msg)
+	releaseTargetLeases(execution.ID) // The worker that held them is gone
+	services.RecordTimelineEvent(execution.IncidentID, "agent_interrupted", "system", msg,
+		map[string]interface{}{"execution_id": execution.ID})
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 
 	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
 )
 
 // SafetyCheck represents the result of a safety validation
@@ -26,6 +27,17 @@ type AgentAction struct {
 
 // CanAgentActOnIncident checks if an AI agent is allowed to take automated actions on an incident
 func CanAgentActOnIncident(incident *models.Incident) SafetyCheck {
+	return checkAgentSafety(incident, false)
+}
+
+// CanAgentDryRunOnIncident checks if an AI agent may simulate remediation on an incident.
+// A dry run has no side effects, so advisory incidents, maintenance windows and flapping
+// alerts are reported as risks instead of blocking it.
+func CanAgentDryRunOnIncident(incident *models.Incident) SafetyCheck {
+	return checkAgentSafety(incident, true)
+}
+
+func checkAgentSafety(incident *models.Incident, dryRun bool) SafetyCheck {
 	var risks []string
 
 	// Rule 1: Incident must be explicitly marked as actionable
@@ -81,9 +93,44 @@ func CanAgentActOnIncident(incident *models.Incident) SafetyCheck {
 		}
 	}
 
-	// Advisory mode: allowed but with warnings
+	// Rule 6: Affected systems must not be inside a maintenance window that blocks agents
+	for _, sys := range incident.AffectedSystems {
+		if window := services.MaintenanceBlocksAgent(sys); window != nil {
+			if dryRun {
+				risks = append(risks, fmt.Sprintf("System '%s' is in maintenance window '%s' - a real run would be blocked", sys, window.Title))
+				continue
+			}
+			return SafetyCheck{
+				Allowed: false,
+				Reason:  fmt.Sprintf("System '%s' is in maintenance window '%s'", sys, window.Title),
+				Risks:   []string{"Agent actions are blocked during maintenance unless the window allows them"},
+			}
+		}
+	}
+
+	// Rule 7: No automated action while the alert is flapping - it will likely clear (and reopen) on its own
+	if services.IncidentIsFlapping(incident) {
+		if dryRun {
+			risks = append(risks, "Alert is flapping - a real run would be blocked until it is stable")
+		} else {
+			return SafetyCheck{
+				Allowed: false,
+				Reason:  "Alert is flapping between open and resolved",
+				Risks:   []string{"Remediating an oscillating alert can mask the real cause; wait until it is stable"},
+			}
+		}
+	}
+
+	// Advisory mode: the agent may only suggest, so real execution is refused and dry runs are allowed
 	if incident.RemediationMode == "advisory" {
-		risks = append(risks, "Remediation mode is 'advisory' - actions should be reviewed")
+		if !dryRun {
+			return SafetyCheck{
+				Allowed: false,
+				Reason:  "Incident is in advisory mode; only dry runs are allowed",
+				Risks:   []string{"Remediation mode is 'advisory' - agents may simulate but not take actions"},
+			}
+		}
+		risks = append(risks, "Remediation mode is 'advisory' - a real run would be refused")
 	}
 
 	return SafetyCheck{
@@ -97,6 +144,9 @@ func CanAgentActOnIncident(incident *models.Incident) SafetyCheck {
 func ValidateAgentAction(action AgentAction, incident *models.Incident) error {
 	// Check if agent can act on this incident
 	safetyCheck := CanAgentActOnIncident(incident)
+	if action.DryRun {
+		safetyCheck = CanAgentDryRunOnIncident(incident)
+	}
 	if !safetyCheck.Allowed {
 		return fmt.Errorf("safety check failed: %s", safetyCheck.Reason)
 	}
@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDatabaseEnv names a throwaway Postgres database for the tests that need one. Those tests truncate
+// every table they touch, so never point it at a database whose data matters.
+const testDatabaseEnv = "TEST_DATABASE_URL"
+
+var (
+	testDBOnce sync.Once
+	testDBErr  error
+)
+
+// openTestDB connects db.DB to the test database and empties the agent's tables, skipping the test when
+// no database is configured
+func openTestDB(t *testing.T) {
+	t.Helper()
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set; skipping a test that needs Postgres", testDatabaseEnv)
+	}
+
+	testDBOnce.Do(func() {
+		database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if err != nil {
+			testDBErr = err
+			return
+		}
+		db.DB = database
+		testDBErr = db.DB.AutoMigrate(
+			&models.Incident{},
+			&models.IncidentAnalysis{},
+			&models.StatusHistory{},
+			&models.AgentExecution{},
+			&models.TimelineEntry{},
+			&models.Job{},
+			&models.AgentTargetLease{},
+			&models.AgentActionRun{},
+			&models.AgentBreakerState{},
+		)
+		RegisterJobs()
+	})
+	if testDBErr != nil {
+		t.Fatalf("test database: %v", testDBErr)
+	}
+
+	if err := db.DB.Exec(`TRUNCATE agent_executions, jobs, incident_timeline, agent_target_leases,
+		agent_action_runs, agent_breaker_states, incident_analysis, incident_status_history, incidents CASCADE`).Error; err != nil {
+		t.Fatalf("failed to empty the test database: %v", err)
+	}
+}
+
+// seedIncident stores an actionable incident on the given systems
+func seedIncident(t *testing.T, systems ...string) *models.Incident {
+	t.Helper()
+	incident := &models.Incident{
+		ID:              uuid.New(),
+		Message:         "Redis memory usage critical",
+		Source:          "redis-test",
+		Status:          "triage",
+		IncidentType:    "real_system",
+		Actionable:      true,
+		AffectedSystems: systems,
+		RemediationMode: "automated",
+		MetricsSnapshot: "{}",
+	}
+	if err := db.DB.Create(incident).Error; err != nil {
+		t.Fatalf("failed to seed incident: %v", err)
+	}
+	return incident
+}
+
+// seedExecution stores an execution for the incident after applying change to it
+func seedExecution(t *testing.T, incident *models.Incident, change func(*models.AgentExecution)) *models.AgentExecution {
+	t.Helper()
+	execution := &models.AgentExecution{
+		ID:                uuid.New(),
+		IncidentID:        incident.ID,
+		RecommendedAction: "clear_redis_cache",
+		AgentModel:        "test",
+	}
+	if change != nil {
+		change(execution)
+	}
+	if err := db.DB.Create(execution).Error; err != nil {
+		t.Fatalf("failed to seed execution: %v", err)
+	}
+	return execution
+}
+
+func reloadExecution(t *testing.T, id uuid.UUID) models.AgentExecution {
+	t.Helper()
+	var execution models.AgentExecution
+	if err := db.DB.First(&execution, "id = ?", id).Error; err != nil {
+		t.Fatalf("failed to reload execution %s: %v", id, err)
+	}
+	return execution
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// maxThinkingAttempts bounds the repair loop: the first answer plus re-prompts with the validation error
+const maxThinkingAttempts = 3
+
+// ErrInvalidDecision is returned when the model's answer fails validation
+var ErrInvalidDecision = errors.New("invalid AI decision")
+
+// thinkingResult is the JSON the thinking phase asks the model for; no other fields are accepted
+type thinkingResult struct {
+	Analysis          string `json:"analysis"`
+	RecommendedAction string `json:"recommended_action"`
+	Reasoning         string `json:"reasoning"`
+
+	Plan []planStepDecision `json:"plan,omitempty"` // Optional follow-up steps; defaults to just recommended_action
+}
+
+// parseThinkingResponse extracts the decision from the model's answer and validates it: the JSON must
+// match thinkingResult exactly, every field must be set, and the action (and each plan step) must be
+// a catalog action that ValidateAgentAction allows on one of the incident's affected systems
+func parseThinkingResponse(response string, incident *models.Incident, dryRun bool) (thinkingResult, error) {
+	var result thinkingResult
+
+	// The model may wrap the JSON in prose
+	jsonStart := strings.Index(response, "{")
+	jsonEnd := strings.LastIndex(response, "}")
+	if jsonStart == -1 || jsonEnd <= jsonStart {
+		return result, fmt.Errorf("%w: response does not contain a JSON object", ErrInvalidDecision)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(response[jsonStart : jsonEnd+1])))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return result, fmt.Errorf("%w: %v", ErrInvalidDecision, err)
+	}
+
+	var missing []string
+	if strings.TrimSpace(result.Analysis) == "" {
+		missing = append(missing, "analysis")
+	}
+	if strings.TrimSpace(result.RecommendedAction) == "" {
+		missing = append(missing, "recommended_action")
+	}
+	if strings.TrimSpace(result.Reasoning) == "" {
+		missing = append(missing, "reasoning")
+	}
+	if len(missing) > 0 {
+		return result, fmt.Errorf("%w: missing required field(s): %s", ErrInvalidDecision, strings.Join(missing, ", "))
+	}
+
+	action, ok := GetAction(result.RecommendedAction)
+	if !ok {
+		return result, fmt.Errorf("%w: %q is not an available action", ErrInvalidDecision, result.RecommendedAction)
+	}
+	err := ValidateAgentAction(AgentAction{
+		IncidentID: incident.ID.String(),
+		Action:     action.Name,
+		Target:     actionTarget(action, incident),
+		DryRun:     dryRun,
+		Requester:  "agent",
+	}, incident)
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", ErrInvalidDecision, err)
+	}
+
+	if len(result.Plan) == 0 {
+		result.Plan = []planStepDecision{{Action: result.RecommendedAction, Reason: result.Reasoning}}
+	}
+	if err := validatePlan(result.Plan, result.RecommendedAction, incident, dryRun, "agent"); err != nil {
+		return result, fmt.Errorf("%w: %v", ErrInvalidDecision, err)
+	}
+	return result, nil
+}
+
+// repairPrompt re-asks with the previous answer and why it was rejected
+func repairPrompt(prompt, response string, validationErr error, actions []ActionSpec) string {
+	names := make([]string, len(actions))
+	for i, action := range actions {
+		names[i] = fmt.Sprintf("%q", action.Name)
+	}
+	return fmt.Sprintf(`%s
+
+YOUR PREVIOUS ANSWER WAS REJECTED:
+%s
+
+Reason: %v
+
+Answer again with ONLY the JSON object, using exactly the fields "analysis", "recommended_action", "reasoning" and optionally "plan".
+"recommended_action" must be one of: %s`, prompt, response, validationErr, strings.Join(names, ", "))
+}
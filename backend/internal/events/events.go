@@ -0,0 +1,112 @@
+// Package events is a small in-process publish/subscribe bus for domain events.
+// Outbound integrations (notifications) subscribe here instead of being called directly; webhooks are
+// queued in the same transaction as the change an event describes (see services.EventOutbox).
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types published by the backend
+const (
+	IncidentCreated       = "incident.created"
+	IncidentUpdated       = "incident.updated"
+	IncidentResolved      = "incident.resolved"
+	IncidentDeleted       = "incident.deleted"
+	IncidentAssigned      = "incident.assigned"
+	IncidentSLABreached   = "incident.sla_breached"
+	IncidentUpdateDue     = "incident.update_due"
+	IncidentStale         = "incident.stale"
+	IncidentFlapping      = "incident.flapping"
+	AgentAwaitingApproval = "agent.awaiting_approval"
+	AgentCompleted        = "agent.completed"
+	AgentFailed           = "agent.failed"
+)
+
+// AllTypes lists every event type subscribers can filter on
+var AllTypes = []string{
+	IncidentCreated,
+	IncidentUpdated,
+	IncidentResolved,
+	IncidentDeleted,
+	IncidentAssigned,
+	IncidentSLABreached,
+	IncidentUpdateDue,
+	IncidentStale,
+	IncidentFlapping,
+	AgentAwaitingApproval,
+	AgentCompleted,
+	AgentFailed,
+}
+
+// Event is a single domain event
+type Event struct {
+	ID         uuid.UUID   `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Handler receives published events
+type Handler func(Event)
+
+var (
+	mu          sync.RWMutex
+	subscribers []Handler
+)
+
+// Subscribe registers a handler for every published event
+func Subscribe(handler Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	subscribers = append(subscribers, handler)
+}
+
+// New builds an event without delivering it, for callers that record it before it is dispatched
+func New(eventType string, data interface{}) Event {
+	return Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// Publish builds an event and delivers it to all subscribers
+func Publish(eventType string, data interface{}) Event {
+	event := New(eventType, data)
+	Dispatch(event)
+	return event
+}
+
+// Dispatch delivers an event to all subscribers asynchronously so callers are never blocked
+func Dispatch(event Event) {
+	mu.RLock()
+	handlers := append([]Handler{}, subscribers...)
+	mu.RUnlock()
+
+	for _, handler := range handlers {
+		go func(h Handler) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("❌ PANIC in event handler for %s: %v", event.Type, r)
+				}
+			}()
+			h(event)
+		}(handler)
+	}
+}
+
+// IsKnownType reports whether eventType is one of AllTypes
+func IsKnownType(eventType string) bool {
+	for _, t := range AllTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
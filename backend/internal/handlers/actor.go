@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestActor identifies who is performing an action.
+// The frontend only has a shared password, so we trust the display name it sends in X-User-Name.
+// Anyone who can reach the API can therefore claim any name: the two-person rule compares names
+// case- and space-insensitively, which stops one person approving twice by accident, but it is an
+// audit aid, not authentication. Chat and email links carry the recipient's name in their signature
+// instead (see services.ApprovalLinkURL).
+func requestActor(c *gin.Context) string {
+	if name := strings.TrimSpace(c.GetHeader("X-User-Name")); name != "" {
+		return name
+	}
+	return "anonymous"
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
@@ -31,8 +32,13 @@ func StartAgentRemediationHandler(c *gin.Context) {
 	// Create agent service
 	agentService := agent.NewAgentService()
 
-	// Start remediation workflow
-	execution, err := agentService.StartRemediation(&incident)
+	// Start remediation workflow (?dry_run=true simulates it without side effects)
+	dryRun := c.Query("dry_run") == "true"
+	execution, err := agentService.StartRemediation(&incident, dryRun)
+	if errors.Is(err, agent.ErrCircuitOpen) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -58,6 +64,31 @@ func GetAgentExecutionHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, execution)
 }
 
+// GetAgentActionsHandler lists the action catalog agents choose from
+func GetAgentActionsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, agent.ActionCatalog)
+}
+
+// GetAgentGuardsHandler reports the agent's circuit breaker and the targets currently leased
+func GetAgentGuardsHandler(c *gin.Context) {
+	status, err := agent.GetBreakerStatus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch agent guards"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// ResetAgentBreakerHandler closes the agent's circuit breaker so executions may run again
+func ResetAgentBreakerHandler(c *gin.Context) {
+	status, err := agent.ResetCircuitBreaker(requestActor(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
 // GetIncidentAgentExecutionsHandler retrieves all executions for an incident
 func GetIncidentAgentExecutionsHandler(c *gin.Context) {
 	incidentID, err := uuid.Parse(c.Param("id"))
@@ -75,7 +106,8 @@ func GetIncidentAgentExecutionsHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, executions)
 }
 
-// ApproveAgentExecutionHandler approves an agent execution to proceed
+// ApproveAgentExecutionHandler approves an agent execution to proceed. The approver is the self-asserted
+// X-User-Name, so where the plan needs several approvers the count is advisory, not proof of distinct people.
 func ApproveAgentExecutionHandler(c *gin.Context) {
 	executionID, err := uuid.Parse(c.Param("executionId"))
 	if err != nil {
@@ -83,24 +115,54 @@ func ApproveAgentExecutionHandler(c *gin.Context) {
 		return
 	}
 
-	var execution models.AgentExecution
-	if err := db.DB.First(&execution, "id = ?", executionID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Execution not found"})
+	// Optional body {"steps": [0, 2]} approves only those plan steps (0-based); no body approves the whole plan.
+	// "confirm_stale": true approves even though the targets changed since the preview.
+	var req struct {
+		Steps        []int `json:"steps"`
+		ConfirmStale bool  `json:"confirm_stale"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	agentService := agent.NewAgentService()
+	execution, err := agentService.ApproveExecutionSteps(executionID, requestActor(c), req.Steps, req.ConfirmStale)
+	if errors.Is(err, agent.ErrStalePlan) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "stale_plan": true})
+		return
+	}
+	if err != nil {
+		c.JSON(agentDecisionErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	// Check if execution is awaiting approval
-	if execution.Status != models.StatusAwaitingApproval {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Execution is not awaiting approval"})
+	c.JSON(http.StatusOK, execution)
+}
+
+// EditAgentPlanHandler replaces the plan of an execution awaiting approval with an approver's edit.
+// Body: {"steps": [{"action": "...", "condition": {...}, "params": {...}, "drop_commands": ["..."]}]}
+func EditAgentPlanHandler(c *gin.Context) {
+	executionID, err := uuid.Parse(c.Param("executionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid execution ID"})
 		return
 	}
 
-	log.Printf("✅ [Agent] User approved execution %s", executionID.String()[:8])
+	var req struct {
+		Steps []agent.PlanStepEdit `json:"steps"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
 
-	// Signal approval by updating status
 	agentService := agent.NewAgentService()
-	if err := agentService.ApproveExecution(&execution); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	execution, err := agentService.EditPlan(executionID, requestActor(c), req.Steps)
+	if err != nil {
+		c.JSON(agentDecisionErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -115,27 +177,96 @@ func RejectAgentExecutionHandler(c *gin.Context) {
 		return
 	}
 
-	var execution models.AgentExecution
-	if err := db.DB.First(&execution, "id = ?", executionID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Execution not found"})
+	agentService := agent.NewAgentService()
+	execution, err := agentService.RejectExecution(executionID, requestActor(c))
+	if err != nil {
+		c.JSON(agentDecisionErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	// Check if execution is awaiting approval
-	if execution.Status != models.StatusAwaitingApproval {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Execution is not awaiting approval"})
+	c.JSON(http.StatusOK, execution)
+}
+
+// CancelAgentExecutionHandler stops an execution in any non-terminal phase
+func CancelAgentExecutionHandler(c *gin.Context) {
+	executionID, err := uuid.Parse(c.Param("executionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid execution ID"})
 		return
 	}
 
-	log.Printf("❌ [Agent] User rejected execution %s", executionID.String()[:8])
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
 
-	// Cancel execution
-	execution.Status = models.StatusCancelled
-	execution.ErrorMessage = "Rejected by user"
-	if err := db.DB.Save(&execution).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel execution"})
+	agentService := agent.NewAgentService()
+	execution, err := agentService.CancelExecution(executionID, requestActor(c), req.Reason)
+	if err != nil {
+		c.JSON(agentDecisionErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, execution)
 }
+
+// ApproveAgentRollbackHandler approves the compensating commands of a failed execution
+func ApproveAgentRollbackHandler(c *gin.Context) {
+	executionID, err := uuid.Parse(c.Param("executionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid execution ID"})
+		return
+	}
+
+	agentService := agent.NewAgentService()
+	execution, err := agentService.ApproveRollback(executionID, requestActor(c))
+	if err != nil {
+		c.JSON(agentDecisionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, execution)
+}
+
+// DeclineAgentRollbackHandler leaves a failed execution's changes in place
+func DeclineAgentRollbackHandler(c *gin.Context) {
+	executionID, err := uuid.Parse(c.Param("executionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid execution ID"})
+		return
+	}
+
+	agentService := agent.NewAgentService()
+	execution, err := agentService.DeclineRollback(executionID, requestActor(c))
+	if err != nil {
+		c.JSON(agentDecisionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, execution)
+}
+
+// agentDecisionErrorStatus maps approve/reject/cancel/rollback errors to HTTP status codes
+func agentDecisionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, agent.ErrExecutionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, agent.ErrNotAwaitingApproval), errors.Is(err, agent.ErrNotAwaitingRollback), errors.Is(err, agent.ErrCancelReasonRequired),
+		errors.Is(err, agent.ErrInvalidStepSelection), errors.Is(err, agent.ErrApproverRequired), errors.Is(err, agent.ErrInvalidPlanEdit):
+		return http.StatusBadRequest
+	case errors.Is(err, agent.ErrNotCancellable), errors.Is(err, agent.ErrAlreadyApproved),
+		errors.Is(err, agent.ErrApprovalExpired), errors.Is(err, agent.ErrStalePlan):
+		return http.StatusConflict
+	case errors.Is(err, agent.ErrNotActionable), errors.Is(err, agent.ErrTargetLocked):
+		return http.StatusConflict
+	case errors.Is(err, agent.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, agent.ErrCircuitOpen):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
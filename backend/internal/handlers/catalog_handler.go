@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+// GetAllServicesHandler lists every service in the catalog
+func GetAllServicesHandler(c *gin.Context) {
+	catalog, err := services.GetAllServices()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch services"})
+		return
+	}
+	c.JSON(http.StatusOK, catalog)
+}
+
+// GetServiceHandler returns a single catalog entry
+func GetServiceHandler(c *gin.Context) {
+	service, err := services.GetServiceByName(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+	c.JSON(http.StatusOK, service)
+}
+
+// CreateServiceHandler adds a service to the catalog
+func CreateServiceHandler(c *gin.Context) {
+	var service models.Service
+	if err := c.ShouldBindJSON(&service); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.CreateService(&service); err != nil {
+		if errors.Is(err, services.ErrServiceExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Service already exists"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, service)
+}
+
+// UpdateServiceHandler replaces an existing catalog entry
+func UpdateServiceHandler(c *gin.Context) {
+	existing, err := services.GetServiceByName(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+
+	var service models.Service
+	if err := c.ShouldBindJSON(&service); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	service.Name = existing.Name
+	service.CreatedAt = existing.CreatedAt
+
+	if err := services.SaveService(&service); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, service)
+}
+
+// DeleteServiceHandler removes a service from the catalog
+func DeleteServiceHandler(c *gin.Context) {
+	if err := services.DeleteService(c.Param("name")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Service deleted successfully"})
+}
+
+// GetServiceGraphHandler exports the dependency graph as JSON or Graphviz DOT (?format=dot)
+func GetServiceGraphHandler(c *gin.Context) {
+	graph, err := services.GetServiceGraph()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build service graph"})
+		return
+	}
+
+	if strings.EqualFold(c.Query("format"), "dot") {
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(services.RenderServiceGraphDOT(graph)))
+		return
+	}
+
+	c.JSON(http.StatusOK, graph)
+}
+
+// GetServiceBlastRadiusHandler returns every service that transitively depends on the given one
+func GetServiceBlastRadiusHandler(c *gin.Context) {
+	name := c.Param("name")
+	if _, err := services.GetServiceByName(name); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+
+	radius, err := services.ComputeBlastRadius([]string{name})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute blast radius"})
+		return
+	}
+	c.JSON(http.StatusOK, radius)
+}
+
+// GetIncidentBlastRadiusHandler suggests downstream services likely impacted by an incident
+func GetIncidentBlastRadiusHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID format"})
+		return
+	}
+
+	incident, err := services.GetIncidentByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Incident not found"})
+		return
+	}
+
+	radius, err := services.ComputeIncidentBlastRadius(&incident)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute blast radius"})
+		return
+	}
+	c.JSON(http.StatusOK, radius)
+}
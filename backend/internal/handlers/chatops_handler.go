@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/agent"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+// Links from chat and email open in a browser, so these handlers render small HTML pages instead of JSON.
+// GET only shows a confirmation form - chat clients and link unfurlers prefetch URLs.
+//
+// Trust model: each link is signed for one approver and sent only to them, and the decision is recorded
+// under that signed name. The page never asks who is clicking, so a link cannot be reused under a second
+// name to satisfy the two-person rule. Holding the link is the proof of identity, as with a password-reset email.
+// Links posted to the shared channel name no one; they open the dashboard, which confirms the decision
+// through ChatOpsSharedDecisionHandler under the dashboard's user, exactly like a dashboard approval.
+var chatOpsPage = template.Must(template.New("chatops").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, sans-serif; max-width: 560px; margin: 48px auto; padding: 0 16px; color: #1f2937; }
+.box { border: 1px solid #e5e7eb; border-radius: 8px; padding: 20px; }
+code { background: #f3f4f6; padding: 1px 4px; border-radius: 4px; }
+button { padding: 10px 18px; border: 0; border-radius: 6px; color: white; font-size: 15px; cursor: pointer; }
+.approve { background: #16a34a; } .reject { background: #dc2626; }
+</style>
+</head>
+<body>
+<div class="box">
+<h2>{{.Title}}</h2>
+<p>{{.Message}}</p>
+{{if .Execution}}
+<p><strong>Action:</strong> <code>{{.Execution.RecommendedAction}}</code></p>
+<p><strong>Reasoning:</strong> {{.Execution.Reasoning}}</p>
+{{end}}
+{{if .ShowForm}}
+<form method="POST">
+<p>You are deciding as <strong>{{.Approver}}</strong>. This link was sent only to you; do not forward it.</p>
+<button type="submit" class="{{.Action}}">Confirm {{.Action}}</button>
+</form>
+{{end}}
+</div>
+</body>
+</html>`))
+
+type chatOpsPageData struct {
+	Title     string
+	Message   string
+	Action    string
+	Approver  string
+	Execution *models.AgentExecution
+	ShowForm  bool
+}
+
+// chatOpsLink is a verified approve/reject link
+type chatOpsLink struct {
+	ExecutionID uuid.UUID
+	Action      string
+	Approver    string // Signed into the link, so it is who the link was sent to
+}
+
+// verifyChatOpsLink parses and verifies the signed link, rendering an error page on failure
+func verifyChatOpsLink(c *gin.Context) (chatOpsLink, bool) {
+	link := chatOpsLink{Action: c.Param("action"), Approver: c.Query("approver")}
+	executionID, err := uuid.Parse(c.Param("executionId"))
+	if err == nil {
+		err = services.VerifyApprovalLink(executionID, link.Action, link.Approver, c.Query("expires"), c.Query("sig"))
+	}
+	if err != nil {
+		status := http.StatusForbidden
+		message := "This link is not valid."
+		if errors.Is(err, services.ErrApprovalLinkExpired) {
+			status = http.StatusGone
+			message = "This link has expired. Approve or reject the execution from the dashboard instead."
+		}
+		renderChatOpsPage(c, status, chatOpsPageData{Title: "Link not valid", Message: message})
+		return chatOpsLink{}, false
+	}
+	link.ExecutionID = executionID
+	return link, true
+}
+
+func renderChatOpsPage(c *gin.Context, status int, data chatOpsPageData) {
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	if err := chatOpsPage.Execute(c.Writer, data); err != nil {
+		log.Printf("❌ Failed to render chat-ops page: %v", err)
+	}
+}
+
+// ChatOpsConfirmHandler shows a confirmation page for a signed approve/reject link
+func ChatOpsConfirmHandler(c *gin.Context) {
+	link, ok := verifyChatOpsLink(c)
+	if !ok {
+		return
+	}
+
+	var execution models.AgentExecution
+	if err := db.DB.First(&execution, "id = ?", link.ExecutionID).Error; err != nil {
+		renderChatOpsPage(c, http.StatusNotFound, chatOpsPageData{Title: "Execution not found", Message: "This execution no longer exists."})
+		return
+	}
+
+	if execution.Status != models.StatusAwaitingApproval {
+		renderChatOpsPage(c, http.StatusOK, chatOpsPageData{
+			Title:     "Already decided",
+			Message:   "This execution is " + string(execution.Status) + ".",
+			Execution: &execution,
+		})
+		return
+	}
+
+	renderChatOpsPage(c, http.StatusOK, chatOpsPageData{
+		Title:     strings.ToUpper(link.Action[:1]) + link.Action[1:] + " agent action?",
+		Message:   "The agent is waiting for a decision on this remediation.",
+		Action:    link.Action,
+		Approver:  link.Approver,
+		Execution: &execution,
+		ShowForm:  true,
+	})
+}
+
+// ChatOpsDecisionHandler approves or rejects an execution from a signed chat link, as the approver the
+// link was signed for. It goes through the same agent service checks as the dashboard endpoints.
+func ChatOpsDecisionHandler(c *gin.Context) {
+	link, ok := verifyChatOpsLink(c)
+	if !ok {
+		return
+	}
+	executionID, action := link.ExecutionID, link.Action
+	actor := strings.TrimSpace(link.Approver) + agent.ChatActorSuffix
+
+	agentService := agent.NewAgentService()
+	var execution *models.AgentExecution
+	var err error
+	if action == services.ChatActionApprove {
+		execution, err = agentService.ApproveExecution(executionID, actor)
+	} else {
+		execution, err = agentService.RejectExecution(executionID, actor)
+	}
+	if errors.Is(err, agent.ErrStalePlan) {
+		renderChatOpsPage(c, http.StatusConflict, chatOpsPageData{Title: "Plan may be out of date", Message: err.Error() + ". Review it in the dashboard to approve anyway."})
+		return
+	}
+	if err != nil {
+		renderChatOpsPage(c, agentDecisionErrorStatus(err), chatOpsPageData{Title: "Could not " + action, Message: err.Error()})
+		return
+	}
+
+	if execution.Status == models.StatusAwaitingApproval {
+		renderChatOpsPage(c, http.StatusOK, chatOpsPageData{
+			Title:     "Approval recorded",
+			Message:   "Recorded as " + actor + ". The approval policy needs another approver before this runs.",
+			Execution: execution,
+		})
+		return
+	}
+
+	renderChatOpsPage(c, http.StatusOK, chatOpsPageData{
+		Title:     "Execution " + action + "d",
+		Message:   "Recorded as " + actor + ".",
+		Execution: execution,
+	})
+}
+
+// ChatOpsSharedDecisionHandler approves or rejects an execution from a shared-channel link, opened in the
+// dashboard. The link only proves the decision was offered in the channel; who decides comes from the
+// dashboard session (X-User-Name), as for the dashboard's own approve and reject buttons.
+// Query: ?expires=...&sig=... as signed into the link.
+func ChatOpsSharedDecisionHandler(c *gin.Context) {
+	executionID, err := uuid.Parse(c.Param("executionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid execution ID"})
+		return
+	}
+	action := c.Param("action")
+	if err := services.VerifySharedApprovalLink(executionID, action, c.Query("expires"), c.Query("sig")); err != nil {
+		if errors.Is(err, services.ErrApprovalLinkExpired) {
+			c.JSON(http.StatusGone, gin.H{"error": "This link has expired. Approve or reject the execution from its incident instead."})
+			return
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "This link is not valid."})
+		return
+	}
+
+	actor := requestActor(c)
+	if actor == "anonymous" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Open this link in the dashboard so the decision is recorded under your name"})
+		return
+	}
+	actor += agent.ChatActorSuffix
+
+	agentService := agent.NewAgentService()
+	var execution *models.AgentExecution
+	if action == services.ChatActionApprove {
+		execution, err = agentService.ApproveExecution(executionID, actor)
+	} else {
+		execution, err = agentService.RejectExecution(executionID, actor)
+	}
+	if errors.Is(err, agent.ErrStalePlan) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error() + ". Review it in the incident to approve anyway.", "stale_plan": true})
+		return
+	}
+	if err != nil {
+		c.JSON(agentDecisionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, execution)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+// GetFlapStatesHandler lists alert fingerprints tracked by flap detection (?flapping=true, ?source=)
+func GetFlapStatesHandler(c *gin.Context) {
+	states, err := services.GetFlapStates(c.Query("flapping") == "true", c.Query("source"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flapping state"})
+		return
+	}
+	c.JSON(http.StatusOK, states)
+}
+
+// GetFlapDetailHandler returns a fingerprint's flapping state and transition history
+func GetFlapDetailHandler(c *gin.Context) {
+	detail, err := services.GetFlapDetail(c.Param("fingerprint"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flapping history"})
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// ClearFlapStateHandler manually ends flapping for a fingerprint
+func ClearFlapStateHandler(c *gin.Context) {
+	state, err := services.ClearFlapping(c.Param("fingerprint"), requestActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// GetIncidentFlapDetailHandler returns flapping state for an incident's alert
+func GetIncidentFlapDetailHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID format"})
+		return
+	}
+
+	detail, err := services.GetIncidentFlapDetail(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -94,20 +95,35 @@ func CreateIncidentHandler(c *gin.Context) {
 	log.Printf("📥 Creating incident: source=%s, type=%s, actionable=%v, systems=%v",
 		incident.Source, incident.IncidentType, incident.Actionable, incident.AffectedSystems)
 
+	// Alerts for systems inside an active maintenance window are held back or annotated
+	if window := services.FindActiveMaintenanceWindow(incident.AffectedSystems); window != nil {
+		if window.Mode == services.MaintenanceModeSuppress {
+			alert, err := services.SuppressAlert(window, &incident)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record suppressed alert"})
+				return
+			}
+			c.JSON(http.StatusAccepted, gin.H{
+				"suppressed":            true,
+				"maintenance_window_id": window.ID,
+				"suppressed_alert":      alert,
+			})
+			return
+		}
+		services.AnnotateDuringMaintenance(window, &incident)
+		log.Printf("🛠️  Incident raised during maintenance window %s", window.ID.String()[:8])
+	}
+
 	if err := services.CreateIncident(&incident); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create incident"})
 		return
 	}
 
-	// Start the AI analysis pipeline in the background.
-	// This pipeline is now responsible for all WebSocket broadcasts.
-	go services.RunFullAnalysisPipeline(incident)
-
 	c.JSON(http.StatusCreated, incident)
 }
 
 func GetAllIncidentsHandler(c *gin.Context) {
-	incidents, err := services.GetAllIncidents()
+	incidents, err := services.GetAllIncidents(c.Query("include_snoozed") == "true")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch incidents"})
 		return
@@ -153,6 +169,7 @@ func UpdateIncidentHandler(c *gin.Context) {
 		Notes    *string `json:"notes"`
 		Severity *string `json:"severity" binding:"omitempty,oneof=high medium low"`
 		Team     *string `json:"team"`
+		Assignee *string `json:"assignee"`
 	}
 	if err := c.ShouldBindJSON(&updateData); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -196,6 +213,15 @@ func UpdateIncidentHandler(c *gin.Context) {
 		}
 	}
 
+	// Update assignee if provided
+	if updateData.Assignee != nil {
+		incident, err = services.UpdateIncidentAssignee(id, strings.TrimSpace(*updateData.Assignee), requestActor(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update incident assignee"})
+			return
+		}
+	}
+
 	// If no update was performed, return the current incident
 	if incident == nil {
 		incidentValue, err := services.GetIncidentByID(id)
@@ -217,7 +243,7 @@ func TriggerAIDiagnosisHandler(c *gin.Context) {
 		return
 	}
 
-	analysis, err := services.TriggerAIDiagnosis(id)
+	analysis, err := services.TriggerAIDiagnosis(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -237,7 +263,7 @@ func TriggerAISuggestedFixHandler(c *gin.Context) {
 		return
 	}
 
-	analysis, err := services.TriggerAISuggestedFix(id)
+	analysis, err := services.TriggerAISuggestedFix(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -250,7 +276,7 @@ func TriggerAISuggestedFixHandler(c *gin.Context) {
 }
 
 func GenerateRandomIncidentHandler(c *gin.Context) {
-	incident, err := services.GenerateRandomIncident()
+	incident, err := services.GenerateRandomIncident(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to generate incident: %v", err)})
 		return
@@ -261,12 +287,25 @@ func GenerateRandomIncidentHandler(c *gin.Context) {
 		return
 	}
 
-	// Start the AI analysis pipeline in the background
-	go services.RunFullAnalysisPipeline(incident)
-
 	c.JSON(http.StatusCreated, incident)
 }
 
+// GetIncidentTimelineHandler returns the merged incident timeline
+func GetIncidentTimelineHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID format"})
+		return
+	}
+
+	timeline, err := services.GetIncidentTimeline(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timeline"})
+		return
+	}
+	c.JSON(http.StatusOK, timeline)
+}
+
 func DeleteIncidentHandler(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
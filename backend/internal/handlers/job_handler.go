@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+// GetJobsHandler lists background jobs, newest first (?status=, ?type=, ?limit=)
+func GetJobsHandler(c *gin.Context) {
+	limit := 100
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+
+	jobs, err := services.GetJobs(services.JobFilter{
+		Status: c.Query("status"),
+		Type:   c.Query("type"),
+		Limit:  limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetJobStatsHandler returns job counts per type and status, plus the registered job types
+func GetJobStatsHandler(c *gin.Context) {
+	stats, err := services.GetJobStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job stats"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"counts": stats,
+		"types":  services.RegisteredJobTypes(),
+	})
+}
+
+// GetJobHandler returns a single job
+func GetJobHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
+		return
+	}
+
+	job, err := services.GetJobByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// RetryJobHandler requeues a dead or cancelled job
+func RetryJobHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
+		return
+	}
+
+	job, err := services.RetryJob(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// CancelJobHandler cancels a queued or running job
+func CancelJobHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
+		return
+	}
+
+	job, err := services.CancelJob(id, requestActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
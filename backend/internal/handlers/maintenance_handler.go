@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+// GetMaintenanceWindowsHandler lists all maintenance windows with their current active state
+func GetMaintenanceWindowsHandler(c *gin.Context) {
+	windows, err := services.GetMaintenanceWindows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch maintenance windows"})
+		return
+	}
+	c.JSON(http.StatusOK, windows)
+}
+
+// GetMaintenanceWindowHandler returns a single maintenance window
+func GetMaintenanceWindowHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("windowId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maintenance window ID"})
+		return
+	}
+
+	window, err := services.GetMaintenanceWindowByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Maintenance window not found"})
+		return
+	}
+	c.JSON(http.StatusOK, window)
+}
+
+// CreateMaintenanceWindowHandler schedules a one-off or recurring maintenance window
+func CreateMaintenanceWindowHandler(c *gin.Context) {
+	var window models.MaintenanceWindow
+	if err := c.ShouldBindJSON(&window); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	window.ID = uuid.Nil
+	window.CreatedBy = requestActor(c)
+
+	if err := services.CreateMaintenanceWindow(&window); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, window)
+}
+
+// UpdateMaintenanceWindowHandler replaces a maintenance window definition
+func UpdateMaintenanceWindowHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("windowId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maintenance window ID"})
+		return
+	}
+
+	existing, err := services.GetMaintenanceWindowByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Maintenance window not found"})
+		return
+	}
+
+	var window models.MaintenanceWindow
+	if err := c.ShouldBindJSON(&window); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	window.ID = existing.ID
+	window.CreatedBy = existing.CreatedBy
+	window.CreatedAt = existing.CreatedAt
+
+	if err := services.UpdateMaintenanceWindow(&window); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, window)
+}
+
+// DeleteMaintenanceWindowHandler cancels a maintenance window
+func DeleteMaintenanceWindowHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("windowId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maintenance window ID"})
+		return
+	}
+
+	if err := services.DeleteMaintenanceWindow(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Maintenance window deleted successfully"})
+}
+
+// GetSuppressedAlertsHandler lists alerts swallowed by maintenance windows.
+// Use ?unreviewed=true to see only alerts still awaiting review.
+func GetSuppressedAlertsHandler(c *gin.Context) {
+	filter := services.SuppressedAlertFilter{Unreviewed: c.Query("unreviewed") == "true"}
+
+	if idStr := c.Param("windowId"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maintenance window ID"})
+			return
+		}
+		filter.WindowID = &id
+	}
+
+	alerts, err := services.GetSuppressedAlerts(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch suppressed alerts"})
+		return
+	}
+	c.JSON(http.StatusOK, alerts)
+}
+
+// ReviewSuppressedAlertHandler dismisses a suppressed alert or promotes it to an incident
+func ReviewSuppressedAlertHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("alertId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid suppressed alert ID"})
+		return
+	}
+
+	var req struct {
+		Action string `json:"action" binding:"required,oneof=dismiss promote"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alert, incident, err := services.ReviewSuppressedAlert(id, req.Action == "promote", requestActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suppressed_alert": alert, "incident": incident})
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+// GetNotificationPreferencesHandler lists every user's notification preferences
+func GetNotificationPreferencesHandler(c *gin.Context) {
+	prefs, err := services.GetNotificationPreferences()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"preferences":       prefs,
+		"email_event_types": services.EmailEventTypes,
+		"channels":          services.NotificationChannels,
+		"email_enabled":     services.EmailEnabled(),
+	})
+}
+
+// GetNotificationPreferenceHandler returns one user's notification preferences
+func GetNotificationPreferenceHandler(c *gin.Context) {
+	pref, err := services.GetNotificationPreference(c.Param("user"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification preference not found"})
+		return
+	}
+	c.JSON(http.StatusOK, pref)
+}
+
+// SaveNotificationPreferenceHandler creates or replaces a user's contact details and notification settings
+func SaveNotificationPreferenceHandler(c *gin.Context) {
+	var req struct {
+		Email           string   `json:"email"`
+		EventTypes      []string `json:"event_types"`
+		DeliveryMode    string   `json:"delivery_mode"`
+		Enabled         *bool    `json:"enabled"`
+		ChatWebhookURL  string   `json:"chat_webhook_url"`
+		QuietHoursStart string   `json:"quiet_hours_start"`
+		QuietHoursEnd   string   `json:"quiet_hours_end"`
+		Timezone        string   `json:"timezone"`
+		ThrottleMinutes *int     `json:"throttle_minutes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pref := models.NotificationPreference{
+		UserName:        c.Param("user"),
+		Email:           req.Email,
+		EventTypes:      pq.StringArray(req.EventTypes),
+		DeliveryMode:    req.DeliveryMode,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+		ChatWebhookURL:  req.ChatWebhookURL,
+		QuietHoursStart: req.QuietHoursStart,
+		QuietHoursEnd:   req.QuietHoursEnd,
+		Timezone:        req.Timezone,
+		ThrottleMinutes: 10,
+	}
+	if req.ThrottleMinutes != nil {
+		pref.ThrottleMinutes = *req.ThrottleMinutes
+	}
+	if err := services.SaveNotificationPreference(&pref); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, pref)
+}
+
+// DeleteNotificationPreferenceHandler removes a user's notification preferences
+func DeleteNotificationPreferenceHandler(c *gin.Context) {
+	if err := services.DeleteNotificationPreference(c.Param("user")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification preference deleted successfully"})
+}
+
+// GetEmailOutboxHandler lists recent outgoing emails (?status=pending|sent|failed, ?limit=)
+func GetEmailOutboxHandler(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	messages, err := services.GetEmailOutbox(c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch email outbox"})
+		return
+	}
+	c.JSON(http.StatusOK, messages)
+}
+
+type notificationRuleRequest struct {
+	UserName         string   `json:"user_name"`
+	Name             string   `json:"name"`
+	Channels         []string `json:"channels"`
+	EventTypes       []string `json:"event_types"`
+	Severities       []string `json:"severities"`
+	Teams            []string `json:"teams"`
+	BypassQuietHours bool     `json:"bypass_quiet_hours"`
+	Enabled          *bool    `json:"enabled"`
+}
+
+func (req notificationRuleRequest) apply(rule *models.NotificationRule) {
+	rule.Name = req.Name
+	rule.Channels = pq.StringArray(req.Channels)
+	rule.EventTypes = pq.StringArray(req.EventTypes)
+	rule.Severities = pq.StringArray(req.Severities)
+	rule.Teams = pq.StringArray(req.Teams)
+	rule.BypassQuietHours = req.BypassQuietHours
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+}
+
+// GetNotificationRulesHandler lists notification rules (?user= to filter)
+func GetNotificationRulesHandler(c *gin.Context) {
+	rules, err := services.GetNotificationRules(c.Query("user"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification rules"})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateNotificationRuleHandler adds a routing rule; user_name defaults to the caller
+func CreateNotificationRuleHandler(c *gin.Context) {
+	var req notificationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.UserName == "" {
+		req.UserName = requestActor(c)
+	}
+
+	rule := models.NotificationRule{UserName: req.UserName}
+	req.apply(&rule)
+	if err := services.CreateNotificationRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateNotificationRuleHandler replaces a rule's filters and channels
+func UpdateNotificationRuleHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("ruleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	rule, err := services.GetNotificationRuleByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification rule not found"})
+		return
+	}
+
+	var req notificationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.apply(&rule)
+	if err := services.UpdateNotificationRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteNotificationRuleHandler removes a rule
+func DeleteNotificationRuleHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("ruleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	if err := services.DeleteNotificationRule(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification rule deleted successfully"})
+}
+
+// GetNotificationAttemptsHandler answers "was I notified?" (?user=, ?incident_id=, ?event_type=, ?channel=, ?limit=)
+func GetNotificationAttemptsHandler(c *gin.Context) {
+	filter := services.NotificationAttemptFilter{
+		UserName:  c.Query("user"),
+		EventType: c.Query("event_type"),
+		Channel:   c.Query("channel"),
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+		return
+	}
+	filter.Limit = limit
+
+	if raw := c.Query("incident_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID"})
+			return
+		}
+		filter.IncidentID = &id
+	}
+
+	attempts, err := services.GetNotificationAttempts(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification attempts"})
+		return
+	}
+	c.JSON(http.StatusOK, attempts)
+}
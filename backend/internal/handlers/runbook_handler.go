@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+// GetRunbooksHandler lists the latest version of each runbook, optionally filtered
+func GetRunbooksHandler(c *gin.Context) {
+	runbooks, err := services.GetLatestRunbooks(services.RunbookFilter{
+		Service: c.Query("service"),
+		Source:  c.Query("source"),
+		Action:  c.Query("action"),
+		Team:    c.Query("team"),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch runbooks"})
+		return
+	}
+	c.JSON(http.StatusOK, runbooks)
+}
+
+// CreateRunbookHandler stores a new runbook, or a new version if the slug already exists
+func CreateRunbookHandler(c *gin.Context) {
+	var runbook models.Runbook
+	if err := c.ShouldBindJSON(&runbook); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	runbook.CreatedBy = requestActor(c)
+
+	if err := services.CreateRunbookVersion(&runbook); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, runbook)
+}
+
+// GetRunbookHandler returns the latest version of a runbook, or the one named by :version
+func GetRunbookHandler(c *gin.Context) {
+	version := 0
+	if v := c.Param("version"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid runbook version"})
+			return
+		}
+		version = parsed
+	}
+
+	runbook, err := services.GetRunbook(c.Param("slug"), version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Runbook not found"})
+		return
+	}
+	c.JSON(http.StatusOK, runbook)
+}
+
+// GetRunbookVersionsHandler returns the version history of a runbook
+func GetRunbookVersionsHandler(c *gin.Context) {
+	runbooks, err := services.GetRunbookVersions(c.Param("slug"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch runbook versions"})
+		return
+	}
+	if len(runbooks) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Runbook not found"})
+		return
+	}
+	c.JSON(http.StatusOK, runbooks)
+}
+
+// SuggestRunbooksHandler ranks runbooks relevant to an incident
+func SuggestRunbooksHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID format"})
+		return
+	}
+
+	incident, err := services.GetIncidentByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Incident not found"})
+		return
+	}
+
+	suggestions, err := services.SuggestRunbooks(&incident)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suggest runbooks"})
+		return
+	}
+	if suggestions == nil {
+		suggestions = []models.RunbookSuggestion{}
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// GetIncidentChecklistsHandler lists runbook checklists started on an incident
+func GetIncidentChecklistsHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID format"})
+		return
+	}
+
+	checklists, err := services.GetIncidentChecklists(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch checklists"})
+		return
+	}
+	c.JSON(http.StatusOK, checklists)
+}
+
+// StartIncidentChecklistHandler instantiates a runbook as a checklist on an incident
+func StartIncidentChecklistHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID format"})
+		return
+	}
+
+	var req struct {
+		RunbookSlug string `json:"runbook_slug" binding:"required"`
+		Version     int    `json:"version"` // 0 = latest
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	checklist, err := services.StartIncidentChecklist(id, req.RunbookSlug, req.Version, requestActor(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, checklist)
+}
+
+// UpdateChecklistStepHandler ticks or unticks a single checklist step
+func UpdateChecklistStepHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID format"})
+		return
+	}
+	checklistID, err := uuid.Parse(c.Param("checklistId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid checklist ID format"})
+		return
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid step index"})
+		return
+	}
+
+	var req struct {
+		Completed *bool `json:"completed" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	checklist, err := services.SetChecklistStep(id, checklistID, index, *req.Completed, requestActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, checklist)
+}
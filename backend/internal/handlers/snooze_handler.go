@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+// SnoozeIncidentHandler hides an incident from the board until a timestamp
+func SnoozeIncidentHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID format"})
+		return
+	}
+
+	var req struct {
+		Until  time.Time `json:"until" binding:"required"`
+		Reason string    `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	incident, err := services.SnoozeIncident(id, req.Until, req.Reason, requestActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, incident)
+}
+
+// UnsnoozeIncidentHandler returns a snoozed incident to the board early
+func UnsnoozeIncidentHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID format"})
+		return
+	}
+
+	incident, err := services.UnsnoozeIncident(id, requestActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, incident)
+}
+
+// GetStaleThresholdsHandler lists per-team stale thresholds and the default
+func GetStaleThresholdsHandler(c *gin.Context) {
+	thresholds, err := services.GetStaleThresholds()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stale thresholds"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"default_threshold_hours": services.DefaultStaleThresholdHours(),
+		"thresholds":              thresholds,
+	})
+}
+
+// SaveStaleThresholdHandler sets how long a team's incidents may sit in one status
+func SaveStaleThresholdHandler(c *gin.Context) {
+	var req struct {
+		ThresholdHours int   `json:"threshold_hours" binding:"required"`
+		Enabled        *bool `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	threshold := models.StaleThreshold{
+		Team:           c.Param("team"),
+		ThresholdHours: req.ThresholdHours,
+		Enabled:        req.Enabled == nil || *req.Enabled,
+	}
+	if err := services.SaveStaleThreshold(&threshold); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, threshold)
+}
+
+// DeleteStaleThresholdHandler returns a team to the default threshold
+func DeleteStaleThresholdHandler(c *gin.Context) {
+	if err := services.DeleteStaleThreshold(c.Param("team")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Stale threshold deleted successfully"})
+}
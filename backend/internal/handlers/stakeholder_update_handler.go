@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+// GetStakeholderUpdatesHandler lists an incident's stakeholder updates and when the next one is due
+func GetStakeholderUpdatesHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID format"})
+		return
+	}
+
+	status, err := services.GetStakeholderUpdateStatus(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Incident not found"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// PostStakeholderUpdateHandler records a stakeholder update and resets the cadence clock
+func PostStakeholderUpdateHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID format"})
+		return
+	}
+
+	var req struct {
+		Message   string `json:"message" binding:"required"`
+		AIDrafted bool   `json:"ai_drafted"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	update, err := services.PostStakeholderUpdate(id, req.Message, requestActor(c), req.AIDrafted)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, update)
+}
+
+// DraftStakeholderUpdateHandler returns AI-drafted update text for review; nothing is posted
+func DraftStakeholderUpdateHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID format"})
+		return
+	}
+
+	draft, err := services.DraftStakeholderUpdate(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, draft)
+}
+
+// GetUpdateCadencesHandler lists the stakeholder update cadence per severity
+func GetUpdateCadencesHandler(c *gin.Context) {
+	cadences, err := services.GetUpdateCadences()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch update cadences"})
+		return
+	}
+	c.JSON(http.StatusOK, cadences)
+}
+
+// SaveUpdateCadenceHandler sets how often a severity needs stakeholder updates
+func SaveUpdateCadenceHandler(c *gin.Context) {
+	var req struct {
+		IntervalMinutes int   `json:"interval_minutes" binding:"required"`
+		Enabled         *bool `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cadence := models.UpdateCadence{
+		Severity:        c.Param("severity"),
+		IntervalMinutes: req.IntervalMinutes,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+	}
+	if err := services.SaveUpdateCadence(&cadence); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cadence)
+}
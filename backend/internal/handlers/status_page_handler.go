@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+// setPublicCacheHeaders lets browsers and CDNs cache public status responses briefly
+func setPublicCacheHeaders(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=30")
+}
+
+// GetPublicStatusHandler serves the read-only public status page as JSON
+func GetPublicStatusHandler(c *gin.Context) {
+	status, err := services.GetPublicStatus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load status"})
+		return
+	}
+	setPublicCacheHeaders(c)
+	c.JSON(http.StatusOK, status)
+}
+
+// GetPublicStatusPostHandler serves a single public post
+func GetPublicStatusPostHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("postId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	post, err := services.GetPublicPost(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+	setPublicCacheHeaders(c)
+	c.JSON(http.StatusOK, post)
+}
+
+// GetStatusAtomFeedHandler serves recent posts as an Atom feed
+func GetStatusAtomFeedHandler(c *gin.Context) {
+	posts, err := services.GetPublicPostsForFeed(50)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load feed")
+		return
+	}
+	body, err := services.RenderStatusAtom(posts)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to render feed")
+		return
+	}
+	setPublicCacheHeaders(c)
+	c.Data(http.StatusOK, "application/atom+xml; charset=utf-8", body)
+}
+
+// GetStatusRSSFeedHandler serves recent posts as an RSS 2.0 feed
+func GetStatusRSSFeedHandler(c *gin.Context) {
+	posts, err := services.GetPublicPostsForFeed(50)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load feed")
+		return
+	}
+	body, err := services.RenderStatusRSS(posts)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to render feed")
+		return
+	}
+	setPublicCacheHeaders(c)
+	c.Data(http.StatusOK, "application/rss+xml; charset=utf-8", body)
+}
+
+// GetStatusComponentsHandler lists components with derived and effective status, including override details
+func GetStatusComponentsHandler(c *gin.Context) {
+	components, err := services.GetStatusComponents()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch status components"})
+		return
+	}
+	c.JSON(http.StatusOK, components)
+}
+
+type statusComponentRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	Description  string   `json:"description"`
+	Services     []string `json:"services"`
+	DisplayOrder int      `json:"display_order"`
+}
+
+// CreateStatusComponentHandler adds a component to the status page
+func CreateStatusComponentHandler(c *gin.Context) {
+	var req statusComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	component := models.StatusComponent{
+		Name:         req.Name,
+		Description:  req.Description,
+		Services:     pq.StringArray(req.Services),
+		DisplayOrder: req.DisplayOrder,
+	}
+	if err := services.CreateStatusComponent(&component); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := services.GetStatusComponentByID(component.ID)
+	if err != nil {
+		c.JSON(http.StatusCreated, component)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateStatusComponentHandler replaces a component's name, description, services and order
+func UpdateStatusComponentHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("componentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid component ID"})
+		return
+	}
+
+	component, err := services.GetStatusComponentByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Status component not found"})
+		return
+	}
+
+	var req statusComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	component.Name = req.Name
+	component.Description = req.Description
+	component.Services = pq.StringArray(req.Services)
+	component.DisplayOrder = req.DisplayOrder
+
+	if err := services.UpdateStatusComponent(&component); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := services.GetStatusComponentByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload status component"})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteStatusComponentHandler removes a component
+func DeleteStatusComponentHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("componentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid component ID"})
+		return
+	}
+
+	if err := services.DeleteStatusComponent(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Status component deleted successfully"})
+}
+
+// SetStatusComponentOverrideHandler manually pins a component's status
+func SetStatusComponentOverrideHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("componentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid component ID"})
+		return
+	}
+
+	var req struct {
+		Status string     `json:"status" binding:"required"`
+		Reason string     `json:"reason"`
+		Until  *time.Time `json:"until"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	component, err := services.SetComponentOverride(id, req.Status, req.Reason, req.Until, requestActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, component)
+}
+
+// ClearStatusComponentOverrideHandler returns a component to its derived status
+func ClearStatusComponentOverrideHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("componentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid component ID"})
+		return
+	}
+
+	component, err := services.ClearComponentOverride(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, component)
+}
+
+// GetStatusPostsHandler lists posts for the internal editor (?days= of resolved history, default 30)
+func GetStatusPostsHandler(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 0 || days > 365 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 0 and 365"})
+		return
+	}
+
+	posts, err := services.GetStatusPosts(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch status posts"})
+		return
+	}
+	c.JSON(http.StatusOK, posts)
+}
+
+// GetStatusPostHandler returns a post with its internal fields
+func GetStatusPostHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("postId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	post, err := services.GetStatusPost(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+	c.JSON(http.StatusOK, post)
+}
+
+// CreateStatusPostHandler publishes a new public post with its first update
+func CreateStatusPostHandler(c *gin.Context) {
+	var req struct {
+		Title        string     `json:"title" binding:"required"`
+		Status       string     `json:"status"`
+		Message      string     `json:"message" binding:"required"`
+		ComponentIDs []string   `json:"component_ids"`
+		IncidentID   *uuid.UUID `json:"incident_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	post := models.StatusPost{
+		Title:        req.Title,
+		Status:       req.Status,
+		ComponentIDs: pq.StringArray(req.ComponentIDs),
+		IncidentID:   req.IncidentID,
+		CreatedBy:    requestActor(c),
+	}
+	if err := services.CreateStatusPost(&post, req.Message); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, post)
+}
+
+// AddStatusPostUpdateHandler appends a public update to a post
+func AddStatusPostUpdateHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("postId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	var req struct {
+		Status  string `json:"status"`
+		Message string `json:"message" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	post, err := services.AddStatusPostUpdate(id, req.Status, req.Message, requestActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, post)
+}
+
+// DeleteStatusPostHandler removes a post and its updates
+func DeleteStatusPostHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("postId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	if err := services.DeleteStatusPost(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Status post deleted successfully"})
+}
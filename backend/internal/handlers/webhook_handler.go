@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
+)
+
+// GetWebhookEventTypesHandler lists the event types subscriptions can filter on
+func GetWebhookEventTypesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, events.AllTypes)
+}
+
+// GetWebhooksHandler lists all webhook subscriptions
+func GetWebhooksHandler(c *gin.Context) {
+	subs, err := services.GetWebhookSubscriptions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+	c.JSON(http.StatusOK, subs)
+}
+
+// GetWebhookHandler returns a single webhook subscription
+func GetWebhookHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	sub, err := services.GetWebhookSubscriptionByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// CreateWebhookHandler registers a webhook subscription. The signing secret is only returned here.
+func CreateWebhookHandler(c *gin.Context) {
+	var req struct {
+		Name       string   `json:"name"`
+		URL        string   `json:"url" binding:"required"`
+		EventTypes []string `json:"event_types"`
+		Owner      string   `json:"owner"` // Personal subscription used by notification rules
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub := models.WebhookSubscription{
+		Name:       req.Name,
+		URL:        req.URL,
+		EventTypes: pq.StringArray(req.EventTypes),
+		Owner:      req.Owner,
+		CreatedBy:  requestActor(c),
+	}
+	if err := services.CreateWebhookSubscription(&sub); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"subscription": sub, "secret": sub.Secret})
+}
+
+// UpdateWebhookHandler changes a subscription's URL, filter or enabled flag
+func UpdateWebhookHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	sub, err := services.GetWebhookSubscriptionByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	var req struct {
+		Name       *string  `json:"name"`
+		URL        *string  `json:"url"`
+		EventTypes []string `json:"event_types"`
+		Enabled    *bool    `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name != nil {
+		sub.Name = *req.Name
+	}
+	if req.URL != nil {
+		sub.URL = *req.URL
+	}
+	if req.EventTypes != nil {
+		sub.EventTypes = pq.StringArray(req.EventTypes)
+	}
+	if req.Enabled != nil {
+		sub.Enabled = *req.Enabled
+	}
+
+	if err := services.UpdateWebhookSubscription(&sub); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// DeleteWebhookHandler removes a subscription and its delivery history
+func DeleteWebhookHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	if err := services.DeleteWebhookSubscription(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// TestWebhookHandler queues a ping delivery to check an endpoint is reachable
+func TestWebhookHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	if _, err := services.GetWebhookSubscriptionByID(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	delivery, err := services.SendWebhookPing(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue test delivery"})
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// GetWebhookDeliveriesHandler lists recent deliveries for a subscription (?limit=, default 50)
+func GetWebhookDeliveriesHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	deliveries, err := services.GetWebhookDeliveries(id, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// GetWebhookDeliveryHandler returns a delivery with its per-attempt log
+func GetWebhookDeliveryHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("deliveryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	delivery, err := services.GetWebhookDeliveryByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// RedeliverWebhookHandler queues a new attempt of a previous delivery
+func RedeliverWebhookHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("deliveryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	delivery, err := services.RedeliverWebhook(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
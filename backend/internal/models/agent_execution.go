@@ -15,6 +15,8 @@ const (
 	StatusAwaitingApproval AgentExecutionStatus = "awaiting_approval"
 	StatusExecuting        AgentExecutionStatus = "executing"
 	StatusVerifying        AgentExecutionStatus = "verifying"
+	StatusRollbackPending  AgentExecutionStatus = "awaiting_rollback_approval"
+	StatusRollingBack      AgentExecutionStatus = "rolling_back"
 	StatusCompleted        AgentExecutionStatus = "completed"
 	StatusFailed           AgentExecutionStatus = "failed"
 	StatusCancelled        AgentExecutionStatus = "cancelled"
@@ -36,8 +38,18 @@ type AgentExecution struct {
 	EstimatedImpact string `json:"estimated_impact" gorm:"type:text"`       // Expected impact
 	Risks           JSONB  `json:"risks" gorm:"type:jsonb;default:'[]'"`    // Identified risks
 
+	// Plan: ordered catalog actions, each with its own commands, approval, logs and verification
+	Plan        JSONB `json:"plan" gorm:"type:jsonb;default:'[]'"` // []PlanStep
+	CurrentStep int   `json:"current_step" gorm:"default:0"`       // Index of the step being run or decided
+
+	// Plan edits: once an approver edits the plan, the AI's previewed plan is kept with the differences
+	AIPlan       JSONB      `json:"ai_plan" gorm:"column:ai_plan;type:jsonb;default:'[]'"` // []PlanStep as the agent previewed it
+	PlanDiff     JSONB      `json:"plan_diff" gorm:"type:jsonb;default:'[]'"`              // []PlanChange from AIPlan to Plan
+	PlanEditedBy string     `json:"plan_edited_by,omitempty" gorm:"size:255"`
+	PlanEditedAt *time.Time `json:"plan_edited_at,omitempty"`
+
 	// Phase: Execution
-	ExecutionLogs JSONB      `json:"execution_logs" gorm:"type:jsonb;default:'[]'"` // Detailed logs
+	ExecutionLogs JSONB      `json:"execution_logs" gorm:"type:jsonb;default:'[]'"` // Detailed logs (all steps)
 	StartedAt     *time.Time `json:"started_at"`
 	CompletedAt   *time.Time `json:"completed_at"`
 
@@ -46,11 +58,36 @@ type AgentExecution struct {
 	VerificationPassed *bool  `json:"verification_passed"`
 	VerificationNotes  string `json:"verification_notes" gorm:"type:text"`
 
+	// Phase: Rollback (snapshot before execution; compensating actions if execution or verification fails)
+	RollbackPlan        JSONB      `json:"rollback_plan" gorm:"type:jsonb;default:'{}'"` // RollbackPlan for the chosen action
+	Snapshot            JSONB      `json:"snapshot" gorm:"type:jsonb;default:'[]'"`      // Pre-change state per target
+	RollbackReason      string     `json:"rollback_reason,omitempty" gorm:"type:text"`   // What triggered the rollback
+	RollbackLogs        JSONB      `json:"rollback_logs" gorm:"type:jsonb;default:'[]'"`
+	RollbackChecks      JSONB      `json:"rollback_checks" gorm:"type:jsonb;default:'[]'"`
+	RollbackPassed      *bool      `json:"rollback_passed"`
+	RollbackApprovedBy  string     `json:"rollback_approved_by,omitempty" gorm:"size:255"`
+	RollbackStartedAt   *time.Time `json:"rollback_started_at"`
+	RollbackCompletedAt *time.Time `json:"rollback_completed_at"`
+
 	// Outcome
 	Success           *bool  `json:"success"`
 	ErrorMessage      string `json:"error_message" gorm:"type:text"`
 	RollbackPerformed bool   `json:"rollback_performed" gorm:"default:false"`
 
+	// Approval
+	ApprovalPolicy    JSONB      `json:"approval_policy" gorm:"type:jsonb;default:'{}'"` // ApprovalDecision from the policy
+	Approvals         JSONB      `json:"approvals" gorm:"type:jsonb;default:'[]'"`       // []Approval, one per distinct approver
+	PreviewState      JSONB      `json:"preview_state" gorm:"type:jsonb;default:'{}'"`   // {service: {health, status}} shown at preview
+	ApprovalExpiresAt *time.Time `json:"approval_expires_at,omitempty"`                  // Cancelled if still undecided by then
+	ApprovedBy        string     `json:"approved_by,omitempty" gorm:"size:255"`
+	RejectedBy        string     `json:"rejected_by,omitempty" gorm:"size:255"`
+	DecidedAt         *time.Time `json:"decided_at,omitempty"`
+
+	// Cancellation (any non-terminal phase)
+	CancelledBy  string     `json:"cancelled_by,omitempty" gorm:"size:255"`
+	CancelReason string     `json:"cancel_reason,omitempty" gorm:"type:text"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`
+
 	// Metadata
 	AgentModel string    `json:"agent_model" gorm:"size:50"`
 	DryRun     bool      `json:"dry_run" gorm:"default:false"`
@@ -60,12 +97,13 @@ type AgentExecution struct {
 
 // Command represents a single command to execute
 type Command struct {
-	Name        string                 `json:"name"`        // Human-readable name
-	Command     string                 `json:"command"`     // Actual command
-	Args        []string               `json:"args"`        // Command arguments
-	Target      string                 `json:"target"`      // Target system
-	Description string                 `json:"description"` // What this does
-	Metadata    map[string]interface{} `json:"metadata"`    // Additional data
+	Name        string                 `json:"name"`               // Human-readable name
+	Command     string                 `json:"command"`            // Actual command
+	Args        []string               `json:"args"`               // Command arguments
+	Target      string                 `json:"target"`             // Target system
+	Description string                 `json:"description"`        // What this does
+	Metadata    map[string]interface{} `json:"metadata"`           // Additional data
+	Required    bool                   `json:"required,omitempty"` // Catalog commands an approver may not drop from a step
 }
 
 // ExecutionLog represents a single execution log entry
@@ -73,9 +111,11 @@ type ExecutionLog struct {
 	Timestamp   time.Time `json:"timestamp"`
 	Command     string    `json:"command"`
 	Output      string    `json:"output"`
-	Status      string    `json:"status"` // "success", "failed", "skipped"
+	Status      string    `json:"status"` // "success", "failed", "skipped", "cancelled", "simulated"
 	DurationMs  int64     `json:"duration_ms"`
 	ErrorDetail string    `json:"error_detail,omitempty"`
+
+	Result map[string]interface{} `json:"result,omitempty"` // Structured output from the executor
 }
 
 // VerificationCheck represents a single verification check result
@@ -88,6 +128,81 @@ type VerificationCheck struct {
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// RollbackPlan is what an action declares for undoing itself
+type RollbackPlan struct {
+	Mode       string    `json:"mode"`       // "auto", "approval", or "none" when the action cannot be undone
+	Snapshot   []Command `json:"snapshot"`   // Read-only captures taken before the change
+	Compensate []Command `json:"compensate"` // Commands that undo the change
+	Notes      string    `json:"notes"`
+}
+
+// SnapshotEntry is one target's captured state
+type SnapshotEntry struct {
+	Target  string                 `json:"target"`
+	TakenAt time.Time              `json:"taken_at"`
+	State   map[string]interface{} `json:"state,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+// Plan step statuses
+const (
+	StepPending   = "pending"
+	StepRunning   = "running" // Commands have started
+	StepVerifying = "verifying"
+	StepPassed    = "passed"
+	StepFailed    = "failed"
+	StepSkipped   = "skipped"
+)
+
+// PlanStep is one action in a remediation plan
+type PlanStep struct {
+	Action      string              `json:"action"`
+	Reason      string              `json:"reason,omitempty"`
+	Condition   *StepCondition      `json:"condition,omitempty"`        // nil: always run
+	Params      map[string]int      `json:"params,omitempty"`           // Catalog parameters, passed to every command as metadata
+	Dropped     []string            `json:"dropped_commands,omitempty"` // Catalog commands an approver left out
+	Commands    []Command           `json:"commands"`
+	Risks       []Risk              `json:"risks"`
+	Rollback    RollbackPlan        `json:"rollback"`
+	Approved    bool                `json:"approved"`
+	Status      string              `json:"status"`
+	Outcome     string              `json:"outcome,omitempty"`
+	Logs        []ExecutionLog      `json:"logs"`
+	Checks      []VerificationCheck `json:"checks"`
+	StartedAt   *time.Time          `json:"started_at,omitempty"`
+	CompletedAt *time.Time          `json:"completed_at,omitempty"`
+}
+
+// PlanChange is one difference between the agent's plan and the plan a person edited it into
+type PlanChange struct {
+	Step   int         `json:"step"` // 0-based step index
+	Kind   string      `json:"kind"` // "step_added", "step_removed", "action_changed", "condition_changed", "param_changed", "command_dropped", "command_restored"
+	Detail string      `json:"detail"`
+	From   interface{} `json:"from,omitempty"`
+	To     interface{} `json:"to,omitempty"`
+}
+
+// StepCondition runs a step only if the previous step that ran had a given verification result
+type StepCondition struct {
+	Check  string `json:"check,omitempty"` // A check of that step; empty means all of its checks together
+	Passed bool   `json:"passed"`          // Run when the check passed (true) or failed (false)
+}
+
+// ApprovalDecision is what the approval policy required before an execution may run
+type ApprovalDecision struct {
+	RiskLevel         string    `json:"risk_level"`         // Highest catalog risk level in the plan
+	RequiredApprovals int       `json:"required_approvals"` // 0 auto-approves; 2 is the two-person rule
+	Reasons           []string  `json:"reasons"`            // Why the requirement is what it is
+	EvaluatedAt       time.Time `json:"evaluated_at"`
+}
+
+// Approval is one approver's sign-off on an execution
+type Approval struct {
+	Actor string    `json:"actor"`
+	Steps []int     `json:"steps"` // Plan steps (0-based) this approver approved
+	At    time.Time `json:"at"`
+}
+
 // Risk represents an identified risk
 type Risk struct {
 	Level       string `json:"level"` // "low", "medium", "high"
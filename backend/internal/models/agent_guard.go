@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AgentTargetLease gives one execution exclusive use of a target system while it runs and verifies
+// its commands. A lease outlives its holder only until ExpiresAt, so a crashed worker cannot block a target.
+type AgentTargetLease struct {
+	Target      string    `gorm:"primaryKey;size:100" json:"target"`
+	ExecutionID uuid.UUID `gorm:"type:uuid;not null;index" json:"execution_id"`
+	AcquiredAt  time.Time `json:"acquired_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// AgentActionRun records one action the agent actually ran (dry runs excluded), counted by the rate limits
+type AgentActionRun struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ExecutionID uuid.UUID `gorm:"type:uuid;not null;index" json:"execution_id"`
+	IncidentID  uuid.UUID `gorm:"type:uuid" json:"incident_id"`
+	Action      string    `json:"action" gorm:"size:100;index:idx_agent_action_runs_action_time,priority:1"`
+	Target      string    `json:"target" gorm:"size:100;index:idx_agent_action_runs_target_time,priority:1"`
+	StartedAt   time.Time `json:"started_at" gorm:"index:idx_agent_action_runs_action_time,priority:2;index:idx_agent_action_runs_target_time,priority:2"`
+}
+
+// AgentBreakerState is the single row holding the agent's circuit breaker. While OpenedAt is set, no
+// execution may start; a reset forgives the failures before ResetAt.
+type AgentBreakerState struct {
+	ID        int        `gorm:"primaryKey" json:"-"`
+	OpenedAt  *time.Time `json:"opened_at"`
+	Reason    string     `json:"reason" gorm:"type:text"`
+	ResetAt   *time.Time `json:"reset_at"`
+	ResetBy   string     `json:"reset_by,omitempty" gorm:"size:255"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
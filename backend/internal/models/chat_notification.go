@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChatNotification records a post to the shared chat-ops channel so each thing is only posted there once
+type ChatNotification struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Kind       string    `json:"kind" gorm:"size:50;not null;uniqueIndex:idx_chat_notification_subject"`         // "high_severity_incident", "approval_request" or the routed event's type
+	SubjectID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_chat_notification_subject" json:"subject_id"` // Incident, execution or routed event ID
+	IncidentID uuid.UUID `gorm:"type:uuid;index" json:"incident_id"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty" gorm:"type:text"`
+	SentAt     time.Time `json:"sent_at"`
+}
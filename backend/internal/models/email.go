@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Email delivery modes
+const (
+	DeliveryImmediate    = "immediate"
+	DeliveryHourlyDigest = "hourly"
+	DeliveryDailyDigest  = "daily"
+)
+
+// Email outbox statuses
+const (
+	EmailPending = "pending"
+	EmailSent    = "sent"
+	EmailFailed  = "failed" // Gave up after the maximum number of attempts
+)
+
+// NotificationPreference is a user's contact details and notification settings, keyed by the name sent in X-User-Name
+type NotificationPreference struct {
+	UserName     string         `gorm:"primaryKey;size:255" json:"user_name"`
+	Email        string         `json:"email" gorm:"size:255"`
+	EventTypes   pq.StringArray `json:"event_types" gorm:"type:text[];default:'{}'"` // Email events for users without notification rules; empty = every email event
+	DeliveryMode string         `json:"delivery_mode" gorm:"size:20;default:immediate"`
+	Enabled      bool           `json:"enabled" gorm:"default:true"`
+	LastDigestAt *time.Time     `json:"last_digest_at"`
+
+	// Routing
+	ChatWebhookURL  string `json:"chat_webhook_url" gorm:"type:text"`   // Personal chat webhook; falls back to CHATOPS_WEBHOOK_URL
+	QuietHoursStart string `json:"quiet_hours_start" gorm:"size:5"`     // "HH:MM", empty = no quiet hours
+	QuietHoursEnd   string `json:"quiet_hours_end" gorm:"size:5"`       // "HH:MM", may wrap past midnight
+	Timezone        string `json:"timezone" gorm:"size:64;default:UTC"` // IANA zone for quiet hours
+	ThrottleMinutes int    `json:"throttle_minutes" gorm:"default:10"`  // At most one notification per incident fingerprint and channel in this window
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EmailMessage is a rendered email waiting in (or sent from) the outbox
+type EmailMessage struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserName      string     `json:"user_name" gorm:"size:255;index"`
+	Recipient     string     `json:"recipient" gorm:"size:255;not null"`
+	Subject       string     `json:"subject" gorm:"type:text"`
+	TextBody      string     `json:"text_body" gorm:"type:text"`
+	HTMLBody      string     `json:"html_body" gorm:"type:text"`
+	EventType     string     `json:"event_type" gorm:"size:100"` // "digest" for digest emails
+	Status        string     `json:"status" gorm:"size:20;default:pending;index"`
+	Attempts      int        `json:"attempts" gorm:"default:0"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index"`
+	LastError     string     `json:"last_error" gorm:"type:text"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (EmailMessage) TableName() string {
+	return "email_outbox"
+}
+
+// EmailDigestItem is a notification held back for a user's next digest
+type EmailDigestItem struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserName   string     `json:"user_name" gorm:"size:255;not null;index"`
+	EventType  string     `json:"event_type" gorm:"size:100"`
+	IncidentID uuid.UUID  `gorm:"type:uuid" json:"incident_id"`
+	Summary    string     `json:"summary" gorm:"type:text"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DigestedAt *time.Time `json:"digested_at"` // Set when included in a sent digest
+}
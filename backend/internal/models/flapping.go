@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Flap transition kinds
+const (
+	FlapOpened   = "opened"   // New incident, or a resolved one reopened
+	FlapResolved = "resolved" // Incident resolved
+)
+
+// FlapState tracks whether an alert fingerprint is oscillating between open and resolved
+type FlapState struct {
+	Fingerprint      string     `gorm:"primaryKey;size:32" json:"fingerprint"`
+	Source           string     `json:"source" gorm:"size:255;index"`
+	Message          string     `json:"message" gorm:"type:text"` // Latest incident message, for display
+	Flapping         bool       `json:"flapping" gorm:"default:false;index"`
+	FlappingSince    *time.Time `json:"flapping_since"`
+	TransitionCount  int        `json:"transition_count"` // Transitions inside the window at the last evaluation
+	LastTransitionAt time.Time  `json:"last_transition_at"`
+	LastIncidentID   *uuid.UUID `gorm:"type:uuid" json:"last_incident_id"`
+	ClearedAt        *time.Time `json:"cleared_at"`
+	ClearedBy        string     `json:"cleared_by,omitempty" gorm:"size:255"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// FlapTransition is one open/resolve state change for a fingerprint
+type FlapTransition struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Fingerprint string    `json:"fingerprint" gorm:"size:32;not null;index:idx_flap_transitions_fp_time,priority:1"`
+	IncidentID  uuid.UUID `gorm:"type:uuid;not null;index" json:"incident_id"`
+	Kind        string    `json:"kind" gorm:"size:20"`
+	OccurredAt  time.Time `json:"occurred_at" gorm:"index:idx_flap_transitions_fp_time,priority:2"`
+}
@@ -68,6 +68,7 @@ type Incident struct {
 	Source      string    `json:"source"`
 	Status      string    `json:"status" gorm:"default:triage"`
 	Team        string    `json:"team" gorm:"default:Platform"`
+	Assignee    string    `json:"assignee" gorm:"size:255"`           // Display name of the responder who owns the incident
 	GeneratedBy string    `json:"generated_by" gorm:"default:manual"` // "gemini", "groq", "fallback", "manual"
 	Notes       string    `json:"notes" gorm:"type:text"`
 
@@ -83,8 +84,15 @@ type Incident struct {
 	RemediationMode string         `json:"remediation_mode" gorm:"type:varchar(50);default:advisory"` // "automated", "manual", "advisory"
 	Metadata        JSONB          `json:"metadata" gorm:"type:jsonb;default:'{}'"`                   // Extensible metadata
 
-	CreatedAt     time.Time         `json:"created_at"`
-	UpdatedAt     time.Time         `json:"updated_at"`
-	Analysis      *IncidentAnalysis `gorm:"foreignKey:IncidentID" json:"analysis,omitempty"`
-	StatusHistory []StatusHistory   `gorm:"foreignKey:IncidentID;constraint:OnDelete:CASCADE;" json:"status_history,omitempty"`
+	SLABreachedAt           *time.Time        `json:"sla_breached_at"`            // Set once when the resolution target for its severity passes
+	LastStakeholderUpdateAt *time.Time        `json:"last_stakeholder_update_at"` // Resets the update cadence clock
+	LastUpdateReminderAt    *time.Time        `json:"last_update_reminder_at"`    // When the last "update due" reminder went out
+	SnoozedUntil            *time.Time        `json:"snoozed_until"`              // Hidden from the board until this time
+	SnoozeReason            string            `json:"snooze_reason" gorm:"type:text"`
+	SnoozedBy               string            `json:"snoozed_by" gorm:"size:255"`
+	StaleFlaggedAt          *time.Time        `json:"stale_flagged_at"` // Set when status has not changed within the team's threshold; cleared on the next change
+	CreatedAt               time.Time         `json:"created_at"`
+	UpdatedAt               time.Time         `json:"updated_at"`
+	Analysis                *IncidentAnalysis `gorm:"foreignKey:IncidentID" json:"analysis,omitempty"`
+	StatusHistory           []StatusHistory   `gorm:"foreignKey:IncidentID;constraint:OnDelete:CASCADE;" json:"status_history,omitempty"`
 }
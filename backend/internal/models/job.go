@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job statuses
+const (
+	JobQueued    = "queued"  // Waiting for RunAt, including retries after a failure
+	JobRunning   = "running" // Claimed by a worker; reclaimed if its lease expires
+	JobSucceeded = "succeeded"
+	JobDead      = "dead" // Out of attempts (or failed permanently); kept for inspection and manual retry
+	JobCancelled = "cancelled"
+)
+
+// Job is a unit of durable background work, claimed by workers with SELECT ... FOR UPDATE SKIP LOCKED
+type Job struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Type        string     `json:"type" gorm:"size:100;not null;index:idx_jobs_claim,priority:1"`
+	Payload     JSONB      `json:"payload" gorm:"type:jsonb;default:'{}'"`
+	Status      string     `json:"status" gorm:"size:20;default:queued;index:idx_jobs_claim,priority:2"`
+	Attempts    int        `json:"attempts" gorm:"default:0"`
+	MaxAttempts int        `json:"max_attempts" gorm:"default:5"`
+	RunAt       time.Time  `json:"run_at" gorm:"index:idx_jobs_claim,priority:3"` // Earliest time the next attempt may start
+	LockedUntil *time.Time `json:"locked_until"`                                  // Lease; a crashed worker's job is reclaimed after this
+	LockedBy    string     `json:"locked_by,omitempty" gorm:"size:100"`
+	LastError   string     `json:"last_error,omitempty" gorm:"type:text"`
+	CancelledBy string     `json:"cancelled_by,omitempty" gorm:"size:255"`
+	StartedAt   *time.Time `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"index"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// FinalAttempt reports whether a failure of the current attempt dead-letters the job
+func (j *Job) FinalAttempt() bool {
+	return j.Attempts >= j.MaxAttempts
+}
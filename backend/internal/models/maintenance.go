@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// MaintenanceWindow is a scheduled period during which alerts for some services are expected
+type MaintenanceWindow struct {
+	ID                uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Title             string         `json:"title" gorm:"size:255"`
+	Services          pq.StringArray `json:"services" gorm:"type:text[];default:'{}'"` // Systems covered by the window
+	StartsAt          time.Time      `json:"starts_at"`                                // One-off start, or when a recurring series begins
+	EndsAt            *time.Time     `json:"ends_at"`                                  // One-off end, or when a recurring series stops (nil = forever)
+	Recurrence        string         `json:"recurrence" gorm:"size:100"`               // Cron expression; empty for one-off windows
+	Timezone          string         `json:"timezone" gorm:"size:64"`                  // IANA zone the recurrence is read in (empty = UTC)
+	DurationMinutes   int            `json:"duration_minutes"`                         // Length of each recurring occurrence
+	Mode              string         `json:"mode" gorm:"size:20;default:suppress"`     // "suppress" or "annotate"
+	AllowAgentActions bool           `json:"allow_agent_actions" gorm:"default:false"`
+	CreatedBy         string         `json:"created_by" gorm:"size:255"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+
+	// Computed on read
+	Active bool `json:"active" gorm:"-"`
+}
+
+// SuppressedAlert is an incoming alert that was swallowed by a maintenance window
+type SuppressedAlert struct {
+	ID              uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	WindowID        uuid.UUID      `gorm:"type:uuid;not null;index" json:"window_id"`
+	Message         string         `json:"message" gorm:"type:text"`
+	Source          string         `json:"source" gorm:"size:255"`
+	AffectedSystems pq.StringArray `json:"affected_systems" gorm:"type:text[];default:'{}'"`
+	Payload         JSONB          `json:"payload" gorm:"type:jsonb;default:'{}'"` // Original incident as received
+	ReceivedAt      time.Time      `json:"received_at"`
+	ReviewedAt      *time.Time     `json:"reviewed_at"`
+	ReviewedBy      string         `json:"reviewed_by" gorm:"size:255"`
+	Resolution      string         `json:"resolution" gorm:"size:20"`              // "dismissed" or "promoted"
+	IncidentID      *uuid.UUID     `gorm:"type:uuid" json:"incident_id,omitempty"` // Set when promoted to an incident
+}
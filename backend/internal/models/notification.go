@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Notification channels
+const (
+	ChannelWebSocket = "ws"      // Toast in the dashboard
+	ChannelEmail     = "email"   // Immediate email or digest, per the user's delivery mode
+	ChannelWebhook   = "webhook" // The user's own webhook subscriptions
+	ChannelChat      = "chat"    // Slack-compatible incoming webhook
+)
+
+// Notification attempt outcomes
+const (
+	NotificationSent       = "sent"       // Delivered (WebSocket, chat)
+	NotificationQueued     = "queued"     // Handed to a retrying queue (email outbox, digest, webhook)
+	NotificationSuppressed = "suppressed" // Deliberately not sent, see Reason
+	NotificationFailed     = "failed"
+)
+
+// NotificationRule decides which events reach a user and on which channels.
+// Empty filters match everything.
+type NotificationRule struct {
+	ID               uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserName         string         `json:"user_name" gorm:"size:255;not null;index"`
+	Name             string         `json:"name" gorm:"size:255"`
+	Channels         pq.StringArray `json:"channels" gorm:"type:text[];default:'{}'"`
+	EventTypes       pq.StringArray `json:"event_types" gorm:"type:text[];default:'{}'"`
+	Severities       pq.StringArray `json:"severities" gorm:"type:text[];default:'{}'"`
+	Teams            pq.StringArray `json:"teams" gorm:"type:text[];default:'{}'"`
+	BypassQuietHours bool           `json:"bypass_quiet_hours" gorm:"default:false"` // e.g. still page for high severity at night
+	Enabled          bool           `json:"enabled" gorm:"default:true"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+// NotificationAttempt records every routing decision, so "was I notified?" has an answer
+type NotificationAttempt struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserName   string     `json:"user_name" gorm:"size:255;not null;index"`
+	RuleID     *uuid.UUID `gorm:"type:uuid" json:"rule_id"` // nil for the default rule derived from preferences
+	Channel    string     `json:"channel" gorm:"size:20"`
+	EventID    uuid.UUID  `gorm:"type:uuid" json:"event_id"`
+	EventType  string     `json:"event_type" gorm:"size:100"`
+	IncidentID *uuid.UUID `gorm:"type:uuid;index" json:"incident_id"`
+	DedupKey   string     `json:"dedup_key" gorm:"size:255;index"` // Channel + incident fingerprint, used for throttling
+	Status     string     `json:"status" gorm:"size:20"`
+	Reason     string     `json:"reason,omitempty" gorm:"size:50"` // quiet_hours, throttled, no_address, ...
+	Detail     string     `json:"detail,omitempty" gorm:"type:text"`
+	Reference  string     `json:"reference,omitempty" gorm:"size:255"` // Outbox/delivery ID for queued attempts
+	CreatedAt  time.Time  `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Runbook is one version of a step-by-step operational guide.
+// Each edit creates a new row with the same slug and an incremented version.
+type Runbook struct {
+	ID        uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Slug      string         `json:"slug" gorm:"size:100;not null;uniqueIndex:idx_runbooks_slug_version"`
+	Version   int            `json:"version" gorm:"not null;uniqueIndex:idx_runbooks_slug_version"`
+	Title     string         `json:"title" gorm:"size:255"`
+	Format    string         `json:"format" gorm:"size:20;default:markdown"`   // "markdown" or "yaml"
+	Content   string         `json:"content" gorm:"type:text"`                 // Raw source as authored
+	Steps     JSONB          `json:"steps" gorm:"type:jsonb;default:'[]'"`     // Ordered []RunbookStep parsed from Content
+	Services  pq.StringArray `json:"services" gorm:"type:text[];default:'{}'"` // Catalog services this applies to
+	Sources   pq.StringArray `json:"sources" gorm:"type:text[];default:'{}'"`  // Incident sources this applies to
+	Actions   pq.StringArray `json:"actions" gorm:"type:text[];default:'{}'"`  // Related agent actions
+	Teams     pq.StringArray `json:"teams" gorm:"type:text[];default:'{}'"`    // Teams incidents are routed to
+	CreatedBy string         `json:"created_by" gorm:"size:255"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// RunbookStep is a single ordered instruction in a runbook
+type RunbookStep struct {
+	Index  int    `json:"index"`
+	Title  string `json:"title"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// RunbookSuggestion is a runbook proposed for an incident along with why it matched
+type RunbookSuggestion struct {
+	Runbook Runbook  `json:"runbook"`
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// IncidentChecklist is a runbook instantiated against an incident
+type IncidentChecklist struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	IncidentID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"incident_id"`
+	RunbookID      uuid.UUID  `gorm:"type:uuid;not null" json:"runbook_id"`
+	RunbookSlug    string     `json:"runbook_slug" gorm:"size:100"`
+	RunbookVersion int        `json:"runbook_version"`
+	Title          string     `json:"title" gorm:"size:255"`
+	Steps          JSONB      `json:"steps" gorm:"type:jsonb;default:'[]'"` // []ChecklistStep
+	StartedBy      string     `json:"started_by" gorm:"size:255"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// ChecklistStep tracks who ticked off a runbook step and when
+type ChecklistStep struct {
+	Index       int        `json:"index"`
+	Title       string     `json:"title"`
+	Detail      string     `json:"detail,omitempty"`
+	Completed   bool       `json:"completed"`
+	CompletedBy string     `json:"completed_by,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableName specifies the table name for GORM
+func (IncidentChecklist) TableName() string {
+	return "incident_checklists"
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Service is an entry in the service catalog
+type Service struct {
+	Name        string         `gorm:"primaryKey;size:100" json:"name"`
+	DisplayName string         `json:"display_name" gorm:"size:255"`
+	Description string         `json:"description" gorm:"type:text"`
+	Owner       string         `json:"owner" gorm:"size:100"`                      // Owning team
+	Tier        int            `json:"tier" gorm:"default:3"`                      // 1 = business critical, 3 = best effort
+	Runbooks    pq.StringArray `json:"runbooks" gorm:"type:text[];default:'{}'"`   // Runbook slugs or URLs
+	DependsOn   pq.StringArray `json:"depends_on" gorm:"type:text[];default:'{}'"` // Upstream services this one needs
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Service) TableName() string {
+	return "services"
+}
+
+// ImpactedService is a downstream service reached while computing blast radius
+type ImpactedService struct {
+	Name  string   `json:"name"`
+	Owner string   `json:"owner"`
+	Tier  int      `json:"tier"`
+	Depth int      `json:"depth"` // Hops away from the nearest affected system
+	Path  []string `json:"path"`  // Dependency chain from the affected system to this service
+}
+
+// BlastRadius describes everything that may be affected when a set of systems fail
+type BlastRadius struct {
+	AffectedSystems []string          `json:"affected_systems"`
+	UnknownSystems  []string          `json:"unknown_systems,omitempty"` // Systems not in the catalog
+	Impacted        []ImpactedService `json:"impacted"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UpdateCadence is how often stakeholders must hear about an open incident of a given severity
+type UpdateCadence struct {
+	Severity        string    `gorm:"primaryKey;size:20" json:"severity"` // high, medium, low
+	IntervalMinutes int       `json:"interval_minutes" gorm:"not null"`
+	Enabled         bool      `json:"enabled" gorm:"default:true"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (UpdateCadence) TableName() string {
+	return "stakeholder_update_cadences"
+}
+
+// StakeholderUpdate is a communication sent to stakeholders about an incident
+type StakeholderUpdate struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	IncidentID uuid.UUID `gorm:"type:uuid;not null;index" json:"incident_id"`
+	Message    string    `json:"message" gorm:"type:text;not null"`
+	PostedBy   string    `json:"posted_by" gorm:"size:255"`
+	AIDrafted  bool      `json:"ai_drafted" gorm:"default:false"` // Started from an AI draft (possibly edited)
+	CreatedAt  time.Time `json:"created_at"`
+}
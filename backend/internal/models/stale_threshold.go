@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// StaleThreshold is how long a team's incidents may sit in one status before being flagged stale
+type StaleThreshold struct {
+	Team           string    `gorm:"primaryKey;size:100" json:"team"`
+	ThresholdHours int       `json:"threshold_hours" gorm:"not null"`
+	Enabled        bool      `json:"enabled" gorm:"default:true"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (StaleThreshold) TableName() string {
+	return "stale_incident_thresholds"
+}
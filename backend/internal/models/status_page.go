@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Component statuses, from best to worst
+const (
+	ComponentOperational   = "operational"
+	ComponentDegraded      = "degraded"
+	ComponentPartialOutage = "partial_outage"
+	ComponentMajorOutage   = "major_outage"
+)
+
+// Public post statuses
+const (
+	PostInvestigating = "investigating"
+	PostIdentified    = "identified"
+	PostMonitoring    = "monitoring"
+	PostResolved      = "resolved"
+)
+
+// StatusComponent is a customer-facing part of the product shown on the status page
+type StatusComponent struct {
+	ID             uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name           string         `json:"name" gorm:"size:255;not null"`
+	Description    string         `json:"description" gorm:"type:text"`
+	Services       pq.StringArray `json:"services" gorm:"type:text[];default:'{}'"` // Catalog services / affected systems backing this component
+	DisplayOrder   int            `json:"display_order" gorm:"default:0"`
+	OverrideStatus string         `json:"override_status" gorm:"size:20"` // Manual status; empty = derived from open incidents
+	OverrideReason string         `json:"override_reason" gorm:"type:text"`
+	OverrideBy     string         `json:"override_by" gorm:"size:255"`
+	OverrideUntil  *time.Time     `json:"override_until"` // nil = until cleared
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+
+	// Computed on read
+	Status        string `json:"status" gorm:"-"`
+	DerivedStatus string `json:"derived_status" gorm:"-"`
+}
+
+// StatusPost is a public incident write-up, kept separate from internal incident notes
+type StatusPost struct {
+	ID           uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Title        string         `json:"title" gorm:"size:255;not null"`
+	Status       string         `json:"status" gorm:"size:20;default:investigating"`
+	ComponentIDs pq.StringArray `json:"component_ids" gorm:"type:text[];default:'{}'"`
+	IncidentID   *uuid.UUID     `gorm:"type:uuid;index" json:"incident_id,omitempty"` // Internal incident, never exposed publicly
+	CreatedBy    string         `json:"created_by" gorm:"size:255"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	ResolvedAt   *time.Time     `json:"resolved_at"`
+
+	Updates []StatusPostUpdate `gorm:"foreignKey:PostID" json:"updates,omitempty"`
+}
+
+// StatusPostUpdate is a timestamped public update on a post
+type StatusPostUpdate struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	PostID    uuid.UUID `gorm:"type:uuid;not null;index" json:"post_id"`
+	Status    string    `json:"status" gorm:"size:20"`
+	Message   string    `json:"message" gorm:"type:text"`
+	CreatedBy string    `json:"created_by" gorm:"size:255"`
+	CreatedAt time.Time `json:"created_at"`
+}
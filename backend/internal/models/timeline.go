@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimelineEntry records a notable event on an incident (runbook progress, updates, automation decisions)
+type TimelineEntry struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	IncidentID uuid.UUID `gorm:"type:uuid;not null;index" json:"incident_id"`
+	EventType  string    `json:"event_type" gorm:"size:100"` // e.g. "runbook_started", "runbook_step_completed"
+	Actor      string    `json:"actor" gorm:"size:255"`      // User name or "system"
+	Message    string    `json:"message" gorm:"type:text"`
+	Metadata   JSONB     `json:"metadata" gorm:"type:jsonb;default:'{}'"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (TimelineEntry) TableName() string {
+	return "incident_timeline"
+}
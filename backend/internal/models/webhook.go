@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"   // Waiting for its first attempt
+	WebhookDeliveryRetrying  = "retrying"  // Failed at least once, another attempt is scheduled
+	WebhookDeliverySucceeded = "succeeded" // Endpoint returned 2xx
+	WebhookDeliveryDead      = "dead"      // Gave up after the maximum number of attempts
+)
+
+// WebhookSubscription is an external endpoint that receives signed event notifications
+type WebhookSubscription struct {
+	ID                  uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name                string         `json:"name" gorm:"size:255"`
+	URL                 string         `json:"url" gorm:"type:text;not null"`
+	Secret              string         `json:"-" gorm:"size:255;not null"`                  // HMAC-SHA256 signing key, only shown on creation
+	EventTypes          pq.StringArray `json:"event_types" gorm:"type:text[];default:'{}'"` // Empty = all events
+	Enabled             bool           `json:"enabled" gorm:"default:true"`
+	ConsecutiveFailures int            `json:"consecutive_failures" gorm:"default:0"`
+	DisabledAt          *time.Time     `json:"disabled_at"`
+	DisabledReason      string         `json:"disabled_reason" gorm:"type:text"`
+	Owner               string         `json:"owner" gorm:"size:255;index"` // Set for personal subscriptions used by notification rules instead of the global fan-out
+	CreatedBy           string         `json:"created_by" gorm:"size:255"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+}
+
+// WebhookDelivery is one event queued for one subscription
+type WebhookDelivery struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	SubscriptionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"subscription_id"`
+	EventID        uuid.UUID  `gorm:"type:uuid;not null" json:"event_id"`
+	EventType      string     `json:"event_type" gorm:"size:100"`
+	Payload        JSONB      `json:"payload" gorm:"type:jsonb;default:'{}'"` // Exact body sent to the endpoint
+	Status         string     `json:"status" gorm:"size:20;default:pending;index"`
+	Attempts       int        `json:"attempts" gorm:"default:0"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index"`
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `json:"last_error" gorm:"type:text"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	RedeliveryOf   *uuid.UUID `gorm:"type:uuid" json:"redelivery_of,omitempty"` // Original delivery when manually redelivered
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	AttemptLog []WebhookDeliveryAttempt `gorm:"foreignKey:DeliveryID" json:"attempt_log,omitempty"`
+}
+
+// WebhookDeliveryAttempt logs a single HTTP attempt for a delivery
+type WebhookDeliveryAttempt struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	DeliveryID     uuid.UUID `gorm:"type:uuid;not null;index" json:"delivery_id"`
+	SubscriptionID uuid.UUID `gorm:"type:uuid;not null;index" json:"subscription_id"`
+	AttemptNumber  int       `json:"attempt_number"`
+	StatusCode     int       `json:"status_code"`
+	ResponseBody   string    `json:"response_body" gorm:"type:text"` // Truncated
+	Error          string    `json:"error,omitempty" gorm:"type:text"`
+	DurationMs     int64     `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -15,7 +15,7 @@ func SetupRouter() *gin.Engine {
 		// Set CORS headers for every request
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-User-Name")
 		c.Header("Access-Control-Max-Age", "86400")
 
 		// Handle preflight OPTIONS request
@@ -47,13 +47,127 @@ func SetupRouter() *gin.Engine {
 		api.DELETE("/incidents/:id", handlers.DeleteIncidentHandler)
 		api.POST("/incidents/:id/diagnose", handlers.TriggerAIDiagnosisHandler)
 		api.POST("/incidents/:id/suggest-fix", handlers.TriggerAISuggestedFixHandler)
+		api.GET("/incidents/:id/blast-radius", handlers.GetIncidentBlastRadiusHandler)
+		api.GET("/incidents/:id/timeline", handlers.GetIncidentTimelineHandler)
+
+		// Stakeholder update routes
+		api.GET("/incidents/:id/stakeholder-updates", handlers.GetStakeholderUpdatesHandler)
+		api.POST("/incidents/:id/stakeholder-updates", handlers.PostStakeholderUpdateHandler)
+		api.POST("/incidents/:id/stakeholder-updates/draft", handlers.DraftStakeholderUpdateHandler)
+		api.GET("/stakeholder-update-cadences", handlers.GetUpdateCadencesHandler)
+		api.PUT("/stakeholder-update-cadences/:severity", handlers.SaveUpdateCadenceHandler)
+
+		// Snooze and stale incident routes
+		api.POST("/incidents/:id/snooze", handlers.SnoozeIncidentHandler)
+		api.DELETE("/incidents/:id/snooze", handlers.UnsnoozeIncidentHandler)
+		api.GET("/stale-thresholds", handlers.GetStaleThresholdsHandler)
+		api.PUT("/stale-thresholds/:team", handlers.SaveStaleThresholdHandler)
+		api.DELETE("/stale-thresholds/:team", handlers.DeleteStaleThresholdHandler)
+
+		// Flap detection routes
+		api.GET("/flapping", handlers.GetFlapStatesHandler)
+		api.GET("/flapping/:fingerprint", handlers.GetFlapDetailHandler)
+		api.DELETE("/flapping/:fingerprint", handlers.ClearFlapStateHandler)
+		api.GET("/incidents/:id/flapping", handlers.GetIncidentFlapDetailHandler)
+
+		// Job queue admin routes
+		api.GET("/admin/jobs", handlers.GetJobsHandler)
+		api.GET("/admin/jobs/stats", handlers.GetJobStatsHandler)
+		api.GET("/admin/jobs/:jobId", handlers.GetJobHandler)
+		api.POST("/admin/jobs/:jobId/retry", handlers.RetryJobHandler)
+		api.POST("/admin/jobs/:jobId/cancel", handlers.CancelJobHandler)
+
+		// Runbook routes
+		api.GET("/runbooks", handlers.GetRunbooksHandler)
+		api.POST("/runbooks", handlers.CreateRunbookHandler)
+		api.GET("/runbooks/:slug", handlers.GetRunbookHandler)
+		api.GET("/runbooks/:slug/versions", handlers.GetRunbookVersionsHandler)
+		api.GET("/runbooks/:slug/versions/:version", handlers.GetRunbookHandler)
+		api.GET("/incidents/:id/runbooks/suggested", handlers.SuggestRunbooksHandler)
+		api.GET("/incidents/:id/checklists", handlers.GetIncidentChecklistsHandler)
+		api.POST("/incidents/:id/checklists", handlers.StartIncidentChecklistHandler)
+		api.PATCH("/incidents/:id/checklists/:checklistId/steps/:index", handlers.UpdateChecklistStepHandler)
+
+		// Service catalog routes
+		api.GET("/services", handlers.GetAllServicesHandler)
+		api.GET("/services/graph", handlers.GetServiceGraphHandler)
+		api.POST("/services", handlers.CreateServiceHandler)
+		api.GET("/services/:name", handlers.GetServiceHandler)
+		api.PUT("/services/:name", handlers.UpdateServiceHandler)
+		api.DELETE("/services/:name", handlers.DeleteServiceHandler)
+		api.GET("/services/:name/blast-radius", handlers.GetServiceBlastRadiusHandler)
+
+		// Maintenance window routes
+		api.GET("/maintenance-windows", handlers.GetMaintenanceWindowsHandler)
+		api.POST("/maintenance-windows", handlers.CreateMaintenanceWindowHandler)
+		api.GET("/maintenance-windows/suppressed-alerts", handlers.GetSuppressedAlertsHandler)
+		api.POST("/maintenance-windows/suppressed-alerts/:alertId/review", handlers.ReviewSuppressedAlertHandler)
+		api.GET("/maintenance-windows/:windowId", handlers.GetMaintenanceWindowHandler)
+		api.PUT("/maintenance-windows/:windowId", handlers.UpdateMaintenanceWindowHandler)
+		api.DELETE("/maintenance-windows/:windowId", handlers.DeleteMaintenanceWindowHandler)
+		api.GET("/maintenance-windows/:windowId/suppressed-alerts", handlers.GetSuppressedAlertsHandler)
+
+		// Webhook routes
+		api.GET("/webhooks", handlers.GetWebhooksHandler)
+		api.POST("/webhooks", handlers.CreateWebhookHandler)
+		api.GET("/webhooks/event-types", handlers.GetWebhookEventTypesHandler)
+		api.GET("/webhooks/deliveries/:deliveryId", handlers.GetWebhookDeliveryHandler)
+		api.POST("/webhooks/deliveries/:deliveryId/redeliver", handlers.RedeliverWebhookHandler)
+		api.GET("/webhooks/:id", handlers.GetWebhookHandler)
+		api.PATCH("/webhooks/:id", handlers.UpdateWebhookHandler)
+		api.DELETE("/webhooks/:id", handlers.DeleteWebhookHandler)
+		api.GET("/webhooks/:id/deliveries", handlers.GetWebhookDeliveriesHandler)
+		api.POST("/webhooks/:id/test", handlers.TestWebhookHandler)
+
+		// Notification routes
+		api.GET("/notification-preferences", handlers.GetNotificationPreferencesHandler)
+		api.GET("/notification-preferences/:user", handlers.GetNotificationPreferenceHandler)
+		api.PUT("/notification-preferences/:user", handlers.SaveNotificationPreferenceHandler)
+		api.DELETE("/notification-preferences/:user", handlers.DeleteNotificationPreferenceHandler)
+		api.GET("/notification-rules", handlers.GetNotificationRulesHandler)
+		api.POST("/notification-rules", handlers.CreateNotificationRuleHandler)
+		api.PUT("/notification-rules/:ruleId", handlers.UpdateNotificationRuleHandler)
+		api.DELETE("/notification-rules/:ruleId", handlers.DeleteNotificationRuleHandler)
+		api.GET("/notifications/attempts", handlers.GetNotificationAttemptsHandler)
+		api.GET("/email/outbox", handlers.GetEmailOutboxHandler)
+
+		// Status page routes
+		api.GET("/status-page/components", handlers.GetStatusComponentsHandler)
+		api.POST("/status-page/components", handlers.CreateStatusComponentHandler)
+		api.PUT("/status-page/components/:componentId", handlers.UpdateStatusComponentHandler)
+		api.DELETE("/status-page/components/:componentId", handlers.DeleteStatusComponentHandler)
+		api.PUT("/status-page/components/:componentId/override", handlers.SetStatusComponentOverrideHandler)
+		api.DELETE("/status-page/components/:componentId/override", handlers.ClearStatusComponentOverrideHandler)
+		api.GET("/status-page/posts", handlers.GetStatusPostsHandler)
+		api.POST("/status-page/posts", handlers.CreateStatusPostHandler)
+		api.GET("/status-page/posts/:postId", handlers.GetStatusPostHandler)
+		api.DELETE("/status-page/posts/:postId", handlers.DeleteStatusPostHandler)
+		api.POST("/status-page/posts/:postId/updates", handlers.AddStatusPostUpdateHandler)
+
+		// Public status page routes (read-only)
+		api.GET("/public/status", handlers.GetPublicStatusHandler)
+		api.GET("/public/status/posts/:postId", handlers.GetPublicStatusPostHandler)
+		api.GET("/public/status/feed.atom", handlers.GetStatusAtomFeedHandler)
+		api.GET("/public/status/feed.rss", handlers.GetStatusRSSFeedHandler)
 
 		// AI Agent routes
 		api.POST("/incidents/:id/agent/remediate", handlers.StartAgentRemediationHandler)
 		api.GET("/incidents/:id/agent/executions", handlers.GetIncidentAgentExecutionsHandler)
+		api.GET("/agent/actions", handlers.GetAgentActionsHandler)
+		api.GET("/agent/guards", handlers.GetAgentGuardsHandler)
+		api.POST("/agent/breaker/reset", handlers.ResetAgentBreakerHandler)
 		api.GET("/agent/executions/:executionId", handlers.GetAgentExecutionHandler)
+		api.PUT("/agent/executions/:executionId/plan", handlers.EditAgentPlanHandler)
 		api.POST("/agent/executions/:executionId/approve", handlers.ApproveAgentExecutionHandler)
 		api.POST("/agent/executions/:executionId/reject", handlers.RejectAgentExecutionHandler)
+		api.POST("/agent/executions/:executionId/cancel", handlers.CancelAgentExecutionHandler)
+		api.POST("/agent/executions/:executionId/rollback/approve", handlers.ApproveAgentRollbackHandler)
+		api.POST("/agent/executions/:executionId/rollback/decline", handlers.DeclineAgentRollbackHandler)
+
+		// Chat-ops approval links (signed, opened from chat messages)
+		api.GET("/chatops/executions/:executionId/:action", handlers.ChatOpsConfirmHandler)
+		api.POST("/chatops/executions/:executionId/:action", handlers.ChatOpsDecisionHandler)
+		api.POST("/chatops/shared/executions/:executionId/:action", handlers.ChatOpsSharedDecisionHandler)
 
 		// Database reset broadcast
 		api.POST("/reset", handlers.ResetDatabaseHandler)
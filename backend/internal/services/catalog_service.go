@@ -0,0 +1,346 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// defaultServiceCatalog seeds the catalog with every system the platform knows about.
+// Names match agent.ActionableSystems so incidents and agent actions line up with catalog entries.
+var defaultServiceCatalog = []models.Service{
+	{Name: "redis-test", DisplayName: "Redis", Description: "Mock Redis instance used for sessions and caching", Owner: "Platform", Tier: 2,
+		Runbooks: pq.StringArray{"redis-memory-pressure"}},
+	{Name: "postgres-test", DisplayName: "PostgreSQL", Description: "Mock PostgreSQL instance backing core services", Owner: "Data", Tier: 1,
+		Runbooks: pq.StringArray{"postgres-connection-exhaustion", "postgres-table-bloat"}},
+	{Name: "disk-monitor", DisplayName: "Disk", Description: "Shared log volume", Owner: "Infrastructure", Tier: 3,
+		Runbooks: pq.StringArray{"disk-space-low"}},
+	{Name: "health-monitor", DisplayName: "Health Monitor", Description: "System health monitoring service", Owner: "Platform", Tier: 3},
+	{Name: "user-service", DisplayName: "User Service", Description: "Accounts, authentication and sessions", Owner: "Backend", Tier: 1,
+		DependsOn: pq.StringArray{"postgres-test", "redis-test"}},
+	{Name: "payment-gateway", DisplayName: "Payment Gateway", Description: "Card processing provider integration", Owner: "Backend", Tier: 1},
+	{Name: "email-relay-service", DisplayName: "Email Relay", Description: "Outbound transactional email", Owner: "Platform", Tier: 3,
+		DependsOn: pq.StringArray{"user-service"}},
+	{Name: "billing-service", DisplayName: "Billing Service", Description: "Invoicing and subscriptions", Owner: "Backend", Tier: 1,
+		DependsOn: pq.StringArray{"postgres-test", "payment-gateway", "email-relay-service"}},
+	{Name: "api-gateway", DisplayName: "API Gateway", Description: "Public API entry point", Owner: "Platform", Tier: 1,
+		DependsOn: pq.StringArray{"user-service", "billing-service", "redis-test"}},
+	{Name: "edge-cdn", DisplayName: "Edge CDN", Description: "Edge caching in front of the API", Owner: "Infrastructure", Tier: 2,
+		DependsOn: pq.StringArray{"api-gateway"}},
+	{Name: "cloudflare-cdn", DisplayName: "Cloudflare CDN", Description: "Static asset delivery", Owner: "Frontend", Tier: 2,
+		DependsOn: pq.StringArray{"api-gateway"}},
+	{Name: "data-pipeline-processor", DisplayName: "Data Pipeline Processor", Description: "Event ingestion and transformation", Owner: "Data", Tier: 2,
+		DependsOn: pq.StringArray{"postgres-test"}},
+	{Name: "analytics-data-pipeline", DisplayName: "Analytics Pipeline", Description: "Reporting and analytics exports", Owner: "Data", Tier: 3,
+		DependsOn: pq.StringArray{"data-pipeline-processor"}},
+}
+
+// SeedServiceCatalog inserts the default catalog entries that don't exist yet.
+// Existing entries are left untouched so edits made through the API survive restarts.
+func SeedServiceCatalog() error {
+	for _, svc := range defaultServiceCatalog {
+		svc := svc
+		if svc.DependsOn == nil {
+			svc.DependsOn = pq.StringArray{}
+		}
+		if svc.Runbooks == nil {
+			svc.Runbooks = pq.StringArray{}
+		}
+		if err := db.DB.Where(models.Service{Name: svc.Name}).FirstOrCreate(&svc).Error; err != nil {
+			return fmt.Errorf("failed to seed service %s: %w", svc.Name, err)
+		}
+	}
+	log.Printf("📚 Service catalog seeded (%d default services)", len(defaultServiceCatalog))
+	return nil
+}
+
+func GetAllServices() ([]models.Service, error) {
+	var services []models.Service
+	err := db.DB.Order("tier ASC, name ASC").Find(&services).Error
+	return services, err
+}
+
+func GetServiceByName(name string) (models.Service, error) {
+	var service models.Service
+	err := db.DB.First(&service, "name = ?", name).Error
+	return service, err
+}
+
+// ErrServiceExists is returned when creating a service whose name is already in the catalog
+var ErrServiceExists = errors.New("service already exists")
+
+// CreateService adds a new catalog entry after validating its dependencies. It never replaces an
+// existing entry, even one created concurrently between the handler's check and the insert.
+func CreateService(service *models.Service) error {
+	if err := validateService(service); err != nil {
+		return err
+	}
+	result := db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(service)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrServiceExists, service.Name)
+	}
+
+	log.Printf("✅ Created service %s (depends on %v)", service.Name, []string(service.DependsOn))
+	return nil
+}
+
+// SaveService creates or replaces a catalog entry after validating its dependencies
+func SaveService(service *models.Service) error {
+	if err := validateService(service); err != nil {
+		return err
+	}
+	if err := db.DB.Save(service).Error; err != nil {
+		return err
+	}
+
+	log.Printf("✅ Saved service %s (depends on %v)", service.Name, []string(service.DependsOn))
+	return nil
+}
+
+// validateService fills in empty lists and checks the service's dependencies exist and form no cycle
+func validateService(service *models.Service) error {
+	if service.Name == "" {
+		return fmt.Errorf("service name is required")
+	}
+	if service.DependsOn == nil {
+		service.DependsOn = pq.StringArray{}
+	}
+	if service.Runbooks == nil {
+		service.Runbooks = pq.StringArray{}
+	}
+
+	catalog, err := GetAllServices()
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]models.Service, len(catalog)+1)
+	for _, svc := range catalog {
+		byName[svc.Name] = svc
+	}
+	byName[service.Name] = *service
+
+	for _, dep := range service.DependsOn {
+		if dep == service.Name {
+			return fmt.Errorf("service %s cannot depend on itself", service.Name)
+		}
+		if _, exists := byName[dep]; !exists {
+			return fmt.Errorf("unknown dependency: %s", dep)
+		}
+	}
+
+	if cycle := findDependencyCycle(service.Name, byName); cycle != nil {
+		return fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " → "))
+	}
+	return nil
+}
+
+// DeleteService removes a service and any dependency edges pointing at it
+func DeleteService(name string) error {
+	tx := db.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Exec(`UPDATE services SET depends_on = array_remove(depends_on, ?) WHERE ? = ANY(depends_on)`, name, name).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to remove dependency edges: %w", err)
+	}
+
+	result := tx.Delete(&models.Service{}, "name = ?", name)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return fmt.Errorf("service not found: %s", name)
+	}
+
+	return tx.Commit().Error
+}
+
+// ComputeBlastRadius walks the dependency graph from the given systems and returns every transitive dependent
+func ComputeBlastRadius(systems []string) (models.BlastRadius, error) {
+	catalog, err := GetAllServices()
+	if err != nil {
+		return models.BlastRadius{}, err
+	}
+	return blastRadius(systems, catalog), nil
+}
+
+// ComputeIncidentBlastRadius returns the blast radius for an incident's AffectedSystems
+func ComputeIncidentBlastRadius(incident *models.Incident) (models.BlastRadius, error) {
+	return ComputeBlastRadius(incidentSystems(incident))
+}
+
+// incidentSystems returns the systems an incident is about
+func incidentSystems(incident *models.Incident) []string {
+	systems := []string(incident.AffectedSystems)
+	// Fall back to the legacy single-system field and the source for older incidents
+	if len(systems) == 0 && incident.AffectedSystem != "" {
+		systems = []string{incident.AffectedSystem}
+	}
+	if len(systems) == 0 && incident.Source != "" {
+		systems = []string{incident.Source}
+	}
+	return systems
+}
+
+// blastRadius is a breadth-first walk over reverse dependency edges
+func blastRadius(systems []string, catalog []models.Service) models.BlastRadius {
+	byName := make(map[string]models.Service, len(catalog))
+	dependents := make(map[string][]string)
+	for _, svc := range catalog {
+		byName[svc.Name] = svc
+		for _, dep := range svc.DependsOn {
+			dependents[dep] = append(dependents[dep], svc.Name)
+		}
+	}
+
+	result := models.BlastRadius{
+		AffectedSystems: systems,
+		Impacted:        []models.ImpactedService{},
+	}
+
+	visited := make(map[string]bool)
+	var queue []models.ImpactedService
+	for _, sys := range systems {
+		if _, exists := byName[sys]; !exists {
+			result.UnknownSystems = append(result.UnknownSystems, sys)
+			continue
+		}
+		if !visited[sys] {
+			visited[sys] = true
+			queue = append(queue, models.ImpactedService{Name: sys, Path: []string{sys}})
+		}
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		next := dependents[current.Name]
+		sort.Strings(next)
+		for _, name := range next {
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+
+			svc := byName[name]
+			path := append(append([]string{}, current.Path...), name)
+			impacted := models.ImpactedService{
+				Name:  name,
+				Owner: svc.Owner,
+				Tier:  svc.Tier,
+				Depth: current.Depth + 1,
+				Path:  path,
+			}
+			result.Impacted = append(result.Impacted, impacted)
+			queue = append(queue, impacted)
+		}
+	}
+
+	return result
+}
+
+// findDependencyCycle returns the cycle reachable from start, or nil if there is none
+func findDependencyCycle(start string, byName map[string]models.Service) []string {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int)
+	var stack []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = inProgress
+		stack = append(stack, name)
+		for _, dep := range byName[name].DependsOn {
+			switch state[dep] {
+			case inProgress:
+				for i, n := range stack {
+					if n == dep {
+						return append(append([]string{}, stack[i:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = done
+		return nil
+	}
+
+	return visit(start)
+}
+
+// ServiceGraph is the JSON export of the catalog dependency graph
+type ServiceGraph struct {
+	Nodes []models.Service `json:"nodes"`
+	Edges []ServiceEdge    `json:"edges"`
+}
+
+// ServiceEdge points from a service to an upstream it depends on
+type ServiceEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// GetServiceGraph exports the catalog as nodes and dependency edges
+func GetServiceGraph() (ServiceGraph, error) {
+	catalog, err := GetAllServices()
+	if err != nil {
+		return ServiceGraph{}, err
+	}
+
+	graph := ServiceGraph{Nodes: catalog, Edges: []ServiceEdge{}}
+	for _, svc := range catalog {
+		for _, dep := range svc.DependsOn {
+			graph.Edges = append(graph.Edges, ServiceEdge{From: svc.Name, To: dep})
+		}
+	}
+	return graph, nil
+}
+
+// RenderServiceGraphDOT renders the graph in Graphviz DOT format
+func RenderServiceGraphDOT(graph ServiceGraph) string {
+	var b strings.Builder
+	b.WriteString("digraph services {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+	for _, svc := range graph.Nodes {
+		label := svc.Name
+		if svc.DisplayName != "" {
+			label = svc.DisplayName
+		}
+		fmt.Fprintf(&b, "  \"%s\" [label=\"%s\\nTier %d · %s\"];\n",
+			dotEscape(svc.Name), dotEscape(label), svc.Tier, dotEscape(svc.Owner))
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&b, "  \"%s\" -> \"%s\";\n", dotEscape(edge.From), dotEscape(edge.To))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
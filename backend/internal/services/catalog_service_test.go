@@ -0,0 +1,135 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+func service(name string, dependsOn ...string) models.Service {
+	return models.Service{Name: name, Owner: "owner-" + name, Tier: 2, DependsOn: pq.StringArray(dependsOn)}
+}
+
+func impactedSummary(impacted []models.ImpactedService) []string {
+	summary := make([]string, len(impacted))
+	for i, svc := range impacted {
+		summary[i] = strings.Join(svc.Path, ">")
+	}
+	return summary
+}
+
+func TestBlastRadius(t *testing.T) {
+	// api and cache depend on db, and web on both of them
+	diamond := []models.Service{
+		service("db"),
+		service("api", "db"),
+		service("cache", "db"),
+		service("web", "api", "cache"),
+		service("reports"),
+	}
+
+	tests := []struct {
+		name        string
+		systems     []string
+		catalog     []models.Service
+		wantPaths   []string // Path of each impacted service, in order
+		wantUnknown []string
+	}{
+		{
+			name:      "diamond reaches each dependent once by its shortest path",
+			systems:   []string{"db"},
+			catalog:   diamond,
+			wantPaths: []string{"db>api", "db>cache", "db>api>web"},
+		},
+		{
+			name:      "leaf has no dependents",
+			systems:   []string{"web"},
+			catalog:   diamond,
+			wantPaths: []string{},
+		},
+		{
+			name:      "several failed systems are not listed as impacted",
+			systems:   []string{"api", "db"},
+			catalog:   diamond,
+			wantPaths: []string{"api>web", "db>cache"},
+		},
+		{
+			name:        "unknown systems are reported",
+			systems:     []string{"mainframe", "reports"},
+			catalog:     diamond,
+			wantPaths:   []string{},
+			wantUnknown: []string{"mainframe"},
+		},
+		{
+			name:      "default catalog",
+			systems:   []string{"redis-test"},
+			catalog:   defaultServiceCatalog,
+			wantPaths: []string{"redis-test>api-gateway", "redis-test>user-service", "redis-test>api-gateway>cloudflare-cdn", "redis-test>api-gateway>edge-cdn", "redis-test>user-service>email-relay-service", "redis-test>user-service>email-relay-service>billing-service"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := blastRadius(tt.systems, tt.catalog)
+			if got := impactedSummary(result.Impacted); !reflect.DeepEqual(got, tt.wantPaths) {
+				t.Errorf("impacted = %v, want %v", got, tt.wantPaths)
+			}
+			if !reflect.DeepEqual(result.UnknownSystems, tt.wantUnknown) {
+				t.Errorf("unknown = %v, want %v", result.UnknownSystems, tt.wantUnknown)
+			}
+			for _, svc := range result.Impacted {
+				if svc.Depth != len(svc.Path)-1 {
+					t.Errorf("%s: depth %d does not match path %v", svc.Name, svc.Depth, svc.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestBlastRadiusCarriesOwnerAndTier(t *testing.T) {
+	result := blastRadius([]string{"db"}, []models.Service{service("db"), {Name: "api", Owner: "Backend", Tier: 1, DependsOn: pq.StringArray{"db"}}})
+	if len(result.Impacted) != 1 || result.Impacted[0].Owner != "Backend" || result.Impacted[0].Tier != 1 {
+		t.Errorf("impacted = %+v, want api owned by Backend at tier 1", result.Impacted)
+	}
+}
+
+func TestFindDependencyCycle(t *testing.T) {
+	byName := func(services ...models.Service) map[string]models.Service {
+		m := make(map[string]models.Service, len(services))
+		for _, svc := range services {
+			m[svc.Name] = svc
+		}
+		return m
+	}
+
+	tests := []struct {
+		name    string
+		start   string
+		catalog map[string]models.Service
+		want    []string
+	}{
+		{"no dependencies", "a", byName(service("a")), nil},
+		{"chain", "a", byName(service("a", "b"), service("b", "c"), service("c")), nil},
+		{"shared dependency is not a cycle", "a", byName(service("a", "b", "c"), service("b", "d"), service("c", "d"), service("d")), nil},
+		{"self dependency", "a", byName(service("a", "a")), []string{"a", "a"}},
+		{"cycle through start", "a", byName(service("a", "b"), service("b", "c"), service("c", "a")), []string{"a", "b", "c", "a"}},
+		{"cycle further down", "a", byName(service("a", "b"), service("b", "c"), service("c", "b")), []string{"b", "c", "b"}},
+		{"unknown dependency", "a", byName(service("a", "ghost")), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findDependencyCycle(tt.start, tt.catalog); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findDependencyCycle = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	catalog := byName(defaultServiceCatalog...)
+	for name := range catalog {
+		if cycle := findDependencyCycle(name, catalog); cycle != nil {
+			t.Errorf("default catalog has a cycle from %s: %v", name, cycle)
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Kinds of message the shared channel always gets, whatever users' notification rules say
+const (
+	ChatKindHighSeverity    = "high_severity_incident"
+	ChatKindApprovalRequest = "approval_request"
+)
+
+// Approval link actions
+const (
+	ChatActionApprove = "approve"
+	ChatActionReject  = "reject"
+)
+
+var (
+	ErrApprovalLinkInvalid = errors.New("approval link signature is invalid")
+	ErrApprovalLinkExpired = errors.New("approval link has expired")
+)
+
+var (
+	chatOpsClient = &http.Client{Timeout: 10 * time.Second}
+
+	chatOpsSecretOnce sync.Once
+	chatOpsSecret     []byte
+)
+
+// ChatOpsEnabled reports whether a Slack-compatible incoming webhook is configured
+func ChatOpsEnabled() bool {
+	return os.Getenv("CHATOPS_WEBHOOK_URL") != ""
+}
+
+// chatOpsSigningSecret returns the key used to sign approval links.
+// Without CHATOPS_SIGNING_SECRET a random key is used, so links stop working after a restart.
+func chatOpsSigningSecret() []byte {
+	chatOpsSecretOnce.Do(func() {
+		if secret := os.Getenv("CHATOPS_SIGNING_SECRET"); secret != "" {
+			chatOpsSecret = []byte(secret)
+			return
+		}
+		chatOpsSecret = make([]byte, 32)
+		rand.Read(chatOpsSecret)
+		log.Println("⚠️  CHATOPS_SIGNING_SECRET not set - approval links will not survive a restart")
+	})
+	return chatOpsSecret
+}
+
+func chatOpsLinkTTL() time.Duration {
+	if v, err := strconv.Atoi(os.Getenv("CHATOPS_LINK_TTL_MINUTES")); err == nil && v > 0 {
+		return time.Duration(v) * time.Minute
+	}
+	return 30 * time.Minute
+}
+
+// publicBaseURL is where people clicking links from chat can reach the backend
+func publicBaseURL() string {
+	if base := os.Getenv("PUBLIC_BASE_URL"); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	return "http://localhost:8080"
+}
+
+func frontendBaseURL() string {
+	if base := os.Getenv("FRONTEND_URL"); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	return "http://localhost:3000"
+}
+
+// approvalLinkSignature signs the approver last: the fields before it never contain ':', so no two
+// links sign the same string
+func approvalLinkSignature(executionID uuid.UUID, action, approver string, expires int64) string {
+	mac := hmac.New(sha256.New, chatOpsSigningSecret())
+	fmt.Fprintf(mac, "%s:%s:%d:%s", executionID, action, expires, approver)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ApprovalLinkURL returns a signed, expiring link that approves or rejects an execution as approver.
+// The link is only as private as the place it is sent: it must go to approver alone (their email or
+// personal chat webhook), since whoever holds it decides under that name. The approver is part of the
+// signature, so it cannot be edited into someone else's name to satisfy the two-person rule.
+func ApprovalLinkURL(executionID uuid.UUID, action, approver string) string {
+	expires := time.Now().Add(chatOpsLinkTTL()).Unix()
+	query := url.Values{}
+	query.Set("approver", approver)
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("sig", approvalLinkSignature(executionID, action, approver, expires))
+	return fmt.Sprintf("%s/api/v1/chatops/executions/%s/%s?%s", publicBaseURL(), executionID, action, query.Encode())
+}
+
+// SharedApprovalLinkURL returns a signed, expiring approve or reject link for the shared channel. Anyone
+// there can click it, so it names no approver: it opens the dashboard, which asks the clicker to confirm
+// under the name of their dashboard session. Personal links cannot be turned into shared ones or back,
+// since the approver (empty here) is part of the signature.
+func SharedApprovalLinkURL(executionID uuid.UUID, action string) string {
+	expires := time.Now().Add(chatOpsLinkTTL()).Unix()
+	query := url.Values{}
+	query.Set("chatops_execution", executionID.String())
+	query.Set("chatops_action", action)
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("sig", approvalLinkSignature(executionID, action, "", expires))
+	return fmt.Sprintf("%s/?%s", frontendBaseURL(), query.Encode())
+}
+
+// VerifyApprovalLink checks the signature and expiry of a personal approval link
+func VerifyApprovalLink(executionID uuid.UUID, action, approver, expires, sig string) error {
+	if strings.TrimSpace(approver) == "" {
+		return ErrApprovalLinkInvalid
+	}
+	return verifyApprovalLink(executionID, action, approver, expires, sig)
+}
+
+// VerifySharedApprovalLink checks the signature and expiry of a link from SharedApprovalLinkURL
+func VerifySharedApprovalLink(executionID uuid.UUID, action, expires, sig string) error {
+	return verifyApprovalLink(executionID, action, "", expires, sig)
+}
+
+func verifyApprovalLink(executionID uuid.UUID, action, approver, expires, sig string) error {
+	if action != ChatActionApprove && action != ChatActionReject {
+		return ErrApprovalLinkInvalid
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrApprovalLinkInvalid
+	}
+	expected := approvalLinkSignature(executionID, action, approver, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(sig)) {
+		return ErrApprovalLinkInvalid
+	}
+	if time.Now().Unix() > expiresAt {
+		return ErrApprovalLinkExpired
+	}
+	return nil
+}
+
+// NotifyChatOps is an events.Handler that posts new high-severity incidents and approval requests to the
+// shared channel. It is the channel's default route: these are posted once per incident or execution even
+// when no user has a rule routing them there.
+func NotifyChatOps(event events.Event) {
+	n, ok := describeEvent(event)
+	if !ok {
+		return
+	}
+	kind, subjectID, message, ok := defaultChatPost(n)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	record := models.ChatNotification{Kind: kind, SubjectID: subjectID, IncidentID: n.Incident.ID, SentAt: now}
+	post := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		claimed, err := claimSharedChatPost(tx, &record)
+		if err != nil || !claimed {
+			return err
+		}
+		// Claim the event too, so a routed delivery of this same event doesn't post it again
+		post, err = claimSharedChatPost(tx, &models.ChatNotification{
+			Kind: n.Event.Type, SubjectID: n.Event.ID, IncidentID: n.Incident.ID, SentAt: now,
+		})
+		return err
+	})
+	if err != nil {
+		log.Printf("❌ Chat-ops: failed to record %s notification: %v", kind, err)
+		return
+	}
+	if post {
+		sendSharedChatMessage(&record, message)
+	}
+}
+
+// defaultChatPost decides whether the default route posts an event: an unresolved high-severity incident
+// that is not flapping (paging the channel on every flap is the noise flap detection exists to stop), or
+// an execution awaiting approval
+func defaultChatPost(n *notification) (kind string, subjectID uuid.UUID, message map[string]interface{}, ok bool) {
+	switch n.Event.Type {
+	case events.IncidentCreated, events.IncidentUpdated:
+		if n.Severity != "high" || n.Incident.Status == "resolved" || n.Flapping {
+			return "", uuid.Nil, nil, false
+		}
+		return ChatKindHighSeverity, n.Incident.ID, buildHighSeverityChatMessage(n.Incident), true
+
+	case events.AgentAwaitingApproval:
+		execution, isExecution := n.Event.Data.(models.AgentExecution)
+		if !isExecution {
+			return "", uuid.Nil, nil, false
+		}
+		return ChatKindApprovalRequest, execution.ID, buildApprovalChatMessage(&execution, n.Incident, ""), true
+	}
+	return "", uuid.Nil, nil, false
+}
+
+// postSharedChatMessage posts a routed notification to the shared channel. Several users' rules may route
+// the same event there, and the default route may already have posted it, so it is posted once per event
+// and later deliveries report "already_posted".
+func postSharedChatMessage(n *notification, message map[string]interface{}) (reason string, err error) {
+	record := models.ChatNotification{
+		Kind:       n.Event.Type,
+		SubjectID:  n.Event.ID,
+		IncidentID: n.Incident.ID,
+		SentAt:     time.Now(),
+	}
+	claimed, err := claimSharedChatPost(db.DB, &record)
+	if err != nil {
+		return "", err
+	}
+	if !claimed {
+		return "already_posted", nil
+	}
+	return "", sendSharedChatMessage(&record, message)
+}
+
+// claimSharedChatPost records that a message is about to be posted, returning false if it already was
+func claimSharedChatPost(tx *gorm.DB, record *models.ChatNotification) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to record chat notification: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// sendSharedChatMessage posts a claimed message to the shared channel and records the outcome on its claim
+func sendSharedChatMessage(record *models.ChatNotification, message map[string]interface{}) error {
+	body, _ := json.Marshal(message)
+	var err error
+	record.StatusCode, err = postChatMessage(os.Getenv("CHATOPS_WEBHOOK_URL"), body)
+	if err != nil {
+		record.Error = err.Error()
+		log.Printf("❌ Chat-ops: failed to post %s for %s: %v", record.Kind, record.IncidentID.String()[:8], err)
+	} else {
+		log.Printf("💬 Chat-ops: posted %s for %s", record.Kind, record.IncidentID.String()[:8])
+	}
+	db.DB.Model(record).Select("status_code", "error").Updates(record)
+	return err
+}
+
+func postChatMessage(webhookURL string, body []byte) (int, error) {
+	resp, err := chatOpsClient.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("chat webhook returned HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// buildHighSeverityChatMessage renders a Block Kit message for a new high-severity incident
+func buildHighSeverityChatMessage(incident *models.Incident) map[string]interface{} {
+	summary := fmt.Sprintf("🚨 High severity incident: %s", truncateText(incident.Message, 150))
+	systems := "unknown"
+	if len(incident.AffectedSystems) > 0 {
+		systems = strings.Join(incident.AffectedSystems, ", ")
+	}
+
+	return map[string]interface{}{
+		"text": summary,
+		"blocks": []interface{}{
+			chatHeader("🚨 High severity incident"),
+			chatSection(truncateText(incident.Message, 2900)),
+			chatFields(
+				"*Team*", incident.Team,
+				"*Status*", incident.Status,
+				"*Source*", incident.Source,
+				"*Affected systems*", systems,
+			),
+			chatActions(
+				chatButton("Open dashboard", frontendBaseURL(), ""),
+			),
+			chatContext(fmt.Sprintf("Incident %s", incident.ID)),
+		},
+	}
+}
+
+// buildApprovalChatMessage renders a Block Kit message for approver. A message for the shared channel
+// (approver "") gets shared links instead, which decide as whoever confirms them in the dashboard.
+func buildApprovalChatMessage(execution *models.AgentExecution, incident *models.Incident, approver string) map[string]interface{} {
+	summary := fmt.Sprintf("🤖 Agent wants to run %s for: %s", execution.RecommendedAction, truncateText(incident.Message, 150))
+
+	var commands []models.Command
+	raw, _ := json.Marshal(execution.Commands.Data)
+	json.Unmarshal(raw, &commands)
+	var commandLines []string
+	for _, cmd := range commands {
+		commandLines = append(commandLines, fmt.Sprintf("• `%s` on %s", cmd.Command, cmd.Target))
+	}
+	if len(commandLines) == 0 {
+		commandLines = []string{"_No commands_"}
+	}
+
+	actions := chatActions(
+		chatButton("Approve", SharedApprovalLinkURL(execution.ID, ChatActionApprove), "primary"),
+		chatButton("Reject", SharedApprovalLinkURL(execution.ID, ChatActionReject), "danger"),
+	)
+	context := fmt.Sprintf("Links open the dashboard to confirm under your name and expire in %s · Execution %s", chatOpsLinkTTL(), execution.ID)
+	if approver != "" {
+		actions = chatActions(
+			chatButton("Approve", ApprovalLinkURL(execution.ID, ChatActionApprove, approver), "primary"),
+			chatButton("Reject", ApprovalLinkURL(execution.ID, ChatActionReject, approver), "danger"),
+		)
+		context = fmt.Sprintf("Links decide as %s and expire in %s · Execution %s", approver, chatOpsLinkTTL(), execution.ID)
+	}
+
+	return map[string]interface{}{
+		"text": summary,
+		"blocks": []interface{}{
+			chatHeader("🤖 Agent action awaiting approval"),
+			chatSection(fmt.Sprintf("*Incident:* %s", truncateText(incident.Message, 1000))),
+			chatFields(
+				"*Action*", execution.RecommendedAction,
+				"*Team*", incident.Team,
+			),
+			chatSection(fmt.Sprintf("*Reasoning:* %s", truncateText(execution.Reasoning, 1500))),
+			chatSection(fmt.Sprintf("*Commands:*\n%s", truncateText(strings.Join(commandLines, "\n"), 1500))),
+			actions,
+			chatContext(context),
+		},
+	}
+}
+
+func chatHeader(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "header",
+		"text": map[string]interface{}{"type": "plain_text", "text": truncateText(text, 150), "emoji": true},
+	}
+}
+
+func chatSection(markdown string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "section",
+		"text": map[string]interface{}{"type": "mrkdwn", "text": markdown},
+	}
+}
+
+// chatFields builds a section of label/value pairs
+func chatFields(pairs ...string) map[string]interface{} {
+	var fields []interface{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		value := pairs[i+1]
+		if value == "" {
+			value = "-"
+		}
+		fields = append(fields, map[string]interface{}{"type": "mrkdwn", "text": pairs[i] + "\n" + value})
+	}
+	return map[string]interface{}{"type": "section", "fields": fields}
+}
+
+func chatActions(elements ...interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "actions", "elements": elements}
+}
+
+// chatButton is a link button; style is "", "primary" or "danger"
+func chatButton(text, link, style string) map[string]interface{} {
+	button := map[string]interface{}{
+		"type": "button",
+		"text": map[string]interface{}{"type": "plain_text", "text": text},
+		"url":  link,
+	}
+	if style != "" {
+		button["style"] = style
+	}
+	return button
+}
+
+func chatContext(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "context",
+		"elements": []interface{}{map[string]interface{}{"type": "mrkdwn", "text": text}},
+	}
+}
+
+func truncateText(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// parseApprovalLink splits a link from ApprovalLinkURL into what the handler reads from it
+func parseApprovalLink(t *testing.T, link string) (action string, query url.Values) {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("invalid link %q: %v", link, err)
+	}
+	parts := strings.Split(u.Path, "/")
+	return parts[len(parts)-1], u.Query()
+}
+
+func TestApprovalLinkIsSignedForItsApprover(t *testing.T) {
+	executionID := uuid.New()
+	action, query := parseApprovalLink(t, ApprovalLinkURL(executionID, ChatActionApprove, "alice"))
+	verify := func(id uuid.UUID, action, approver string) error {
+		return VerifyApprovalLink(id, action, approver, query.Get("expires"), query.Get("sig"))
+	}
+
+	if query.Get("approver") != "alice" {
+		t.Fatalf("approver = %q, want alice", query.Get("approver"))
+	}
+	if err := verify(executionID, action, "alice"); err != nil {
+		t.Fatalf("VerifyApprovalLink rejected an untouched link: %v", err)
+	}
+
+	tampered := []struct {
+		name     string
+		id       uuid.UUID
+		action   string
+		approver string
+	}{
+		{"another approver", executionID, action, "bob"},
+		{"same name in another case", executionID, action, "Alice"},
+		{"approver removed", executionID, action, ""},
+		{"approve turned into reject", executionID, ChatActionReject, "alice"},
+		{"another execution", uuid.New(), action, "alice"},
+	}
+	for _, tt := range tampered {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verify(tt.id, tt.action, tt.approver); !errors.Is(err, ErrApprovalLinkInvalid) {
+				t.Errorf("VerifyApprovalLink = %v, want ErrApprovalLinkInvalid", err)
+			}
+		})
+	}
+}
+
+func TestApprovalLinkExpiry(t *testing.T) {
+	executionID := uuid.New()
+	expires := "1700000000" // In the past
+	sig := approvalLinkSignature(executionID, ChatActionApprove, "alice", 1700000000)
+	if err := VerifyApprovalLink(executionID, ChatActionApprove, "alice", expires, sig); !errors.Is(err, ErrApprovalLinkExpired) {
+		t.Errorf("VerifyApprovalLink = %v, want ErrApprovalLinkExpired", err)
+	}
+}
+
+func TestApprovalChatMessageLinks(t *testing.T) {
+	execution := &models.AgentExecution{ID: uuid.New(), RecommendedAction: "restart_redis"}
+	incident := &models.Incident{ID: uuid.New(), Message: "Redis is down"}
+
+	links := func(message map[string]interface{}) []string {
+		var urls []string
+		for _, block := range message["blocks"].([]interface{}) {
+			elements, _ := block.(map[string]interface{})["elements"].([]interface{})
+			for _, element := range elements {
+				if link, ok := element.(map[string]interface{})["url"].(string); ok {
+					urls = append(urls, link)
+				}
+			}
+		}
+		return urls
+	}
+
+	personal := links(buildApprovalChatMessage(execution, incident, "alice"))
+	if len(personal) != 2 {
+		t.Fatalf("personal message links = %v, want approve and reject", personal)
+	}
+	for _, link := range personal {
+		if _, query := parseApprovalLink(t, link); query.Get("approver") != "alice" {
+			t.Errorf("link %s is not signed for alice", link)
+		}
+	}
+
+	shared := links(buildApprovalChatMessage(execution, incident, ""))
+	if len(shared) != 2 {
+		t.Fatalf("shared message links = %v, want approve and reject", shared)
+	}
+	for _, link := range shared {
+		if strings.Contains(link, "/chatops/") {
+			t.Errorf("shared channel message decides without the dashboard: %s", link)
+		}
+		parsed, err := url.Parse(link)
+		if err != nil {
+			t.Fatalf("parse %q: %v", link, err)
+		}
+		query := parsed.Query()
+		if query.Get("chatops_execution") != execution.ID.String() {
+			t.Errorf("link %s does not open execution %s", link, execution.ID)
+		}
+		if err := VerifySharedApprovalLink(execution.ID, query.Get("chatops_action"), query.Get("expires"), query.Get("sig")); err != nil {
+			t.Errorf("VerifySharedApprovalLink(%s) = %v", link, err)
+		}
+	}
+}
+
+func TestSharedAndPersonalLinksAreNotInterchangeable(t *testing.T) {
+	executionID := uuid.New()
+
+	_, personal := parseApprovalLink(t, ApprovalLinkURL(executionID, ChatActionApprove, "alice"))
+	if err := VerifySharedApprovalLink(executionID, ChatActionApprove, personal.Get("expires"), personal.Get("sig")); !errors.Is(err, ErrApprovalLinkInvalid) {
+		t.Errorf("personal link accepted as shared: %v", err)
+	}
+
+	parsed, err := url.Parse(SharedApprovalLinkURL(executionID, ChatActionApprove))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := parsed.Query()
+	if err := VerifyApprovalLink(executionID, ChatActionApprove, "alice", shared.Get("expires"), shared.Get("sig")); !errors.Is(err, ErrApprovalLinkInvalid) {
+		t.Errorf("shared link accepted for alice: %v", err)
+	}
+	if err := VerifySharedApprovalLink(executionID, ChatActionReject, shared.Get("expires"), shared.Get("sig")); !errors.Is(err, ErrApprovalLinkInvalid) {
+		t.Errorf("approve link accepted as reject: %v", err)
+	}
+}
+
+func TestDefaultChatPost(t *testing.T) {
+	high := models.Incident{ID: uuid.New(), Message: "Redis is down", Status: "triage"}
+	execution := models.AgentExecution{ID: uuid.New(), IncidentID: high.ID, RecommendedAction: "restart_redis"}
+	notificationFor := func(eventType string, data interface{}, incident models.Incident, severity string, flapping bool) *notification {
+		return &notification{Event: events.New(eventType, data), Incident: &incident, Severity: severity, Flapping: flapping}
+	}
+	resolved := high
+	resolved.Status = "resolved"
+
+	tests := []struct {
+		name        string
+		n           *notification
+		wantKind    string
+		wantSubject uuid.UUID
+	}{
+		{"new high severity incident", notificationFor(events.IncidentCreated, high, high, "high", false), ChatKindHighSeverity, high.ID},
+		{"incident triaged as high", notificationFor(events.IncidentUpdated, high, high, "high", false), ChatKindHighSeverity, high.ID},
+		{"approval request", notificationFor(events.AgentAwaitingApproval, execution, high, "low", false), ChatKindApprovalRequest, execution.ID},
+		{"medium severity", notificationFor(events.IncidentCreated, high, high, "medium", false), "", uuid.Nil},
+		{"not triaged yet", notificationFor(events.IncidentCreated, high, high, "", false), "", uuid.Nil},
+		{"resolved", notificationFor(events.IncidentUpdated, resolved, resolved, "high", false), "", uuid.Nil},
+		{"flapping", notificationFor(events.IncidentUpdated, high, high, "high", true), "", uuid.Nil},
+		{"other event", notificationFor(events.IncidentAssigned, high, high, "high", false), "", uuid.Nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, subject, message, ok := defaultChatPost(tt.n)
+			if ok != (tt.wantKind != "") || kind != tt.wantKind || subject != tt.wantSubject {
+				t.Errorf("defaultChatPost = %q %s %v, want %q %s", kind, subject, ok, tt.wantKind, tt.wantSubject)
+			}
+			if ok && message["text"] == "" {
+				t.Error("default post has no message")
+			}
+		})
+	}
+}
+
+// The shared channel hears about a high-severity incident once without any rules, and a routed
+// delivery of the event the default route already posted is not posted again
+func TestNotifyChatOpsPostsOnce(t *testing.T) {
+	openTestDB(t)
+	var posts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&posts, 1)
+	}))
+	defer server.Close()
+	t.Setenv("CHATOPS_WEBHOOK_URL", server.URL)
+
+	incident := seedIncident(t)
+	incident.Analysis = &models.IncidentAnalysis{IncidentID: incident.ID, Severity: "high"}
+	first := events.New(events.IncidentUpdated, *incident)
+	NotifyChatOps(first)
+	NotifyChatOps(events.New(events.IncidentUpdated, *incident))
+	if got := atomic.LoadInt32(&posts); got != 1 {
+		t.Fatalf("shared channel got %d posts, want 1", got)
+	}
+
+	n, _ := describeEvent(first)
+	reason, err := postSharedChatMessage(n, buildNotificationChatMessage("alice", n, true))
+	if err != nil || reason != "already_posted" {
+		t.Errorf("routed delivery = %q, %v, want already_posted", reason, err)
+	}
+	if got := atomic.LoadInt32(&posts); got != 1 {
+		t.Errorf("shared channel got %d posts after a routed delivery of the same event, want 1", got)
+	}
+}
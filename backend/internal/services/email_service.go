@@ -0,0 +1,531 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailEventTypes are the events users can receive by email
+var EmailEventTypes = []string{
+	events.IncidentCreated,
+	events.IncidentAssigned,
+	events.IncidentSLABreached,
+	events.IncidentUpdateDue,
+	events.IncidentStale,
+	events.IncidentFlapping,
+	events.AgentAwaitingApproval,
+}
+
+const (
+	emailMaxAttempts    = 6
+	emailBaseBackoff    = 30 * time.Second
+	emailLease          = 2 * time.Minute
+	emailBatchSize      = 20
+	emailPollInterval   = 5 * time.Second
+	emailDigestInterval = time.Minute
+)
+
+// EmailEnabled reports whether an SMTP server is configured
+func EmailEnabled() bool {
+	return os.Getenv("SMTP_HOST") != ""
+}
+
+func smtpAddress() string {
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "25"
+	}
+	return os.Getenv("SMTP_HOST") + ":" + port
+}
+
+func smtpFrom() string {
+	if from := os.Getenv("SMTP_FROM"); from != "" {
+		return from
+	}
+	return "incidents@localhost"
+}
+
+// ValidateNotificationPreference normalises and checks a preference before saving
+func ValidateNotificationPreference(pref *models.NotificationPreference) error {
+	pref.UserName = strings.TrimSpace(pref.UserName)
+	if pref.UserName == "" {
+		return fmt.Errorf("user_name is required")
+	}
+	if pref.Email != "" {
+		if _, err := mail.ParseAddress(pref.Email); err != nil {
+			return fmt.Errorf("invalid email address: %s", pref.Email)
+		}
+	}
+	if (pref.QuietHoursStart == "") != (pref.QuietHoursEnd == "") {
+		return fmt.Errorf("quiet_hours_start and quiet_hours_end must be set together")
+	}
+	for _, clock := range []string{pref.QuietHoursStart, pref.QuietHoursEnd} {
+		if clock == "" {
+			continue
+		}
+		if _, err := parseClock(clock); err != nil {
+			return err
+		}
+	}
+	if pref.Timezone == "" {
+		pref.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(pref.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone: %s", pref.Timezone)
+	}
+	if pref.ThrottleMinutes < 1 || pref.ThrottleMinutes > 24*60 {
+		return fmt.Errorf("throttle_minutes must be between 1 and 1440")
+	}
+	switch pref.DeliveryMode {
+	case "":
+		pref.DeliveryMode = models.DeliveryImmediate
+	case models.DeliveryImmediate, models.DeliveryHourlyDigest, models.DeliveryDailyDigest:
+	default:
+		return fmt.Errorf("delivery_mode must be immediate, hourly or daily")
+	}
+	for _, t := range pref.EventTypes {
+		if !containsString(EmailEventTypes, t) {
+			return fmt.Errorf("event type %s is not available by email", t)
+		}
+	}
+	if pref.EventTypes == nil {
+		pref.EventTypes = pq.StringArray{}
+	}
+	return nil
+}
+
+// SaveNotificationPreference creates or replaces a user's preferences
+func SaveNotificationPreference(pref *models.NotificationPreference) error {
+	if err := ValidateNotificationPreference(pref); err != nil {
+		return err
+	}
+
+	var existing models.NotificationPreference
+	if err := db.DB.First(&existing, "user_name = ?", pref.UserName).Error; err == nil {
+		pref.CreatedAt = existing.CreatedAt
+		pref.LastDigestAt = existing.LastDigestAt
+	}
+	return db.DB.Save(pref).Error
+}
+
+func GetNotificationPreferences() ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+	err := db.DB.Order("user_name ASC").Find(&prefs).Error
+	return prefs, err
+}
+
+func GetNotificationPreference(userName string) (models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	err := db.DB.First(&pref, "user_name = ?", userName).Error
+	return pref, err
+}
+
+// DeleteNotificationPreference removes a user's preferences and any digest items still queued for them
+func DeleteNotificationPreference(userName string) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_name = ? AND digested_at IS NULL", userName).Delete(&models.EmailDigestItem{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.NotificationPreference{}, "user_name = ?", userName)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("notification preference not found")
+		}
+		return nil
+	})
+}
+
+// GetEmailOutbox lists recent outbox messages, optionally filtered by status
+func GetEmailOutbox(status string, limit int) ([]models.EmailMessage, error) {
+	var messages []models.EmailMessage
+	query := db.DB.Order("created_at DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&messages).Error
+	return messages, err
+}
+
+// queueEmailNotification hands an event to the outbox for one user, or holds it for their next digest.
+// Returns the outbox message or digest item ID.
+func queueEmailNotification(pref models.NotificationPreference, event events.Event) (string, error) {
+	content, incidentID, ok := buildEventEmail(event, pref.UserName)
+	if !ok {
+		return "", fmt.Errorf("no email content for %s", event.Type)
+	}
+
+	if pref.DeliveryMode == models.DeliveryImmediate || pref.DeliveryMode == "" {
+		text, html, err := renderEmail(content)
+		if err != nil {
+			return "", err
+		}
+
+		message := models.EmailMessage{
+			UserName:      pref.UserName,
+			Recipient:     pref.Email,
+			Subject:       content.Subject,
+			TextBody:      text,
+			HTMLBody:      html,
+			EventType:     event.Type,
+			Status:        models.EmailPending,
+			NextAttemptAt: time.Now(),
+		}
+		if err := db.DB.Create(&message).Error; err != nil {
+			return "", err
+		}
+		return message.ID.String(), nil
+	}
+
+	item := models.EmailDigestItem{
+		UserName:   pref.UserName,
+		EventType:  event.Type,
+		IncidentID: incidentID,
+		Summary:    content.Subject,
+		CreatedAt:  time.Now(),
+	}
+	if err := db.DB.Create(&item).Error; err != nil {
+		return "", err
+	}
+	return item.ID.String(), nil
+}
+
+// buildEventEmail turns an event into email content for recipient; ok is false for events without a usable payload
+func buildEventEmail(event events.Event, recipient string) (emailContent, uuid.UUID, bool) {
+	if execution, ok := event.Data.(models.AgentExecution); ok {
+		incident, err := GetIncidentByID(execution.IncidentID)
+		if err != nil {
+			return emailContent{}, uuid.Nil, false
+		}
+		if event.Type != events.AgentAwaitingApproval {
+			fields := []emailField{
+				{"Incident", incident.Message},
+				{"Action", execution.RecommendedAction},
+				{"Status", string(execution.Status)},
+			}
+			if execution.ErrorMessage != "" {
+				fields = append(fields, emailField{"Error", execution.ErrorMessage})
+			}
+			return emailContent{
+				Subject: fmt.Sprintf("[%s] %s", EventTitle(event.Type), truncateText(incident.Message, 80)),
+				Heading: EventTitle(event.Type),
+				Fields:  fields,
+				Links:   []emailLink{{"Open dashboard", frontendBaseURL()}},
+			}, incident.ID, true
+		}
+		return emailContent{
+			Subject: fmt.Sprintf("[Approval needed] %s on %s", execution.RecommendedAction, truncateText(incident.Message, 80)),
+			Heading: "Agent action awaiting approval",
+			Intro:   execution.Reasoning,
+			Fields: []emailField{
+				{"Incident", incident.Message},
+				{"Action", execution.RecommendedAction},
+				{"Impact", execution.EstimatedImpact},
+				{"Team", incident.Team},
+			},
+			Links: []emailLink{
+				{"Approve", ApprovalLinkURL(execution.ID, ChatActionApprove, recipient)},
+				{"Reject", ApprovalLinkURL(execution.ID, ChatActionReject, recipient)},
+			},
+		}, incident.ID, true
+	}
+
+	incident, ok := event.Data.(models.Incident)
+	if !ok {
+		return emailContent{}, uuid.Nil, false
+	}
+
+	severity := "untriaged"
+	if incident.Analysis != nil && incident.Analysis.Severity != "" {
+		severity = incident.Analysis.Severity
+	}
+	fields := []emailField{
+		{"Severity", severity},
+		{"Status", incident.Status},
+		{"Team", incident.Team},
+		{"Source", incident.Source},
+		{"Affected systems", strings.Join(incident.AffectedSystems, ", ")},
+		{"Opened", incident.CreatedAt.UTC().Format("Jan 2 15:04 UTC")},
+	}
+	links := []emailLink{{"Open dashboard", frontendBaseURL()}}
+	short := truncateText(incident.Message, 80)
+
+	switch event.Type {
+	case events.IncidentCreated:
+		return emailContent{
+			Subject: fmt.Sprintf("[New incident] %s", short),
+			Heading: "New incident",
+			Intro:   incident.Message,
+			Fields:  fields,
+			Links:   links,
+		}, incident.ID, true
+	case events.IncidentAssigned:
+		return emailContent{
+			Subject: fmt.Sprintf("[Assigned to you] %s", short),
+			Heading: fmt.Sprintf("%s, you now own this incident", incident.Assignee),
+			Intro:   incident.Message,
+			Fields:  fields,
+			Links:   links,
+		}, incident.ID, true
+	case events.IncidentSLABreached:
+		return emailContent{
+			Subject: fmt.Sprintf("[SLA breached] %s", short),
+			Heading: "Incident has breached its SLA",
+			Intro:   fmt.Sprintf("This %s severity incident is still unresolved past its %s target.", severity, SLATargets[severity]),
+			Fields:  append(fields, emailField{"Assignee", incident.Assignee}),
+			Links:   links,
+		}, incident.ID, true
+	case events.IncidentUpdateDue:
+		return emailContent{
+			Subject: fmt.Sprintf("[Update due] %s", short),
+			Heading: "Stakeholders are waiting for an update",
+			Intro:   "This incident has gone longer than its update cadence without a stakeholder update. Post one from the dashboard to reset the clock.",
+			Fields:  append(fields, emailField{"Assignee", incident.Assignee}),
+			Links:   links,
+		}, incident.ID, true
+	default:
+		return emailContent{
+			Subject: fmt.Sprintf("[%s] %s", EventTitle(event.Type), short),
+			Heading: EventTitle(event.Type),
+			Intro:   incident.Message,
+			Fields:  fields,
+			Links:   links,
+		}, incident.ID, true
+	}
+}
+
+// StartEmailDispatcher sends queued emails and flushes digests. Sends never happen on request goroutines.
+func StartEmailDispatcher() {
+	log.Printf("📧 Email dispatcher started (SMTP %s)", smtpAddress())
+	sendTicker := time.NewTicker(emailPollInterval)
+	digestTicker := time.NewTicker(emailDigestInterval)
+	defer sendTicker.Stop()
+	defer digestTicker.Stop()
+
+	for {
+		select {
+		case <-sendTicker.C:
+			messages, err := claimEmails()
+			if err != nil {
+				log.Printf("❌ Failed to claim emails: %v", err)
+				continue
+			}
+			for i := range messages {
+				attemptEmail(&messages[i])
+			}
+		case <-digestTicker.C:
+			if err := flushDigests(time.Now()); err != nil {
+				log.Printf("❌ Failed to flush email digests: %v", err)
+			}
+		}
+	}
+}
+
+// claimEmails locks due outbox rows with SKIP LOCKED and leases them like the webhook dispatcher
+func claimEmails() ([]models.EmailMessage, error) {
+	var messages []models.EmailMessage
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.EmailPending, time.Now()).
+			Order("next_attempt_at ASC").
+			Limit(emailBatchSize).
+			Find(&messages).Error; err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(messages))
+		for i, m := range messages {
+			ids[i] = m.ID
+		}
+		return tx.Model(&models.EmailMessage{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(emailLease)).Error
+	})
+	return messages, err
+}
+
+func attemptEmail(message *models.EmailMessage) {
+	message.Attempts++
+	err := sendEmail(message)
+	if err == nil {
+		now := time.Now()
+		message.Status = models.EmailSent
+		message.SentAt = &now
+		message.LastError = ""
+		log.Printf("📧 Sent %s email to %s", message.EventType, message.Recipient)
+	} else {
+		message.LastError = err.Error()
+		if message.Attempts >= emailMaxAttempts {
+			message.Status = models.EmailFailed
+			log.Printf("❌ Giving up on email %s to %s after %d attempts: %v", message.ID.String()[:8], message.Recipient, message.Attempts, err)
+		} else {
+			message.NextAttemptAt = time.Now().Add(emailBaseBackoff << (message.Attempts - 1))
+			log.Printf("⚠️  Email %s to %s failed (attempt %d): %v", message.ID.String()[:8], message.Recipient, message.Attempts, err)
+		}
+	}
+
+	if err := db.DB.Save(message).Error; err != nil {
+		log.Printf("⚠️  Failed to update email %s: %v", message.ID.String()[:8], err)
+	}
+}
+
+// sendEmail delivers a message as multipart/alternative with text and HTML parts
+func sendEmail(message *models.EmailMessage) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	headers := []string{
+		"From: " + smtpFrom(),
+		"To: " + message.Recipient,
+		"Subject: " + mime.QEncoding.Encode("utf-8", message.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: " + emailMessageID(message.ID),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + writer.Boundary(),
+	}
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", message.TextBody},
+		{"text/html; charset=utf-8", message.HTMLBody},
+	} {
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return err
+		}
+		w.Write([]byte(part.content))
+	}
+	writer.Close()
+
+	raw := strings.Join(headers, "\r\n") + "\r\n\r\n" + body.String()
+
+	var auth smtp.Auth
+	if user := os.Getenv("SMTP_USERNAME"); user != "" {
+		auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), os.Getenv("SMTP_HOST"))
+	}
+	return smtp.SendMail(smtpAddress(), auth, smtpFrom(), []string{message.Recipient}, []byte(raw))
+}
+
+func emailMessageID(id uuid.UUID) string {
+	domain := "localhost"
+	if at := strings.LastIndex(smtpFrom(), "@"); at >= 0 {
+		domain = strings.Trim(smtpFrom()[at+1:], "> ")
+	}
+	nonce := make([]byte, 4)
+	rand.Read(nonce)
+	return fmt.Sprintf("<%s.%s@%s>", id, hex.EncodeToString(nonce), domain)
+}
+
+var digestLabels = map[string]string{
+	models.DeliveryHourlyDigest: "Hourly",
+	models.DeliveryDailyDigest:  "Daily",
+}
+
+// flushDigests sends one digest email per digest user whose period has elapsed and who has pending items
+func flushDigests(now time.Time) error {
+	var prefs []models.NotificationPreference
+	if err := db.DB.
+		Where("enabled = true AND delivery_mode IN ?", []string{models.DeliveryHourlyDigest, models.DeliveryDailyDigest}).
+		Find(&prefs).Error; err != nil {
+		return err
+	}
+
+	for _, pref := range prefs {
+		period := time.Hour
+		if pref.DeliveryMode == models.DeliveryDailyDigest {
+			period = 24 * time.Hour
+		}
+		if pref.LastDigestAt != nil && now.Sub(*pref.LastDigestAt) < period {
+			continue
+		}
+
+		if err := sendDigest(pref, now); err != nil {
+			log.Printf("❌ Failed to build digest for %s: %v", pref.UserName, err)
+		}
+	}
+	return nil
+}
+
+func sendDigest(pref models.NotificationPreference, now time.Time) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		var items []models.EmailDigestItem
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("user_name = ? AND digested_at IS NULL", pref.UserName).
+			Order("created_at ASC").
+			Find(&items).Error; err != nil {
+			return err
+		}
+
+		// Start the next period now even when there was nothing to send
+		if err := tx.Model(&models.NotificationPreference{}).
+			Where("user_name = ?", pref.UserName).
+			Update("last_digest_at", now).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		lines := make([]emailDigestLine, len(items))
+		ids := make([]uuid.UUID, len(items))
+		for i, item := range items {
+			lines[i] = emailDigestLine{Time: item.CreatedAt, Summary: item.Summary}
+			ids[i] = item.ID
+		}
+
+		content := emailContent{
+			Subject: fmt.Sprintf("[%s digest] %d incident notification(s)", digestLabels[pref.DeliveryMode], len(items)),
+			Heading: fmt.Sprintf("Your %s incident digest", pref.DeliveryMode),
+			Items:   lines,
+			Links:   []emailLink{{"Open dashboard", frontendBaseURL()}},
+		}
+		text, html, err := renderEmail(content)
+		if err != nil {
+			return err
+		}
+
+		message := models.EmailMessage{
+			UserName:      pref.UserName,
+			Recipient:     pref.Email,
+			Subject:       content.Subject,
+			TextBody:      text,
+			HTMLBody:      html,
+			EventType:     "digest",
+			Status:        models.EmailPending,
+			NextAttemptAt: now,
+		}
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+
+		log.Printf("📬 Queued %s digest with %d item(s) for %s", pref.DeliveryMode, len(items), pref.UserName)
+		return tx.Model(&models.EmailDigestItem{}).Where("id IN ?", ids).Update("digested_at", now).Error
+	})
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// emailContent is the template-independent content of one notification email
+type emailContent struct {
+	Subject string
+	Heading string
+	Intro   string
+	Fields  []emailField
+	Links   []emailLink
+	Items   []emailDigestLine // Only used by digests
+}
+
+type emailField struct {
+	Label string
+	Value string
+}
+
+type emailLink struct {
+	Text string
+	URL  string
+}
+
+type emailDigestLine struct {
+	Time    time.Time
+	Summary string
+}
+
+var emailHTMLTemplate = htmltemplate.Must(htmltemplate.New("email_html").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, Helvetica, Arial, sans-serif; color: #1f2937; background: #f9fafb; padding: 24px;">
+<div style="max-width: 600px; margin: 0 auto; background: #ffffff; border: 1px solid #e5e7eb; border-radius: 8px; padding: 24px;">
+<h2 style="margin-top: 0;">{{.Heading}}</h2>
+{{if .Intro}}<p>{{.Intro}}</p>{{end}}
+{{if .Fields}}
+<table style="border-collapse: collapse; width: 100%;">
+{{range .Fields}}<tr>
+<td style="padding: 4px 12px 4px 0; color: #6b7280; white-space: nowrap; vertical-align: top;">{{.Label}}</td>
+<td style="padding: 4px 0;">{{.Value}}</td>
+</tr>{{end}}
+</table>
+{{end}}
+{{if .Items}}
+<ul style="padding-left: 18px;">
+{{range .Items}}<li style="margin-bottom: 6px;"><span style="color: #6b7280;">{{.Time.UTC.Format "Jan 2 15:04 UTC"}}</span> {{.Summary}}</li>
+{{end}}</ul>
+{{end}}
+{{if .Links}}<p style="margin-top: 20px;">
+{{range .Links}}<a href="{{.URL}}" style="display: inline-block; margin-right: 8px; padding: 8px 14px; background: #2563eb; color: #ffffff; text-decoration: none; border-radius: 6px;">{{.Text}}</a>
+{{end}}</p>{{end}}
+<p style="color: #9ca3af; font-size: 12px; margin-top: 24px;">You receive this because of your notification preferences in the incident management simulator.</p>
+</div>
+</body>
+</html>
+`))
+
+var emailTextTemplate = texttemplate.Must(texttemplate.New("email_text").Parse(`{{.Heading}}
+{{if .Intro}}
+{{.Intro}}
+{{end}}{{if .Fields}}
+{{range .Fields}}{{.Label}}: {{.Value}}
+{{end}}{{end}}{{if .Items}}
+{{range .Items}}- {{.Time.UTC.Format "Jan 2 15:04 UTC"}}  {{.Summary}}
+{{end}}{{end}}{{if .Links}}
+{{range .Links}}{{.Text}}: {{.URL}}
+{{end}}{{end}}
+--
+You receive this because of your notification preferences in the incident management simulator.
+`))
+
+// renderEmail produces the text and HTML bodies for content
+func renderEmail(content emailContent) (string, string, error) {
+	var text, html bytes.Buffer
+	if err := emailTextTemplate.Execute(&text, content); err != nil {
+		return "", "", err
+	}
+	if err := emailHTMLTemplate.Execute(&html, content); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(text.String()) + "\n", html.String(), nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
+	"gorm.io/gorm"
+)
+
+// EventOutbox collects the domain events raised by one change. Adding an event queues its webhook
+// deliveries in the change's transaction, so they are stored exactly when the change commits and a
+// crash right after the commit cannot lose them. Dispatch then hands the events to in-process
+// subscribers; call it only once the transaction has committed.
+type EventOutbox struct {
+	events []events.Event
+}
+
+// Add records an event inside tx
+func (o *EventOutbox) Add(tx *gorm.DB, eventType string, data interface{}) error {
+	event := events.New(eventType, data)
+	if err := enqueueWebhookDeliveries(tx, event); err != nil {
+		return fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+	o.events = append(o.events, event)
+	return nil
+}
+
+// AddIncident records an event carrying the incident as tx sees it
+func (o *EventOutbox) AddIncident(tx *gorm.DB, eventType string, id uuid.UUID) error {
+	incident, err := getIncident(tx, id)
+	if err != nil {
+		return fmt.Errorf("failed to load incident %s for %s event: %w", id, eventType, err)
+	}
+	return o.Add(tx, eventType, incident)
+}
+
+// Dispatch delivers the recorded events to in-process subscribers
+func (o *EventOutbox) Dispatch() {
+	for _, event := range o.events {
+		events.Dispatch(event)
+	}
+	o.events = nil
+}
+
+// PublishEvent records and dispatches an event that is not part of a larger change
+func PublishEvent(eventType string, data interface{}) {
+	var outbox EventOutbox
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		return outbox.Add(tx, eventType, data)
+	}); err != nil {
+		log.Printf("❌ %v", err)
+		return
+	}
+	outbox.Dispatch()
+}
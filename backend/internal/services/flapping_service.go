@@ -0,0 +1,273 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	wshub "github.com/tri27pham/incident-management-simulator/backend/internal/websocket"
+	"gorm.io/gorm"
+)
+
+const (
+	flapCheckInterval     = time.Minute
+	flapHistoryRetention  = 7 * 24 * time.Hour
+	flapDetailTransitions = 100
+)
+
+// flapWindow is the sliding window transitions are counted in (FLAP_WINDOW_MINUTES, default 30)
+func flapWindow() time.Duration {
+	if minutes, err := strconv.Atoi(os.Getenv("FLAP_WINDOW_MINUTES")); err == nil && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return 30 * time.Minute
+}
+
+// flapThreshold is how many transitions in the window mark a fingerprint as flapping (FLAP_THRESHOLD, default 6)
+func flapThreshold() int {
+	if n, err := strconv.Atoi(os.Getenv("FLAP_THRESHOLD")); err == nil && n > 1 {
+		return n
+	}
+	return 6
+}
+
+// flapClearThreshold is the count at or below which flapping clears (FLAP_CLEAR_THRESHOLD, default 2).
+// Keeping it below the start threshold stops the flag itself from flapping.
+func flapClearThreshold() int {
+	if n, err := strconv.Atoi(os.Getenv("FLAP_CLEAR_THRESHOLD")); err == nil && n >= 0 && n < flapThreshold() {
+		return n
+	}
+	return flapThreshold() / 3
+}
+
+// RecordFlapTransition counts an open or resolve for the incident's fingerprint and starts flapping above the threshold
+func RecordFlapTransition(incident *models.Incident, kind string) {
+	fingerprint := IncidentFingerprint(incident)
+	now := time.Now()
+	var state models.FlapState
+	var outbox EventOutbox
+	started := false
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		// Serialise per fingerprint so concurrent opens/resolves are all counted
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "flap|"+fingerprint).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.FlapTransition{
+			Fingerprint: fingerprint,
+			IncidentID:  incident.ID,
+			Kind:        kind,
+			OccurredAt:  now,
+		}).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.FlapTransition{}).
+			Where("fingerprint = ? AND occurred_at > ?", fingerprint, now.Add(-flapWindow())).
+			Count(&count).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("fingerprint = ?", fingerprint).First(&state).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			state = models.FlapState{Fingerprint: fingerprint}
+		}
+		state.Source = incident.Source
+		state.Message = incident.Message
+		state.TransitionCount = int(count)
+		state.LastTransitionAt = now
+		state.LastIncidentID = &incident.ID
+		if !state.Flapping && int(count) >= flapThreshold() {
+			state.Flapping = true
+			state.FlappingSince = &now
+			state.ClearedAt = nil
+			state.ClearedBy = ""
+			started = true
+		}
+		if err := tx.Save(&state).Error; err != nil {
+			return err
+		}
+		if started {
+			return outbox.AddIncident(tx, events.IncidentFlapping, incident.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("⚠️  Failed to record flap transition for incident %s: %v", incident.ID.String()[:8], err)
+		return
+	}
+
+	switch {
+	case started:
+		log.Printf("🔁 Alert %s from %s is flapping (%d transitions in %s)", fingerprint, incident.Source, state.TransitionCount, flapWindow())
+		RecordTimelineEvent(incident.ID, "flapping", "system",
+			fmt.Sprintf("Alert is flapping: %d open/resolve transitions in %d minutes. Notifications and agent actions are suppressed until it is stable.",
+				state.TransitionCount, int(flapWindow().Minutes())),
+			map[string]interface{}{"fingerprint": fingerprint, "transition_count": state.TransitionCount})
+		broadcastFlapUpdate(&state)
+		outbox.Dispatch()
+	case state.Flapping && kind == models.FlapOpened:
+		RecordTimelineEvent(incident.ID, "flapping", "system",
+			"Opened while this alert is flapping; notifications and agent actions are suppressed",
+			map[string]interface{}{"fingerprint": fingerprint, "transition_count": state.TransitionCount})
+	}
+}
+
+// IncidentIsFlapping reports whether the incident's alert fingerprint is currently flapping
+func IncidentIsFlapping(incident *models.Incident) bool {
+	var state models.FlapState
+	if err := db.DB.Where("fingerprint = ?", IncidentFingerprint(incident)).First(&state).Error; err != nil {
+		return false
+	}
+	return state.Flapping
+}
+
+// StartFlapMonitor clears flapping once a fingerprint has been stable and prunes old transitions
+func StartFlapMonitor() {
+	log.Printf("🔁 Flap monitor started (window %s, threshold %d, clears at %d)", flapWindow(), flapThreshold(), flapClearThreshold())
+	ticker := time.NewTicker(flapCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := checkFlapStates(time.Now()); err != nil {
+			log.Printf("❌ Flap check failed: %v", err)
+		}
+	}
+}
+
+func checkFlapStates(now time.Time) error {
+	var states []models.FlapState
+	if err := db.DB.Where("flapping = true").Find(&states).Error; err != nil {
+		return err
+	}
+
+	for _, state := range states {
+		var count int64
+		if err := db.DB.Model(&models.FlapTransition{}).
+			Where("fingerprint = ? AND occurred_at > ?", state.Fingerprint, now.Add(-flapWindow())).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) > flapClearThreshold() {
+			db.DB.Model(&models.FlapState{}).Where("fingerprint = ?", state.Fingerprint).UpdateColumn("transition_count", count)
+			continue
+		}
+		clearFlapping(state, int(count), "system", "Alert is stable again")
+	}
+
+	return db.DB.Where("occurred_at < ?", now.Add(-flapHistoryRetention)).Delete(&models.FlapTransition{}).Error
+}
+
+// ClearFlapping manually ends flapping for a fingerprint, e.g. after fixing the threshold that caused it
+func ClearFlapping(fingerprint, actor string) (*models.FlapState, error) {
+	var state models.FlapState
+	if err := db.DB.Where("fingerprint = ?", fingerprint).First(&state).Error; err != nil {
+		return nil, fmt.Errorf("fingerprint not found")
+	}
+	if !state.Flapping {
+		return nil, fmt.Errorf("fingerprint is not flapping")
+	}
+	if !clearFlapping(state, state.TransitionCount, actor, "Flapping cleared manually") {
+		return nil, fmt.Errorf("fingerprint is not flapping")
+	}
+
+	if err := db.DB.Where("fingerprint = ?", fingerprint).First(&state).Error; err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// clearFlapping ends flapping, returning false if it was already cleared
+func clearFlapping(state models.FlapState, count int, actor, message string) bool {
+	now := time.Now()
+	result := db.DB.Model(&models.FlapState{}).
+		Where("fingerprint = ? AND flapping = true", state.Fingerprint).
+		Updates(map[string]interface{}{
+			"flapping":         false,
+			"transition_count": count,
+			"cleared_at":       now,
+			"cleared_by":       actor,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false
+	}
+
+	log.Printf("✅ Alert %s from %s stopped flapping (%s)", state.Fingerprint, state.Source, actor)
+	if state.LastIncidentID != nil {
+		RecordTimelineEvent(*state.LastIncidentID, "flap_cleared", actor, message,
+			map[string]interface{}{"fingerprint": state.Fingerprint, "transition_count": count})
+	}
+	state.Flapping = false
+	state.TransitionCount = count
+	state.ClearedAt = &now
+	state.ClearedBy = actor
+	broadcastFlapUpdate(&state)
+	return true
+}
+
+func broadcastFlapUpdate(state *models.FlapState) {
+	wshub.WSHub.Broadcast <- map[string]interface{}{
+		"type":  "flapping_update",
+		"state": state,
+	}
+}
+
+// GetFlapStates lists tracked fingerprints, most recently active first (?flapping=true, ?source=)
+func GetFlapStates(onlyFlapping bool, source string) ([]models.FlapState, error) {
+	var states []models.FlapState
+	query := db.DB.Order("last_transition_at DESC")
+	if onlyFlapping {
+		query = query.Where("flapping = true")
+	}
+	if source != "" {
+		query = query.Where("source = ?", source)
+	}
+	err := query.Find(&states).Error
+	return states, err
+}
+
+// FlapDetail is a fingerprint's flapping state with its recent transition history
+type FlapDetail struct {
+	State          models.FlapState        `json:"state"`
+	Transitions    []models.FlapTransition `json:"transitions"`
+	WindowMinutes  int                     `json:"window_minutes"`
+	Threshold      int                     `json:"threshold"`
+	ClearThreshold int                     `json:"clear_threshold"`
+}
+
+// GetFlapDetail returns a fingerprint's state and its latest transitions, newest first
+func GetFlapDetail(fingerprint string) (FlapDetail, error) {
+	detail := FlapDetail{
+		State:          models.FlapState{Fingerprint: fingerprint},
+		WindowMinutes:  int(flapWindow().Minutes()),
+		Threshold:      flapThreshold(),
+		ClearThreshold: flapClearThreshold(),
+	}
+	if err := db.DB.Where("fingerprint = ?", fingerprint).First(&detail.State).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return detail, err
+	}
+	err := db.DB.Where("fingerprint = ?", fingerprint).
+		Order("occurred_at DESC").
+		Limit(flapDetailTransitions).
+		Find(&detail.Transitions).Error
+	return detail, err
+}
+
+// GetIncidentFlapDetail returns flapping state for the incident's alert fingerprint
+func GetIncidentFlapDetail(incidentID uuid.UUID) (FlapDetail, error) {
+	incident, err := GetIncidentByID(incidentID)
+	if err != nil {
+		return FlapDetail{}, fmt.Errorf("incident not found")
+	}
+	return GetFlapDetail(IncidentFingerprint(&incident))
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"math/rand"
@@ -14,11 +16,27 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
 	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
 	wshub "github.com/tri27pham/incident-management-simulator/backend/internal/websocket"
 	"gorm.io/gorm"
 )
 
+// aiServiceTimeout bounds any single call to the AI service, so a hung service cannot hold its caller forever
+const aiServiceTimeout = 60 * time.Second
+
+var aiServiceClient = &http.Client{Timeout: aiServiceTimeout}
+
+// postAIService posts a JSON body to the AI service, giving up when ctx is done
+func postAIService(ctx context.Context, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, os.Getenv("AI_DIAGNOSIS_URL")+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return aiServiceClient.Do(req)
+}
+
 // FullIncidentDetails wraps an incident for broadcasting
 // The embedded Incident already includes Analysis via the foreign key relationship
 type FullIncidentDetails struct {
@@ -26,6 +44,7 @@ type FullIncidentDetails struct {
 	// Note: models.Incident already has Analysis field, no need to duplicate it
 }
 
+// CreateIncident stores a new incident with its first status history entry and queues its AI analysis
 func CreateIncident(incident *models.Incident) error {
 	// Start a transaction to ensure both incident and status history are created together
 	tx := db.DB.Begin()
@@ -62,18 +81,42 @@ func CreateIncident(incident *models.Incident) error {
 		return err
 	}
 
-	return tx.Commit().Error
+	// Queue the AI analysis with the incident, so an incident never exists without its analysis job
+	if _, err := EnqueueJobTx(tx, JobAnalyzeIncident, analyzeIncidentPayload{IncidentID: incident.ID}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var outbox EventOutbox
+	if err := outbox.AddIncident(tx, events.IncidentCreated, incident.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	BroadcastIncidentUpdate(incident.ID)
+	log.Printf("📡 Broadcasted new incident %s (analysis queued)", incident.ID.String()[:8])
+	RecordFlapTransition(incident, models.FlapOpened)
+	outbox.Dispatch()
+	return nil
 }
 
-func GetAllIncidents() ([]models.Incident, error) {
+// GetAllIncidents lists unresolved incidents; snoozed ones are left out unless includeSnoozed is set
+func GetAllIncidents(includeSnoozed bool) ([]models.Incident, error) {
 	var incidents []models.Incident
-	err := db.DB.
+	query := db.DB.
 		Preload("Analysis").
 		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
 			return db.Order("incident_status_history.changed_at ASC")
 		}).
-		Where("status != ?", "resolved").
-		Find(&incidents).Error
+		Where("status != ?", "resolved")
+	if !includeSnoozed {
+		query = query.Where("snoozed_until IS NULL OR snoozed_until <= ?", time.Now())
+	}
+	err := query.Find(&incidents).Error
 	return incidents, err
 }
 
@@ -91,8 +134,13 @@ func GetResolvedIncidents() ([]models.Incident, error) {
 }
 
 func GetIncidentByID(id uuid.UUID) (models.Incident, error) {
+	return getIncident(db.DB, id)
+}
+
+// getIncident loads an incident with its analysis and status history through tx
+func getIncident(tx *gorm.DB, id uuid.UUID) (models.Incident, error) {
 	var incident models.Incident
-	err := db.DB.
+	err := tx.
 		Preload("Analysis").
 		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
 			return db.Order("incident_status_history.changed_at ASC")
@@ -122,17 +170,34 @@ func DeleteIncident(id uuid.UUID) error {
 		return fmt.Errorf("failed to delete analysis: %w", err)
 	}
 
+	// Delete timeline and runbook checklists
+	if err := tx.Where("incident_id = ?", id).Delete(&models.TimelineEntry{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to delete timeline: %w", err)
+	}
+	if err := tx.Where("incident_id = ?", id).Delete(&models.IncidentChecklist{}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to delete checklists: %w", err)
+	}
+
 	// Delete incident
 	if err := tx.Delete(&models.Incident{}, id).Error; err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to delete incident: %w", err)
 	}
 
+	var outbox EventOutbox
+	if err := outbox.Add(tx, events.IncidentDeleted, map[string]interface{}{"id": id}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return err
 	}
 
 	log.Printf("🗑️  Deleted incident %s and all related data", id)
+	outbox.Dispatch()
 	return nil
 }
 
@@ -149,11 +214,18 @@ func UpdateIncidentNotes(id uuid.UUID, notes string) (*models.Incident, error) {
 
 	// Update notes
 	incident.Notes = notes
-	if err := db.DB.Save(&incident).Error; err != nil {
+	var outbox EventOutbox
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&incident).Error; err != nil {
+			return err
+		}
+		return outbox.AddIncident(tx, events.IncidentUpdated, id)
+	}); err != nil {
 		return nil, err
 	}
 
 	log.Printf("✅ Updated incident %s notes", incident.ID)
+	outbox.Dispatch()
 	return &incident, nil
 }
 
@@ -169,36 +241,38 @@ func UpdateIncidentSeverity(id uuid.UUID, severity string) (*models.Incident, er
 	}
 
 	// Update severity in the analysis (create one if it doesn't exist)
-	if incident.Analysis != nil {
-		incident.Analysis.Severity = severity
-		if err := db.DB.Save(&incident.Analysis).Error; err != nil {
-			return nil, err
-		}
-	} else {
-		// Create analysis if it doesn't exist
-		analysis := models.IncidentAnalysis{
-			IncidentID: incident.ID,
-			Severity:   severity,
-			Confidence: 1.0,
-		}
-		if err := db.DB.Create(&analysis).Error; err != nil {
-			return nil, err
+	var outbox EventOutbox
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if incident.Analysis != nil {
+			incident.Analysis.Severity = severity
+			if err := tx.Save(&incident.Analysis).Error; err != nil {
+				return err
+			}
+		} else {
+			analysis := models.IncidentAnalysis{
+				IncidentID: incident.ID,
+				Severity:   severity,
+				Confidence: 1.0,
+			}
+			if err := tx.Create(&analysis).Error; err != nil {
+				return err
+			}
 		}
+		return outbox.AddIncident(tx, events.IncidentUpdated, id)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		// Reload incident with the new analysis
-		if err := db.DB.
-			Preload("Analysis").
-			Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
-				return db.Order("incident_status_history.changed_at ASC")
-			}).
-			First(&incident, id).Error; err != nil {
-			return nil, err
-		}
+	// Reload incident with the new analysis
+	if incident, err = GetIncidentByID(id); err != nil {
+		return nil, err
 	}
 
 	// Broadcast the update to all connected clients
 	BroadcastIncidentUpdate(id)
 	log.Printf("✅ Updated incident %s severity to %s", incident.ID, severity)
+	outbox.Dispatch()
 
 	return &incident, nil
 }
@@ -216,13 +290,71 @@ func UpdateIncidentTeam(id uuid.UUID, team string) (*models.Incident, error) {
 
 	// Update team
 	incident.Team = team
-	if err := db.DB.Save(&incident).Error; err != nil {
+	var outbox EventOutbox
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&incident).Error; err != nil {
+			return err
+		}
+		return outbox.AddIncident(tx, events.IncidentUpdated, id)
+	}); err != nil {
 		return nil, err
 	}
 
 	// Broadcast the update to all connected clients
 	BroadcastIncidentUpdate(id)
 	log.Printf("✅ Updated incident %s team to %s", incident.ID, team)
+	outbox.Dispatch()
+
+	return &incident, nil
+}
+
+// UpdateIncidentAssignee sets who owns an incident; an empty assignee unassigns it
+func UpdateIncidentAssignee(id uuid.UUID, assignee, actor string) (*models.Incident, error) {
+	var incident models.Incident
+	if err := db.DB.
+		Preload("Analysis").
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
+			return db.Order("incident_status_history.changed_at ASC")
+		}).
+		First(&incident, id).Error; err != nil {
+		return nil, err // Incident not found
+	}
+
+	previous := incident.Assignee
+	if previous == assignee {
+		return &incident, nil
+	}
+
+	incident.Assignee = assignee
+	var outbox EventOutbox
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&incident).Error; err != nil {
+			return err
+		}
+		if err := outbox.AddIncident(tx, events.IncidentUpdated, id); err != nil {
+			return err
+		}
+		if assignee != "" {
+			return outbox.AddIncident(tx, events.IncidentAssigned, id)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Assigned to %s", assignee)
+	if assignee == "" {
+		message = fmt.Sprintf("Unassigned from %s", previous)
+	}
+	RecordTimelineEvent(id, "assigned", actor, message, map[string]interface{}{
+		"assignee":          assignee,
+		"previous_assignee": previous,
+	})
+
+	// Broadcast the update to all connected clients
+	BroadcastIncidentUpdate(id)
+	log.Printf("✅ Updated incident %s assignee to %q", incident.ID, assignee)
+	outbox.Dispatch()
 
 	return &incident, nil
 }
@@ -240,6 +372,7 @@ func UpdateIncidentStatus(id uuid.UUID, status string) (*models.Incident, error)
 
 	// Store old status before updating
 	oldStatus := incident.Status
+	var outbox EventOutbox
 
 	// Only create history entry if status actually changed
 	if oldStatus != status {
@@ -251,8 +384,15 @@ func UpdateIncidentStatus(id uuid.UUID, status string) (*models.Incident, error)
 			}
 		}()
 
-		// Update incident status
+		// Update incident status; any status change clears the stale flag
 		incident.Status = status
+		incident.StaleFlaggedAt = nil
+		if status == "resolved" {
+			// A resolved incident has nothing left to wake up for
+			incident.SnoozedUntil = nil
+			incident.SnoozeReason = ""
+			incident.SnoozedBy = ""
+		}
 		if err := tx.Save(&incident).Error; err != nil {
 			tx.Rollback()
 			return nil, err
@@ -270,6 +410,17 @@ func UpdateIncidentStatus(id uuid.UUID, status string) (*models.Incident, error)
 			return nil, err
 		}
 
+		if err := outbox.AddIncident(tx, events.IncidentUpdated, id); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if status == "resolved" {
+			if err := outbox.AddIncident(tx, events.IncidentResolved, id); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+
 		// Commit transaction
 		if err := tx.Commit().Error; err != nil {
 			return nil, err
@@ -286,6 +437,15 @@ func UpdateIncidentStatus(id uuid.UUID, status string) (*models.Incident, error)
 		}
 
 		log.Printf("✅ Updated incident %s status from %s to %s", incident.ID, oldStatus, status)
+
+		// Resolving and reopening are the state changes flap detection counts
+		if status == "resolved" {
+			RecordFlapTransition(&incident, models.FlapResolved)
+		} else if oldStatus == "resolved" {
+			RecordFlapTransition(&incident, models.FlapOpened)
+		}
+
+		outbox.Dispatch()
 	}
 
 	// Broadcast the status update to all connected clients
@@ -318,39 +478,75 @@ func BroadcastIncidentUpdate(id uuid.UUID) {
 	wshub.WSHub.Broadcast <- details
 }
 
-// RunFullAnalysisPipeline performs the complete AI analysis and broadcasts updates.
-func RunFullAnalysisPipeline(incident models.Incident) {
+// PublishIncidentEvent records and dispatches an event carrying the latest state of an incident,
+// for events that are not part of a larger change
+func PublishIncidentEvent(eventType string, id uuid.UUID) {
+	var outbox EventOutbox
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		return outbox.AddIncident(tx, eventType, id)
+	}); err != nil {
+		log.Printf("❌ %v", err)
+		return
+	}
+	outbox.Dispatch()
+}
+
+type analyzeIncidentPayload struct {
+	IncidentID uuid.UUID `json:"incident_id"`
+}
+
+// RegisterIncidentJobs registers the background jobs owned by the incident pipeline
+func RegisterIncidentJobs() {
+	RegisterJobType(JobAnalyzeIncident, JobType{
+		Concurrency: 4,
+		MaxAttempts: 4,
+		Timeout:     2 * time.Minute,
+		Handler: func(ctx context.Context, job *models.Job) error {
+			var payload analyzeIncidentPayload
+			if err := DecodeJobPayload(job, &payload); err != nil {
+				return err
+			}
+			incident, err := GetIncidentByID(payload.IncidentID)
+			if err != nil {
+				return Permanent(fmt.Errorf("incident %s not found", payload.IncidentID))
+			}
+			return RunFullAnalysisPipeline(ctx, incident)
+		},
+		OnDead: func(job *models.Job, err error) {
+			var payload analyzeIncidentPayload
+			if DecodeJobPayload(job, &payload) == nil {
+				RecordTimelineEvent(payload.IncidentID, "analysis_failed", "system",
+					fmt.Sprintf("AI diagnosis failed after %d attempt(s): %v", job.Attempts, err),
+					map[string]interface{}{"job_id": job.ID})
+			}
+		},
+	})
+}
+
+// RunFullAnalysisPipeline performs the AI diagnosis and broadcasts the result.
+// It runs as an incident.analyze job, so returning an error schedules a retry; ctx is the job's,
+// so hitting the job timeout abandons the AI call instead of holding the worker.
+func RunFullAnalysisPipeline(ctx context.Context, incident models.Incident) (err error) {
 	// Add defer to catch any panics
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("❌ PANIC in RunFullAnalysisPipeline for incident %s: %v", incident.ID.String()[:8], r)
+			err = fmt.Errorf("panic in analysis pipeline: %v", r)
 		}
 	}()
 
 	log.Printf("🚀 RunFullAnalysisPipeline started for incident %s (source: %s)", incident.ID.String()[:8], incident.Source)
 
-	// Step 1: Immediately broadcast the newly created incident
-	// Refetch to ensure we have StatusHistory loaded
-	incidentWithHistory, err := GetIncidentByID(incident.ID)
-	if err != nil {
-		log.Printf("❌ Failed to fetch incident %s for initial broadcast: %v", incident.ID.String()[:8], err)
-		// Fallback to incident without history
-		incidentWithHistory = incident
-	}
-
-	detailsNew := FullIncidentDetails{Incident: incidentWithHistory}
-	wshub.WSHub.Broadcast <- detailsNew
-	log.Printf("📡 Broadcasted new incident %s (no analysis yet)", incident.ID.String()[:8])
-
-	// Step 2: Trigger Diagnosis
+	// Step 1: Trigger Diagnosis
 	log.Printf("🔬 Starting analysis pipeline for incident %s", incident.ID.String()[:8])
-	_, diagErr := TriggerAIDiagnosis(incident.ID)
+	var outbox EventOutbox
+	_, diagErr := triggerAIDiagnosis(ctx, incident.ID, &outbox)
 	if diagErr != nil {
 		log.Printf("❌ Error in AI diagnosis for incident %s: %v", incident.ID.String()[:8], diagErr)
-		return // End the pipeline if diagnosis fails
+		return diagErr // End the pipeline if diagnosis fails
 	}
 
-	// Step 3: Broadcast Diagnosis Update
+	// Step 2: Broadcast Diagnosis Update
 	// Refetch incident to get the latest status with Analysis preloaded
 	incidentWithStatus, _ := GetIncidentByID(incident.ID)
 	detailsWithDiagnosis := FullIncidentDetails{
@@ -364,9 +560,11 @@ func RunFullAnalysisPipeline(incident models.Incident) {
 
 	wshub.WSHub.Broadcast <- detailsWithDiagnosis
 	log.Printf("Broadcasted diagnosis update for incident %s", incident.ID)
+	outbox.Dispatch()
 
 	// Note: Solution is now triggered manually via "Get AI Solution" button
 	log.Printf("Finished diagnosis pipeline for incident %s (solution can be triggered manually)", incident.ID)
+	return nil
 }
 
 // aiDiagnosisResponse defines the expected JSON structure from the AI service.
@@ -383,7 +581,13 @@ type aiSuggestedFixResponse struct {
 	Provider     string  `json:"provider"`
 }
 
-func TriggerAIDiagnosis(incidentID uuid.UUID) (models.IncidentAnalysis, error) {
+func TriggerAIDiagnosis(ctx context.Context, incidentID uuid.UUID) (models.IncidentAnalysis, error) {
+	return triggerAIDiagnosis(ctx, incidentID, nil)
+}
+
+// triggerAIDiagnosis runs the diagnosis; when outbox is set, an incident.updated event is recorded
+// with the saved analysis
+func triggerAIDiagnosis(ctx context.Context, incidentID uuid.UUID, outbox *EventOutbox) (models.IncidentAnalysis, error) {
 	var incident models.Incident
 	var analysis models.IncidentAnalysis
 
@@ -397,7 +601,7 @@ func TriggerAIDiagnosis(incidentID uuid.UUID) (models.IncidentAnalysis, error) {
 
 	log.Printf("🤖 TriggerAIDiagnosis: Calling AI service for incident %s", incidentID.String()[:8])
 	// 2. Call the AI service to get a diagnosis.
-	body, err := callAIService(incident.Message, "/api/v1/diagnosis")
+	body, err := callAIService(ctx, incident.Message, "/api/v1/diagnosis")
 	if err != nil {
 		log.Printf("❌ TriggerAIDiagnosis: AI service call failed for incident %s: %v", incidentID.String()[:8], err)
 		return analysis, err
@@ -420,8 +624,16 @@ func TriggerAIDiagnosis(incidentID uuid.UUID) (models.IncidentAnalysis, error) {
 	analysis.Diagnosis = diagResp.Diagnosis
 	analysis.Severity = diagResp.Severity
 	analysis.DiagnosisProvider = diagResp.Provider
-	if err := db.DB.Save(&analysis).Error; err != nil {
-		return analysis, fmt.Errorf("failed to save incident analysis: %w", err)
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&analysis).Error; err != nil {
+			return fmt.Errorf("failed to save incident analysis: %w", err)
+		}
+		if outbox != nil {
+			return outbox.AddIncident(tx, events.IncidentUpdated, incident.ID)
+		}
+		return nil
+	}); err != nil {
+		return analysis, err
 	}
 
 	// 4. Keep incident in current status - users will manually move it
@@ -432,7 +644,7 @@ func TriggerAIDiagnosis(incidentID uuid.UUID) (models.IncidentAnalysis, error) {
 	return analysis, nil
 }
 
-func TriggerAISuggestedFix(incidentID uuid.UUID) (models.IncidentAnalysis, error) {
+func TriggerAISuggestedFix(ctx context.Context, incidentID uuid.UUID) (models.IncidentAnalysis, error) {
 	var incident models.Incident
 	var analysis models.IncidentAnalysis
 
@@ -445,7 +657,7 @@ func TriggerAISuggestedFix(incidentID uuid.UUID) (models.IncidentAnalysis, error
 	}
 
 	// 2. Call the AI service to get a suggested fix.
-	body, err := callAIService(incident.Message, "/api/v1/suggested-fix")
+	body, err := callAIService(ctx, incident.Message, "/api/v1/suggested-fix")
 	if err != nil {
 		return analysis, err
 	}
@@ -471,14 +683,13 @@ func TriggerAISuggestedFix(incidentID uuid.UUID) (models.IncidentAnalysis, error
 }
 
 // callAIService is a helper to communicate with the AI diagnosis service.
-func callAIService(message string, path string) ([]byte, error) {
+func callAIService(ctx context.Context, message string, path string) ([]byte, error) {
 	reqBody, err := json.Marshal(map[string]string{"description": message})
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	aiURL := os.Getenv("AI_DIAGNOSIS_URL") + path
-	resp, err := http.Post(aiURL, "application/json", bytes.NewBuffer(reqBody))
+	resp, err := postAIService(ctx, path, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to call AI service: %w", err)
 	}
@@ -497,10 +708,9 @@ func callAIService(message string, path string) ([]byte, error) {
 }
 
 // GenerateRandomIncident creates a random incident using AI
-func GenerateRandomIncident() (models.Incident, error) {
+func GenerateRandomIncident(ctx context.Context) (models.Incident, error) {
 	// Call the dedicated incident generation endpoint
-	aiURL := os.Getenv("AI_DIAGNOSIS_URL") + "/api/v1/generate-incident"
-	resp, err := http.Post(aiURL, "application/json", nil)
+	resp, err := postAIService(ctx, "/api/v1/generate-incident", nil)
 	if err != nil {
 		return models.Incident{}, fmt.Errorf("failed to call AI service: %w", err)
 	}
@@ -557,7 +767,10 @@ func TruncateAllTables() error {
 	// Execute TRUNCATE for all tables with CASCADE to handle foreign key constraints
 	// RESTART IDENTITY resets auto-increment sequences
 	err := db.DB.Exec(`
-		TRUNCATE TABLE incidents, incident_analysis, incident_status_history, agent_executions 
+		TRUNCATE TABLE incidents, incident_analysis, incident_status_history, agent_executions,
+			incident_timeline, incident_checklists, suppressed_alerts, webhook_deliveries, webhook_delivery_attempts,
+			chat_notifications, email_outbox, email_digest_items, notification_attempts, status_posts, status_post_updates,
+			stakeholder_updates, flap_states, flap_transitions, jobs
 		RESTART IDENTITY CASCADE
 	`).Error
 
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// A new incident commits together with the job that analyses it
+func TestCreateIncidentQueuesAnalysis(t *testing.T) {
+	openTestDB(t)
+	incident := &models.Incident{
+		Message:         "Redis memory usage critical",
+		Source:          "redis-test",
+		Status:          "triage",
+		AffectedSystems: []string{"redis-test"},
+		MetricsSnapshot: "{}",
+	}
+	if err := CreateIncident(incident); err != nil {
+		t.Fatalf("CreateIncident: %v", err)
+	}
+
+	var jobs []models.Job
+	if err := db.DB.Where("type = ?", JobAnalyzeIncident).Find(&jobs).Error; err != nil {
+		t.Fatalf("failed to load jobs: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("got %d analysis jobs, want 1", len(jobs))
+	}
+	var payload analyzeIncidentPayload
+	if err := DecodeJobPayload(&jobs[0], &payload); err != nil {
+		t.Fatalf("DecodeJobPayload: %v", err)
+	}
+	if payload.IncidentID != incident.ID {
+		t.Errorf("job analyses %s, want %s", payload.IncidentID, incident.ID)
+	}
+}
+
+// If the analysis job cannot be stored, the incident is not stored either
+func TestCreateIncidentRollsBackWithoutJob(t *testing.T) {
+	openTestDB(t)
+	if err := db.DB.Exec("ALTER TABLE jobs ADD CONSTRAINT test_no_jobs CHECK (false) NOT VALID").Error; err != nil {
+		t.Fatalf("failed to block job inserts: %v", err)
+	}
+	defer db.DB.Exec("ALTER TABLE jobs DROP CONSTRAINT test_no_jobs")
+
+	incident := &models.Incident{
+		ID:              uuid.New(),
+		Message:         "Redis memory usage critical",
+		Source:          "redis-test",
+		Status:          "triage",
+		AffectedSystems: []string{"redis-test"},
+		MetricsSnapshot: "{}",
+	}
+	if err := CreateIncident(incident); err == nil {
+		t.Fatal("CreateIncident succeeded although its analysis job could not be queued")
+	}
+	var count int64
+	db.DB.Model(&models.Incident{}).Where("id = ?", incident.ID).Count(&count)
+	if count != 0 {
+		t.Error("incident was stored without its analysis job")
+	}
+}
@@ -0,0 +1,476 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Job types
+const (
+	JobAnalyzeIncident = "incident.analyze" // AI diagnosis pipeline for a new incident
+	JobAgentPlan       = "agent.plan"       // Agent thinking + command preview, up to awaiting approval
+	JobAgentExecute    = "agent.execute"    // Agent execution + verification after approval
+	JobAgentRollback   = "agent.rollback"   // Agent compensating commands after an approved rollback
+)
+
+const (
+	jobPollInterval   = 2 * time.Second
+	jobLease          = 2 * time.Minute
+	jobHeartbeat      = 30 * time.Second
+	jobBaseBackoff    = 5 * time.Second
+	jobMaxBackoff     = 5 * time.Minute
+	jobRetention      = 7 * 24 * time.Hour
+	jobPruneInterval  = time.Hour
+	defaultJobTimeout = 10 * time.Minute
+)
+
+// JobHandler runs one attempt of a job. Returning an error schedules a retry unless attempts are exhausted.
+type JobHandler func(ctx context.Context, job *models.Job) error
+
+// JobType configures how jobs of one type are run
+type JobType struct {
+	Handler     JobHandler
+	Concurrency int           // Max jobs of this type running at once in this process
+	MaxAttempts int           // Including the first attempt
+	Timeout     time.Duration // Per attempt; the handler's context is cancelled after this
+	// OnDead runs once when a job is dead-lettered, e.g. to mark the work it represents as failed
+	OnDead func(job *models.Job, err error)
+}
+
+// PermanentJobError marks a failure that retrying cannot fix; the job is dead-lettered immediately
+type PermanentJobError struct{ Err error }
+
+func (e *PermanentJobError) Error() string { return e.Err.Error() }
+func (e *PermanentJobError) Unwrap() error { return e.Err }
+
+// Permanent wraps err so the job is not retried
+func Permanent(err error) error {
+	return &PermanentJobError{Err: err}
+}
+
+var (
+	jobTypesMu sync.RWMutex
+	jobTypes   = map[string]JobType{}
+
+	runningJobsMu sync.Mutex
+	runningJobs   = map[uuid.UUID]context.CancelFunc{}
+
+	workerID = jobWorkerID()
+)
+
+func jobWorkerID() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+// RegisterJobType makes a job type runnable. Call before StartJobWorkers.
+func RegisterJobType(jobType string, t JobType) {
+	if t.Concurrency < 1 {
+		t.Concurrency = 1
+	}
+	if t.MaxAttempts < 1 {
+		t.MaxAttempts = 1
+	}
+	if t.Timeout <= 0 {
+		t.Timeout = defaultJobTimeout
+	}
+	jobTypesMu.Lock()
+	defer jobTypesMu.Unlock()
+	jobTypes[jobType] = t
+}
+
+func getJobType(jobType string) (JobType, bool) {
+	jobTypesMu.RLock()
+	defer jobTypesMu.RUnlock()
+	t, ok := jobTypes[jobType]
+	return t, ok
+}
+
+// RegisteredJobTypes lists job types with a handler, sorted
+func RegisteredJobTypes() []string {
+	jobTypesMu.RLock()
+	defer jobTypesMu.RUnlock()
+	types := make([]string, 0, len(jobTypes))
+	for t := range jobTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// EnqueueJob stores a job to run as soon as a worker is free
+func EnqueueJob(jobType string, payload interface{}) (*models.Job, error) {
+	return EnqueueJobTx(db.DB, jobType, payload)
+}
+
+// EnqueueJobTx stores a job inside an existing transaction, so it only exists if the surrounding work commits
+func EnqueueJobTx(tx *gorm.DB, jobType string, payload interface{}) (*models.Job, error) {
+	t, ok := getJobType(jobType)
+	if !ok {
+		return nil, fmt.Errorf("unknown job type: %s", jobType)
+	}
+
+	// Round-trip through JSON so handlers always see the same shape they would after a restart
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	var data interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	job := models.Job{
+		Type:        jobType,
+		Payload:     models.JSONB{Data: data},
+		Status:      models.JobQueued,
+		MaxAttempts: t.MaxAttempts,
+		RunAt:       time.Now(),
+	}
+	if err := tx.Create(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// DecodeJobPayload unmarshals a job's payload into v
+func DecodeJobPayload(job *models.Job, v interface{}) error {
+	raw, err := json.Marshal(job.Payload.Data)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return Permanent(fmt.Errorf("invalid payload for %s job: %w", job.Type, err))
+	}
+	return nil
+}
+
+// StartJobWorkers runs a poller per registered job type, each limited to its concurrency
+func StartJobWorkers() {
+	for _, jobType := range RegisteredJobTypes() {
+		t, _ := getJobType(jobType)
+		log.Printf("🧵 Job worker started for %s (concurrency %d, max attempts %d)", jobType, t.Concurrency, t.MaxAttempts)
+		go runJobPoller(jobType, t)
+	}
+
+	ticker := time.NewTicker(jobPruneInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		result := db.DB.Where("status = ? AND finished_at < ?", models.JobSucceeded, time.Now().Add(-jobRetention)).Delete(&models.Job{})
+		if result.Error != nil {
+			log.Printf("⚠️  Failed to prune finished jobs: %v", result.Error)
+		} else if result.RowsAffected > 0 {
+			log.Printf("🧹 Pruned %d finished jobs", result.RowsAffected)
+		}
+	}
+}
+
+func runJobPoller(jobType string, t JobType) {
+	slots := make(chan struct{}, t.Concurrency)
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		free := t.Concurrency - len(slots)
+		if free <= 0 {
+			continue
+		}
+		jobs, err := claimJobs(jobType, t, free)
+		if err != nil {
+			log.Printf("❌ Failed to claim %s jobs: %v", jobType, err)
+			continue
+		}
+		for i := range jobs {
+			slots <- struct{}{}
+			go func(job models.Job) {
+				defer func() { <-slots }()
+				runJob(&job, t)
+			}(jobs[i])
+		}
+	}
+}
+
+// claimJobs takes due jobs, plus running jobs whose worker stopped renewing its lease (e.g. after a restart).
+// An abandoned job with no attempts left is dead-lettered rather than run again.
+func claimJobs(jobType string, t JobType, limit int) ([]models.Job, error) {
+	var candidates, jobs, abandoned []models.Job
+	now := time.Now()
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("type = ?", jobType).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?)", models.JobQueued, now, models.JobRunning, now).
+			Order("run_at ASC").
+			Limit(limit).
+			Find(&candidates).Error; err != nil {
+			return err
+		}
+
+		lease := now.Add(jobLease)
+		for _, job := range candidates {
+			if job.Status == models.JobRunning {
+				log.Printf("♻️  Reclaiming %s job %s from %s (lease expired)", jobType, job.ID.String()[:8], job.LockedBy)
+				if job.FinalAttempt() {
+					job.Status = models.JobDead
+					job.LastError = "worker stopped mid-run with no attempts left"
+					if err := tx.Model(&models.Job{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+						"status":       job.Status,
+						"last_error":   job.LastError,
+						"finished_at":  now,
+						"locked_until": nil,
+					}).Error; err != nil {
+						return err
+					}
+					abandoned = append(abandoned, job)
+					continue
+				}
+			}
+
+			job.Status = models.JobRunning
+			job.Attempts++
+			job.LockedUntil = &lease
+			job.LockedBy = workerID
+			job.StartedAt = &now
+			if err := tx.Model(&models.Job{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+				"status":       job.Status,
+				"attempts":     job.Attempts,
+				"locked_until": lease,
+				"locked_by":    workerID,
+				"started_at":   now,
+			}).Error; err != nil {
+				return err
+			}
+			jobs = append(jobs, job)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range abandoned {
+		log.Printf("💀 %s job %s dead: %s", jobType, abandoned[i].ID.String()[:8], abandoned[i].LastError)
+		if t.OnDead != nil {
+			t.OnDead(&abandoned[i], errors.New(abandoned[i].LastError))
+		}
+	}
+	return jobs, nil
+}
+
+func runJob(job *models.Job, t JobType) {
+	ctx, cancel := context.WithTimeout(context.Background(), t.Timeout)
+	defer cancel()
+
+	runningJobsMu.Lock()
+	runningJobs[job.ID] = cancel
+	runningJobsMu.Unlock()
+	defer func() {
+		runningJobsMu.Lock()
+		delete(runningJobs, job.ID)
+		runningJobsMu.Unlock()
+	}()
+
+	// Renew the lease while the handler runs so no other worker reclaims the job
+	stopHeartbeat := make(chan struct{})
+	defer close(stopHeartbeat)
+	go func() {
+		ticker := time.NewTicker(jobHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopHeartbeat:
+				return
+			case <-ticker.C:
+				db.DB.Model(&models.Job{}).
+					Where("id = ? AND status = ? AND locked_by = ?", job.ID, models.JobRunning, workerID).
+					Update("locked_until", time.Now().Add(jobLease))
+			}
+		}
+	}()
+
+	err := callJobHandler(ctx, job, t.Handler)
+	if err == nil {
+		finishJob(job, models.JobSucceeded, "")
+		return
+	}
+
+	var permanent *PermanentJobError
+	if errors.As(err, &permanent) || job.FinalAttempt() {
+		log.Printf("💀 %s job %s dead after %d attempt(s): %v", job.Type, job.ID.String()[:8], job.Attempts, err)
+		if finishJob(job, models.JobDead, err.Error()) && t.OnDead != nil {
+			t.OnDead(job, err)
+		}
+		return
+	}
+
+	backoff := jobBaseBackoff << (job.Attempts - 1)
+	if backoff > jobMaxBackoff || backoff <= 0 {
+		backoff = jobMaxBackoff
+	}
+	log.Printf("🔁 %s job %s failed (attempt %d/%d), retrying in %s: %v", job.Type, job.ID.String()[:8], job.Attempts, job.MaxAttempts, backoff, err)
+	db.DB.Model(&models.Job{}).
+		Where("id = ? AND status = ? AND locked_by = ?", job.ID, models.JobRunning, workerID).
+		Updates(map[string]interface{}{
+			"status":       models.JobQueued,
+			"run_at":       time.Now().Add(backoff),
+			"locked_until": nil,
+			"locked_by":    "",
+			"last_error":   err.Error(),
+		})
+}
+
+// callJobHandler turns a handler panic into an error so one bad job can't kill its worker
+func callJobHandler(ctx context.Context, job *models.Job, handler JobHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// finishJob records a terminal status unless the job was cancelled or reclaimed meanwhile
+func finishJob(job *models.Job, status, lastError string) bool {
+	now := time.Now()
+	result := db.DB.Model(&models.Job{}).
+		Where("id = ? AND status = ? AND locked_by = ?", job.ID, models.JobRunning, workerID).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"finished_at":  now,
+			"locked_until": nil,
+			"last_error":   lastError,
+		})
+	if result.Error != nil {
+		log.Printf("⚠️  Failed to record %s for job %s: %v", status, job.ID.String()[:8], result.Error)
+		return false
+	}
+	return result.RowsAffected > 0
+}
+
+// HasPendingJob reports whether a queued or running job of one of the types has payload[key] == value
+func HasPendingJob(jobTypes []string, key, value string) (bool, error) {
+	var count int64
+	err := db.DB.Model(&models.Job{}).
+		Where("type IN ? AND status IN ? AND payload->>? = ?", jobTypes, []string{models.JobQueued, models.JobRunning}, key, value).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// JobFilter narrows the admin job listing
+type JobFilter struct {
+	Status string
+	Type   string
+	Limit  int
+}
+
+// GetJobs lists jobs, newest first
+func GetJobs(filter JobFilter) ([]models.Job, error) {
+	var jobs []models.Job
+	query := db.DB.Order("created_at DESC").Limit(filter.Limit)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	err := query.Find(&jobs).Error
+	return jobs, err
+}
+
+// GetJobByID returns a single job
+func GetJobByID(id uuid.UUID) (models.Job, error) {
+	var job models.Job
+	err := db.DB.First(&job, "id = ?", id).Error
+	return job, err
+}
+
+// JobStats counts jobs per type and status
+type JobStats struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
+
+// GetJobStats summarises the queue for the admin API
+func GetJobStats() ([]JobStats, error) {
+	var stats []JobStats
+	err := db.DB.Model(&models.Job{}).
+		Select("type, status, COUNT(*) AS count").
+		Group("type, status").
+		Order("type, status").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// RetryJob requeues a dead or cancelled job with a fresh set of attempts
+func RetryJob(id uuid.UUID) (*models.Job, error) {
+	result := db.DB.Model(&models.Job{}).
+		Where("id = ? AND status IN ?", id, []string{models.JobDead, models.JobCancelled}).
+		Updates(map[string]interface{}{
+			"status":       models.JobQueued,
+			"attempts":     0,
+			"run_at":       time.Now(),
+			"locked_until": nil,
+			"locked_by":    "",
+			"finished_at":  nil,
+			"cancelled_by": "",
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("only dead or cancelled jobs can be retried")
+	}
+
+	job, err := GetJobByID(id)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("🔁 Job %s (%s) requeued manually", id.String()[:8], job.Type)
+	return &job, nil
+}
+
+// CancelJob stops a queued job, or signals a running one through its context
+func CancelJob(id uuid.UUID, actor string) (*models.Job, error) {
+	result := db.DB.Model(&models.Job{}).
+		Where("id = ? AND status IN ?", id, []string{models.JobQueued, models.JobRunning}).
+		Updates(map[string]interface{}{
+			"status":       models.JobCancelled,
+			"cancelled_by": actor,
+			"finished_at":  time.Now(),
+			"locked_until": nil,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("only queued or running jobs can be cancelled")
+	}
+
+	// Running here: stop the handler. Running on another instance: it notices when it tries to finish.
+	runningJobsMu.Lock()
+	if cancel, ok := runningJobs[id]; ok {
+		cancel()
+	}
+	runningJobsMu.Unlock()
+
+	job, err := GetJobByID(id)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("🛑 Job %s (%s) cancelled by %s", id.String()[:8], job.Type, actor)
+	return &job, nil
+}
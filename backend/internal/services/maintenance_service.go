@@ -0,0 +1,304 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/utils"
+)
+
+const (
+	MaintenanceModeSuppress = "suppress" // Alerts are held back for review instead of opening incidents
+	MaintenanceModeAnnotate = "annotate" // Incidents open but are marked as "during maintenance"
+
+	// maxRecurringWindowMinutes bounds how far back we scan for the start of a recurring occurrence
+	maxRecurringWindowMinutes = 7 * 24 * 60
+)
+
+// ValidateMaintenanceWindow checks a window definition before it is saved
+func ValidateMaintenanceWindow(w *models.MaintenanceWindow) error {
+	if len(w.Services) == 0 {
+		return fmt.Errorf("at least one service is required")
+	}
+	if w.Mode == "" {
+		w.Mode = MaintenanceModeSuppress
+	}
+	if w.Mode != MaintenanceModeSuppress && w.Mode != MaintenanceModeAnnotate {
+		return fmt.Errorf("mode must be '%s' or '%s'", MaintenanceModeSuppress, MaintenanceModeAnnotate)
+	}
+	if w.StartsAt.IsZero() {
+		return fmt.Errorf("starts_at is required")
+	}
+
+	if w.Recurrence == "" {
+		if w.EndsAt == nil || !w.EndsAt.After(w.StartsAt) {
+			return fmt.Errorf("one-off windows need ends_at after starts_at")
+		}
+		return nil
+	}
+
+	if _, err := utils.ParseCron(w.Recurrence); err != nil {
+		return fmt.Errorf("invalid recurrence: %w", err)
+	}
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", w.Timezone, err)
+	}
+	if w.DurationMinutes <= 0 || w.DurationMinutes > maxRecurringWindowMinutes {
+		return fmt.Errorf("recurring windows need duration_minutes between 1 and %d", maxRecurringWindowMinutes)
+	}
+	if w.EndsAt != nil && !w.EndsAt.After(w.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+// MaintenanceWindowActiveAt reports whether the window covers t. A recurrence is matched against the
+// wall clock in the window's timezone (UTC when unset), so "0 2 * * 0" stays at 02:00 local across DST.
+func MaintenanceWindowActiveAt(w *models.MaintenanceWindow, t time.Time) bool {
+	t = t.UTC()
+	if t.Before(w.StartsAt) {
+		return false
+	}
+
+	if w.Recurrence == "" {
+		return w.EndsAt != nil && t.Before(*w.EndsAt)
+	}
+
+	if w.EndsAt != nil && !t.Before(*w.EndsAt) {
+		return false
+	}
+
+	schedule, err := utils.ParseCron(w.Recurrence)
+	if err != nil {
+		return false
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	// Look back for an occurrence that started within the last DurationMinutes
+	minute := t.Truncate(time.Minute)
+	for i := 0; i < w.DurationMinutes; i++ {
+		occurrence := minute.Add(-time.Duration(i) * time.Minute)
+		if occurrence.Before(w.StartsAt.Truncate(time.Minute)) {
+			break
+		}
+		if schedule.Matches(occurrence.In(loc)) {
+			return true
+		}
+	}
+	return false
+}
+
+func CreateMaintenanceWindow(w *models.MaintenanceWindow) error {
+	if err := ValidateMaintenanceWindow(w); err != nil {
+		return err
+	}
+	if err := db.DB.Create(w).Error; err != nil {
+		return err
+	}
+	w.Active = MaintenanceWindowActiveAt(w, time.Now())
+	log.Printf("🛠️  Created maintenance window %s for %v (mode: %s)", w.ID.String()[:8], []string(w.Services), w.Mode)
+	return nil
+}
+
+func UpdateMaintenanceWindow(w *models.MaintenanceWindow) error {
+	if err := ValidateMaintenanceWindow(w); err != nil {
+		return err
+	}
+	if err := db.DB.Save(w).Error; err != nil {
+		return err
+	}
+	w.Active = MaintenanceWindowActiveAt(w, time.Now())
+	return nil
+}
+
+func GetMaintenanceWindows() ([]models.MaintenanceWindow, error) {
+	var windows []models.MaintenanceWindow
+	if err := db.DB.Order("starts_at DESC").Find(&windows).Error; err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range windows {
+		windows[i].Active = MaintenanceWindowActiveAt(&windows[i], now)
+	}
+	return windows, nil
+}
+
+func GetMaintenanceWindowByID(id uuid.UUID) (models.MaintenanceWindow, error) {
+	var window models.MaintenanceWindow
+	if err := db.DB.First(&window, "id = ?", id).Error; err != nil {
+		return window, err
+	}
+	window.Active = MaintenanceWindowActiveAt(&window, time.Now())
+	return window, nil
+}
+
+func DeleteMaintenanceWindow(id uuid.UUID) error {
+	result := db.DB.Delete(&models.MaintenanceWindow{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("maintenance window not found")
+	}
+	return nil
+}
+
+// FindActiveMaintenanceWindow returns the active window covering any of the systems.
+// Suppressing windows take precedence over annotating ones.
+func FindActiveMaintenanceWindow(systems []string) *models.MaintenanceWindow {
+	if len(systems) == 0 {
+		return nil
+	}
+
+	var candidates []models.MaintenanceWindow
+	if err := db.DB.Where("services && ?::text[]", pq.StringArray(systems)).Find(&candidates).Error; err != nil {
+		log.Printf("⚠️  Failed to look up maintenance windows: %v", err)
+		return nil
+	}
+
+	now := time.Now()
+	var found *models.MaintenanceWindow
+	for i := range candidates {
+		w := &candidates[i]
+		if !MaintenanceWindowActiveAt(w, now) {
+			continue
+		}
+		w.Active = true
+		if w.Mode == MaintenanceModeSuppress {
+			return w
+		}
+		if found == nil {
+			found = w
+		}
+	}
+	return found
+}
+
+// MaintenanceBlocksAgent returns the active window that forbids agent actions on a system, if any
+func MaintenanceBlocksAgent(system string) *models.MaintenanceWindow {
+	var candidates []models.MaintenanceWindow
+	if err := db.DB.Where("? = ANY(services) AND allow_agent_actions = false", system).Find(&candidates).Error; err != nil {
+		log.Printf("⚠️  Failed to look up maintenance windows: %v", err)
+		return nil
+	}
+
+	now := time.Now()
+	for i := range candidates {
+		if MaintenanceWindowActiveAt(&candidates[i], now) {
+			candidates[i].Active = true
+			return &candidates[i]
+		}
+	}
+	return nil
+}
+
+// SuppressAlert records an alert swallowed by a maintenance window so it can be reviewed later
+func SuppressAlert(window *models.MaintenanceWindow, incident *models.Incident) (*models.SuppressedAlert, error) {
+	payload := map[string]interface{}{}
+	raw, _ := json.Marshal(incident)
+	json.Unmarshal(raw, &payload)
+
+	alert := &models.SuppressedAlert{
+		WindowID:        window.ID,
+		Message:         incident.Message,
+		Source:          incident.Source,
+		AffectedSystems: incident.AffectedSystems,
+		Payload:         models.JSONB{Data: payload},
+		ReceivedAt:      time.Now(),
+	}
+	if alert.AffectedSystems == nil {
+		alert.AffectedSystems = pq.StringArray{}
+	}
+
+	if err := db.DB.Create(alert).Error; err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔕 Suppressed alert from %s during maintenance window %s", incident.Source, window.ID.String()[:8])
+	return alert, nil
+}
+
+// AnnotateDuringMaintenance marks an incident's metadata as raised inside a maintenance window
+func AnnotateDuringMaintenance(window *models.MaintenanceWindow, incident *models.Incident) {
+	metadata, ok := incident.Metadata.Data.(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+	}
+	metadata["during_maintenance"] = true
+	metadata["maintenance_window_id"] = window.ID.String()
+	incident.Metadata = models.JSONB{Data: metadata}
+}
+
+// SuppressedAlertFilter narrows the suppressed alert listing
+type SuppressedAlertFilter struct {
+	WindowID   *uuid.UUID
+	Unreviewed bool
+}
+
+func GetSuppressedAlerts(filter SuppressedAlertFilter) ([]models.SuppressedAlert, error) {
+	query := db.DB.Model(&models.SuppressedAlert{})
+	if filter.WindowID != nil {
+		query = query.Where("window_id = ?", *filter.WindowID)
+	}
+	if filter.Unreviewed {
+		query = query.Where("reviewed_at IS NULL")
+	}
+
+	var alerts []models.SuppressedAlert
+	err := query.Order("received_at DESC").Find(&alerts).Error
+	return alerts, err
+}
+
+// ReviewSuppressedAlert dismisses a suppressed alert, or promotes it to a real incident
+func ReviewSuppressedAlert(id uuid.UUID, promote bool, actor string) (*models.SuppressedAlert, *models.Incident, error) {
+	var alert models.SuppressedAlert
+	if err := db.DB.First(&alert, "id = ?", id).Error; err != nil {
+		return nil, nil, fmt.Errorf("suppressed alert not found: %w", err)
+	}
+	if alert.ReviewedAt != nil {
+		return nil, nil, fmt.Errorf("suppressed alert was already reviewed")
+	}
+
+	var incident *models.Incident
+	if promote {
+		incident = &models.Incident{}
+		raw, _ := json.Marshal(alert.Payload.Data)
+		if err := json.Unmarshal(raw, incident); err != nil {
+			return nil, nil, fmt.Errorf("failed to restore suppressed alert: %w", err)
+		}
+		incident.ID = uuid.Nil
+		incident.CreatedAt = time.Time{}
+		incident.UpdatedAt = time.Time{}
+		incident.Status = "triage"
+		if incident.MetricsSnapshot == "" {
+			incident.MetricsSnapshot = "{}"
+		}
+		AnnotateDuringMaintenance(&models.MaintenanceWindow{ID: alert.WindowID}, incident)
+
+		if err := CreateIncident(incident); err != nil {
+			return nil, nil, fmt.Errorf("failed to create incident: %w", err)
+		}
+		alert.IncidentID = &incident.ID
+		alert.Resolution = "promoted"
+	} else {
+		alert.Resolution = "dismissed"
+	}
+
+	now := time.Now()
+	alert.ReviewedAt = &now
+	alert.ReviewedBy = actor
+	if err := db.DB.Save(&alert).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return &alert, incident, nil
+}
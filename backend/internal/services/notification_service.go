@@ -0,0 +1,495 @@
+package services
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	wshub "github.com/tri27pham/incident-management-simulator/backend/internal/websocket"
+	"gorm.io/gorm"
+)
+
+// NotificationChannels lists every channel a rule can route to
+var NotificationChannels = []string{
+	models.ChannelWebSocket,
+	models.ChannelEmail,
+	models.ChannelWebhook,
+	models.ChannelChat,
+}
+
+// eventTitles are human-readable names used in notification subjects
+var eventTitles = map[string]string{
+	events.IncidentCreated:       "New incident",
+	events.IncidentUpdated:       "Incident updated",
+	events.IncidentResolved:      "Incident resolved",
+	events.IncidentDeleted:       "Incident deleted",
+	events.IncidentAssigned:      "Incident assigned",
+	events.IncidentSLABreached:   "SLA breached",
+	events.IncidentUpdateDue:     "Stakeholder update due",
+	events.IncidentStale:         "Incident stale",
+	events.IncidentFlapping:      "Alert flapping",
+	events.AgentAwaitingApproval: "Approval needed",
+	events.AgentCompleted:        "Agent remediation completed",
+	events.AgentFailed:           "Agent remediation failed",
+}
+
+// EventTitle returns a human-readable name for an event type
+func EventTitle(eventType string) string {
+	if title, ok := eventTitles[eventType]; ok {
+		return title
+	}
+	return eventType
+}
+
+// notification is the channel-independent view of an event used for routing
+type notification struct {
+	Event       events.Event
+	Incident    *models.Incident
+	Severity    string
+	Fingerprint string
+	Title       string
+	Body        string
+	Flapping    bool // Alert is oscillating; only the flapping notice itself and approvals get through
+}
+
+var fingerprintDigits = regexp.MustCompile(`[0-9]+`)
+
+// IncidentFingerprint groups incidents that are "the same alert firing again":
+// same source, same systems and the same message once numbers are stripped out.
+func IncidentFingerprint(incident *models.Incident) string {
+	systems := append([]string{}, incident.AffectedSystems...)
+	sort.Strings(systems)
+	message := fingerprintDigits.ReplaceAllString(strings.ToLower(strings.TrimSpace(incident.Message)), "#")
+
+	sum := sha1.Sum([]byte(incident.Source + "|" + strings.Join(systems, ",") + "|" + message))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ValidateNotificationRule normalises and checks a rule before saving
+func ValidateNotificationRule(rule *models.NotificationRule) error {
+	rule.UserName = strings.TrimSpace(rule.UserName)
+	if rule.UserName == "" {
+		return fmt.Errorf("user_name is required")
+	}
+	if len(rule.Channels) == 0 {
+		return fmt.Errorf("at least one channel is required")
+	}
+	for _, ch := range rule.Channels {
+		if !containsString(NotificationChannels, ch) {
+			return fmt.Errorf("unknown channel: %s", ch)
+		}
+	}
+	for _, t := range rule.EventTypes {
+		if !events.IsKnownType(t) {
+			return fmt.Errorf("unknown event type: %s", t)
+		}
+	}
+	for _, sev := range rule.Severities {
+		if _, ok := SLATargets[sev]; !ok {
+			return fmt.Errorf("unknown severity: %s", sev)
+		}
+	}
+	for _, list := range []*pq.StringArray{&rule.EventTypes, &rule.Severities, &rule.Teams} {
+		if *list == nil {
+			*list = pq.StringArray{}
+		}
+	}
+	return nil
+}
+
+func CreateNotificationRule(rule *models.NotificationRule) error {
+	if err := ValidateNotificationRule(rule); err != nil {
+		return err
+	}
+	return db.DB.Create(rule).Error
+}
+
+func UpdateNotificationRule(rule *models.NotificationRule) error {
+	if err := ValidateNotificationRule(rule); err != nil {
+		return err
+	}
+	return db.DB.Save(rule).Error
+}
+
+// GetNotificationRules lists rules, for one user if userName is set
+func GetNotificationRules(userName string) ([]models.NotificationRule, error) {
+	var rules []models.NotificationRule
+	query := db.DB.Order("user_name ASC, created_at ASC")
+	if userName != "" {
+		query = query.Where("user_name = ?", userName)
+	}
+	err := query.Find(&rules).Error
+	return rules, err
+}
+
+func GetNotificationRuleByID(id uuid.UUID) (models.NotificationRule, error) {
+	var rule models.NotificationRule
+	err := db.DB.First(&rule, "id = ?", id).Error
+	return rule, err
+}
+
+func DeleteNotificationRule(id uuid.UUID) error {
+	result := db.DB.Delete(&models.NotificationRule{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("notification rule not found")
+	}
+	return nil
+}
+
+// NotificationAttemptFilter narrows the notification log
+type NotificationAttemptFilter struct {
+	UserName   string
+	IncidentID *uuid.UUID
+	EventType  string
+	Channel    string
+	Limit      int
+}
+
+// GetNotificationAttempts returns the notification log, newest first
+func GetNotificationAttempts(filter NotificationAttemptFilter) ([]models.NotificationAttempt, error) {
+	var attempts []models.NotificationAttempt
+	query := db.DB.Order("created_at DESC").Limit(filter.Limit)
+	if filter.UserName != "" {
+		query = query.Where("user_name = ?", filter.UserName)
+	}
+	if filter.IncidentID != nil {
+		query = query.Where("incident_id = ?", *filter.IncidentID)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+	err := query.Find(&attempts).Error
+	return attempts, err
+}
+
+// RouteNotifications is the single events.Handler that decides who is told about an event and how
+func RouteNotifications(event events.Event) {
+	n, ok := describeEvent(event)
+	if !ok {
+		return
+	}
+
+	var prefs []models.NotificationPreference
+	query := db.DB.Where("enabled = true")
+	if event.Type == events.IncidentAssigned {
+		// Assignment notifications only go to the new assignee
+		query = query.Where("user_name = ?", n.Incident.Assignee)
+	}
+	if err := query.Find(&prefs).Error; err != nil {
+		log.Printf("❌ Failed to load notification preferences for %s: %v", event.Type, err)
+		return
+	}
+
+	for _, pref := range prefs {
+		routes, err := matchNotificationRules(pref, n)
+		if err != nil {
+			log.Printf("❌ Failed to load notification rules for %s: %v", pref.UserName, err)
+			continue
+		}
+		for channel, rule := range routes {
+			deliverNotification(pref, rule, channel, n)
+		}
+	}
+}
+
+// describeEvent resolves the incident, severity and fingerprint an event is about
+func describeEvent(event events.Event) (*notification, bool) {
+	var incident models.Incident
+	switch data := event.Data.(type) {
+	case models.Incident:
+		incident = data
+	case models.AgentExecution:
+		var err error
+		if incident, err = GetIncidentByID(data.IncidentID); err != nil {
+			return nil, false
+		}
+	default:
+		return nil, false // e.g. incident.deleted only carries an ID
+	}
+
+	n := &notification{
+		Event:       event,
+		Incident:    &incident,
+		Fingerprint: IncidentFingerprint(&incident),
+		Title:       EventTitle(event.Type),
+		Body:        truncateText(incident.Message, 280),
+	}
+	if incident.Analysis != nil {
+		n.Severity = incident.Analysis.Severity
+	}
+	if event.Type != events.IncidentFlapping && event.Type != events.AgentAwaitingApproval {
+		n.Flapping = IncidentIsFlapping(&incident)
+	}
+	return n, true
+}
+
+// matchNotificationRules returns the channels a user should be notified on, with the rule that matched first.
+// Users without rules fall back to email for the event types in their preferences.
+func matchNotificationRules(pref models.NotificationPreference, n *notification) (map[string]*models.NotificationRule, error) {
+	var rules []models.NotificationRule
+	if err := db.DB.Where("user_name = ?", pref.UserName).Order("created_at ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	routes := map[string]*models.NotificationRule{}
+	if len(rules) == 0 {
+		if containsString(EmailEventTypes, n.Event.Type) && filterMatches(pref.EventTypes, n.Event.Type) {
+			routes[models.ChannelEmail] = nil
+		}
+		return routes, nil
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Enabled ||
+			!filterMatches(rule.EventTypes, n.Event.Type) ||
+			!filterMatches(rule.Severities, n.Severity) ||
+			!filterMatches(rule.Teams, n.Incident.Team) {
+			continue
+		}
+		for _, channel := range rule.Channels {
+			// Prefer a rule that bypasses quiet hours if several match the same channel
+			if existing, ok := routes[channel]; !ok || (existing != nil && !existing.BypassQuietHours && rule.BypassQuietHours) {
+				routes[channel] = rule
+			}
+		}
+	}
+	return routes, nil
+}
+
+// filterMatches treats an empty filter as "match everything"
+func filterMatches(filter pq.StringArray, value string) bool {
+	return len(filter) == 0 || containsString(filter, value)
+}
+
+// deliverNotification applies quiet hours and throttling, sends on one channel and records the attempt
+func deliverNotification(pref models.NotificationPreference, rule *models.NotificationRule, channel string, n *notification) {
+	attempt := models.NotificationAttempt{
+		UserName:   pref.UserName,
+		Channel:    channel,
+		EventID:    n.Event.ID,
+		EventType:  n.Event.Type,
+		IncidentID: &n.Incident.ID,
+		DedupKey:   channel + ":" + n.Fingerprint,
+		CreatedAt:  time.Now(),
+	}
+	if rule != nil {
+		attempt.RuleID = &rule.ID
+	}
+
+	if n.Flapping {
+		attempt.Status = models.NotificationSuppressed
+		attempt.Reason = "flapping"
+		recordNotificationAttempt(&attempt)
+		return
+	}
+
+	bypassQuiet := rule != nil && rule.BypassQuietHours
+	if channel != models.ChannelWebSocket && !bypassQuiet && inQuietHours(pref, attempt.CreatedAt) {
+		attempt.Status = models.NotificationSuppressed
+		attempt.Reason = "quiet_hours"
+		recordNotificationAttempt(&attempt)
+		return
+	}
+
+	// Approval requests are never throttled - each one needs its own decision
+	throttle := time.Duration(pref.ThrottleMinutes) * time.Minute
+	if n.Event.Type == events.AgentAwaitingApproval {
+		throttle = 0
+	}
+
+	// Serialise per user and dedup key so two events racing for the same alert can't both get through.
+	// Only the decision is made under the lock: the attempt is committed as queued, which the throttle
+	// counts, and the channel is used after the commit so a slow chat or mail server holds no lock.
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", pref.UserName+"|"+attempt.DedupKey).Error; err != nil {
+			return err
+		}
+		if throttle > 0 {
+			var recent int64
+			tx.Model(&models.NotificationAttempt{}).
+				Where("user_name = ? AND dedup_key = ? AND status IN ? AND created_at > ?",
+					pref.UserName, attempt.DedupKey,
+					[]string{models.NotificationSent, models.NotificationQueued},
+					attempt.CreatedAt.Add(-throttle)).
+				Count(&recent)
+			if recent > 0 {
+				attempt.Status = models.NotificationSuppressed
+				attempt.Reason = "throttled"
+				attempt.Detail = fmt.Sprintf("Already notified about this alert on %s in the last %s", channel, throttle)
+				return tx.Create(&attempt).Error
+			}
+		}
+		attempt.Status = models.NotificationQueued
+		return tx.Create(&attempt).Error
+	})
+	if err != nil {
+		log.Printf("❌ Failed to route %s to %s via %s: %v", n.Event.Type, pref.UserName, channel, err)
+		return
+	}
+	if attempt.Status == models.NotificationSuppressed {
+		return
+	}
+
+	reference, reason, err := sendOnChannel(pref, channel, n)
+	switch {
+	case reason != "":
+		attempt.Status = models.NotificationSuppressed
+		attempt.Reason = reason
+	case err != nil:
+		attempt.Status = models.NotificationFailed
+		attempt.Detail = err.Error()
+	case channel == models.ChannelEmail || channel == models.ChannelWebhook:
+		attempt.Status = models.NotificationQueued
+	default:
+		attempt.Status = models.NotificationSent
+	}
+	attempt.Reference = reference
+	if err := db.DB.Model(&attempt).Select("status", "reason", "detail", "reference").Updates(&attempt).Error; err != nil {
+		log.Printf("⚠️  Failed to record notification attempt for %s: %v", attempt.UserName, err)
+	}
+}
+
+func recordNotificationAttempt(attempt *models.NotificationAttempt) {
+	if err := db.DB.Create(attempt).Error; err != nil {
+		log.Printf("⚠️  Failed to record notification attempt for %s: %v", attempt.UserName, err)
+	}
+}
+
+// sendOnChannel performs the delivery. A non-empty reason means the channel could not be used for this user.
+func sendOnChannel(pref models.NotificationPreference, channel string, n *notification) (reference, reason string, err error) {
+	switch channel {
+	case models.ChannelWebSocket:
+		wshub.WSHub.Broadcast <- map[string]interface{}{
+			"type":        "notification",
+			"user":        pref.UserName,
+			"event_type":  n.Event.Type,
+			"title":       n.Title,
+			"body":        n.Body,
+			"severity":    n.Severity,
+			"incident_id": n.Incident.ID,
+		}
+		return "", "", nil
+
+	case models.ChannelEmail:
+		if pref.Email == "" {
+			return "", "no_address", nil
+		}
+		reference, err = queueEmailNotification(pref, n.Event)
+		return reference, "", err
+
+	case models.ChannelWebhook:
+		var subs []models.WebhookSubscription
+		if err := db.DB.Where("owner = ? AND enabled = true", pref.UserName).Find(&subs).Error; err != nil {
+			return "", "", err
+		}
+		if len(subs) == 0 {
+			return "", "no_address", nil
+		}
+		var refs []string
+		for _, sub := range subs {
+			delivery, err := enqueueWebhookDelivery(db.DB, sub.ID, n.Event)
+			if err != nil {
+				return strings.Join(refs, ","), "", err
+			}
+			refs = append(refs, delivery.ID.String())
+		}
+		return strings.Join(refs, ","), "", nil
+
+	case models.ChannelChat:
+		if pref.ChatWebhookURL == "" {
+			if !ChatOpsEnabled() {
+				return "", "no_address", nil
+			}
+			reason, err = postSharedChatMessage(n, buildNotificationChatMessage(pref.UserName, n, true))
+			return "", reason, err
+		}
+		body, _ := json.Marshal(buildNotificationChatMessage(pref.UserName, n, false))
+		if _, err := postChatMessage(pref.ChatWebhookURL, body); err != nil {
+			return "", "", err
+		}
+		return "", "", nil
+	}
+	return "", "", fmt.Errorf("unknown channel: %s", channel)
+}
+
+// buildNotificationChatMessage renders a routed notification for one user in Block Kit format. Approval
+// links decide as that user, so a message for the shared channel gets shared links instead.
+func buildNotificationChatMessage(userName string, n *notification, shared bool) map[string]interface{} {
+	if execution, ok := n.Event.Data.(models.AgentExecution); ok && n.Event.Type == events.AgentAwaitingApproval {
+		approver := userName
+		if shared {
+			approver = ""
+		}
+		return buildApprovalChatMessage(&execution, n.Incident, approver)
+	}
+
+	severity := n.Severity
+	if severity == "" {
+		severity = "untriaged"
+	}
+	return map[string]interface{}{
+		"text": fmt.Sprintf("%s: %s", n.Title, truncateText(n.Incident.Message, 150)),
+		"blocks": []interface{}{
+			chatHeader(n.Title),
+			chatSection(n.Body),
+			chatFields(
+				"*Severity*", severity,
+				"*Team*", n.Incident.Team,
+				"*Status*", n.Incident.Status,
+				"*Assignee*", n.Incident.Assignee,
+			),
+			chatContext(fmt.Sprintf("Sent to %s by notification rules · Incident %s", userName, n.Incident.ID)),
+		},
+	}
+}
+
+// inQuietHours reports whether t falls inside the user's quiet hours, in their timezone
+func inQuietHours(pref models.NotificationPreference, t time.Time) bool {
+	if pref.QuietHoursStart == "" || pref.QuietHoursEnd == "" {
+		return false
+	}
+	start, err1 := parseClock(pref.QuietHoursStart)
+	end, err2 := parseClock(pref.QuietHoursEnd)
+	if err1 != nil || err2 != nil || start == end {
+		return false
+	}
+
+	loc, err := time.LoadLocation(pref.Timezone)
+	if err != nil || pref.Timezone == "" {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	now := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end // Wraps past midnight, e.g. 22:00-07:00
+}
+
+// parseClock converts "HH:MM" into minutes since midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,487 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	wshub "github.com/tri27pham/incident-management-simulator/backend/internal/websocket"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	markdownStepPattern    = regexp.MustCompile(`^ {0,3}(\d+)[.)]\s+(.+)$`)
+	markdownHeadingPattern = regexp.MustCompile(`^#\s+(.+)$`)
+)
+
+// defaultRunbooks are seeded on startup for the real systems the health-monitor watches
+var defaultRunbooks = []models.Runbook{
+	{
+		Slug:     "redis-memory-pressure",
+		Title:    "Redis memory pressure",
+		Format:   "markdown",
+		Services: pq.StringArray{"redis-test"},
+		Sources:  pq.StringArray{"redis-test"},
+		Actions:  pq.StringArray{"clear_redis_cache", "restart_redis"},
+		Teams:    pq.StringArray{"Platform"},
+		Content: `# Redis memory pressure
+
+1. Confirm memory usage on the health-monitor status page
+   Check redis-test health and used_memory against the 50mb maxmemory limit.
+2. Identify the largest keyspaces
+   Run redis-cli --bigkeys against redis-test:6379.
+3. Check whether dependent services are erroring
+   Use the blast radius view for redis-test to see who is affected.
+4. Clear the cache if sessions can be safely dropped
+   Prefer the clear_redis_cache agent action over a restart.
+5. Confirm health is back above the threshold
+`,
+	},
+	{
+		Slug:     "postgres-connection-exhaustion",
+		Title:    "PostgreSQL connection exhaustion",
+		Format:   "markdown",
+		Services: pq.StringArray{"postgres-test"},
+		Sources:  pq.StringArray{"postgres-test"},
+		Actions:  pq.StringArray{"kill_idle_connections", "restart_postgres"},
+		Teams:    pq.StringArray{"Data"},
+		Content: `# PostgreSQL connection exhaustion
+
+1. Check active vs idle connections
+   Query pg_stat_activity grouped by state.
+2. Look for long-running transactions holding connections
+3. Terminate idle connections
+   Use the kill_idle_connections agent action.
+4. Restart PostgreSQL only if connections cannot be reclaimed
+5. Confirm the connection pool is healthy
+`,
+	},
+	{
+		Slug:     "postgres-table-bloat",
+		Title:    "PostgreSQL table bloat",
+		Format:   "markdown",
+		Services: pq.StringArray{"postgres-test"},
+		Sources:  pq.StringArray{"postgres-test"},
+		Actions:  pq.StringArray{"vacuum_table"},
+		Teams:    pq.StringArray{"Data"},
+		Content: `# PostgreSQL table bloat
+
+1. Check dead tuple counts in pg_stat_user_tables
+2. Confirm autovacuum is running
+3. Run VACUUM ANALYZE on the bloated table
+   Use the vacuum_table agent action.
+4. Confirm dead tuples have been reclaimed
+`,
+	},
+	{
+		Slug:     "disk-space-low",
+		Title:    "Disk space low",
+		Format:   "markdown",
+		Services: pq.StringArray{"disk-monitor"},
+		Sources:  pq.StringArray{"disk-monitor"},
+		Actions:  pq.StringArray{"cleanup_old_logs"},
+		Teams:    pq.StringArray{"Infrastructure"},
+		Content: `# Disk space low
+
+1. Confirm usage on the health-monitor status page
+2. Find the largest directories under /var/log
+3. Remove old log files
+   Use the cleanup_old_logs agent action.
+4. Confirm free space is above 100 MB
+`,
+	},
+}
+
+// SeedRunbooks creates version 1 of each default runbook that doesn't exist yet
+func SeedRunbooks() error {
+	for _, rb := range defaultRunbooks {
+		rb := rb
+		var count int64
+		if err := db.DB.Model(&models.Runbook{}).Where("slug = ?", rb.Slug).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		rb.CreatedBy = "system"
+		if err := CreateRunbookVersion(&rb); err != nil {
+			return fmt.Errorf("failed to seed runbook %s: %w", rb.Slug, err)
+		}
+	}
+	return nil
+}
+
+// ParseRunbookSteps extracts ordered steps from Markdown (a numbered list) or YAML (a "steps" list)
+func ParseRunbookSteps(format, content string) (string, []models.RunbookStep, error) {
+	switch format {
+	case "", "markdown":
+		title, steps := parseMarkdownRunbook(content)
+		if len(steps) == 0 {
+			return "", nil, fmt.Errorf("markdown runbook must contain a numbered list of steps")
+		}
+		return title, steps, nil
+	case "yaml":
+		return parseYAMLRunbook(content)
+	default:
+		return "", nil, fmt.Errorf("unsupported runbook format: %s", format)
+	}
+}
+
+// parseMarkdownRunbook treats each top-level numbered item as a step; following lines become its detail
+func parseMarkdownRunbook(content string) (string, []models.RunbookStep) {
+	var title string
+	var steps []models.RunbookStep
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if m := markdownHeadingPattern.FindStringSubmatch(trimmed); m != nil && title == "" && len(steps) == 0 {
+			title = strings.TrimSpace(m[1])
+			continue
+		}
+		if m := markdownStepPattern.FindStringSubmatch(line); m != nil {
+			steps = append(steps, models.RunbookStep{Index: len(steps), Title: strings.TrimSpace(m[2])})
+			continue
+		}
+		if len(steps) > 0 {
+			last := &steps[len(steps)-1]
+			if last.Detail != "" {
+				last.Detail += "\n"
+			}
+			last.Detail += trimmed
+		}
+	}
+
+	return title, steps
+}
+
+func parseYAMLRunbook(content string) (string, []models.RunbookStep, error) {
+	var doc struct {
+		Title string        `yaml:"title"`
+		Steps []interface{} `yaml:"steps"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return "", nil, fmt.Errorf("invalid YAML runbook: %w", err)
+	}
+
+	var steps []models.RunbookStep
+	for i, raw := range doc.Steps {
+		step := models.RunbookStep{Index: i}
+		switch v := raw.(type) {
+		case string:
+			step.Title = v
+		case map[string]interface{}:
+			step.Title, _ = v["title"].(string)
+			step.Detail, _ = v["detail"].(string)
+		}
+		if strings.TrimSpace(step.Title) == "" {
+			return "", nil, fmt.Errorf("step %d has no title", i+1)
+		}
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 {
+		return "", nil, fmt.Errorf("YAML runbook must define at least one step")
+	}
+
+	return doc.Title, steps, nil
+}
+
+// CreateRunbookVersion parses the runbook content and stores it as the next version of its slug
+func CreateRunbookVersion(runbook *models.Runbook) error {
+	if runbook.Slug == "" {
+		return fmt.Errorf("runbook slug is required")
+	}
+	if runbook.Format == "" {
+		runbook.Format = "markdown"
+	}
+
+	title, steps, err := ParseRunbookSteps(runbook.Format, runbook.Content)
+	if err != nil {
+		return err
+	}
+	if runbook.Title == "" {
+		runbook.Title = title
+	}
+	if runbook.Title == "" {
+		runbook.Title = runbook.Slug
+	}
+	runbook.Steps = models.JSONB{Data: steps}
+
+	for _, arr := range []*pq.StringArray{&runbook.Services, &runbook.Sources, &runbook.Actions, &runbook.Teams} {
+		if *arr == nil {
+			*arr = pq.StringArray{}
+		}
+	}
+
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&models.Runbook{}).
+			Where("slug = ?", runbook.Slug).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+
+		runbook.ID = uuid.Nil
+		runbook.Version = latest + 1
+		runbook.CreatedAt = time.Now()
+		if err := tx.Create(runbook).Error; err != nil {
+			return err
+		}
+
+		log.Printf("📘 Saved runbook %s v%d (%d steps)", runbook.Slug, runbook.Version, len(steps))
+		return nil
+	})
+}
+
+// RunbookFilter narrows the runbook listing
+type RunbookFilter struct {
+	Service string
+	Source  string
+	Action  string
+	Team    string
+}
+
+// GetLatestRunbooks returns the newest version of every runbook matching the filter
+func GetLatestRunbooks(filter RunbookFilter) ([]models.Runbook, error) {
+	query := db.DB.Where("version = (SELECT MAX(r2.version) FROM runbooks r2 WHERE r2.slug = runbooks.slug)")
+	if filter.Service != "" {
+		query = query.Where("? = ANY(services)", filter.Service)
+	}
+	if filter.Source != "" {
+		query = query.Where("? = ANY(sources)", filter.Source)
+	}
+	if filter.Action != "" {
+		query = query.Where("? = ANY(actions)", filter.Action)
+	}
+	if filter.Team != "" {
+		query = query.Where("? = ANY(teams)", filter.Team)
+	}
+
+	var runbooks []models.Runbook
+	err := query.Order("slug ASC").Find(&runbooks).Error
+	return runbooks, err
+}
+
+// GetRunbook returns a specific version of a runbook, or the latest when version is 0
+func GetRunbook(slug string, version int) (models.Runbook, error) {
+	var runbook models.Runbook
+	query := db.DB.Where("slug = ?", slug)
+	if version > 0 {
+		query = query.Where("version = ?", version)
+	}
+	err := query.Order("version DESC").First(&runbook).Error
+	return runbook, err
+}
+
+// GetRunbookVersions returns every version of a runbook, newest first
+func GetRunbookVersions(slug string) ([]models.Runbook, error) {
+	var runbooks []models.Runbook
+	err := db.DB.Where("slug = ?", slug).Order("version DESC").Find(&runbooks).Error
+	return runbooks, err
+}
+
+// SuggestRunbooks ranks runbooks for an incident by affected systems, catalog links, source and routed team
+func SuggestRunbooks(incident *models.Incident) ([]models.RunbookSuggestion, error) {
+	runbooks, err := GetLatestRunbooks(RunbookFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Runbooks linked from the catalog entries of affected systems
+	catalogLinks := make(map[string]string)
+	for _, sys := range incident.AffectedSystems {
+		if svc, err := GetServiceByName(sys); err == nil {
+			for _, slug := range svc.Runbooks {
+				catalogLinks[slug] = sys
+			}
+		}
+	}
+
+	var suggestions []models.RunbookSuggestion
+	for _, rb := range runbooks {
+		suggestion := models.RunbookSuggestion{Runbook: rb}
+
+		for _, sys := range incident.AffectedSystems {
+			if containsString(rb.Services, sys) {
+				suggestion.Score += 3
+				suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("covers affected system %s", sys))
+			}
+		}
+		if sys, ok := catalogLinks[rb.Slug]; ok {
+			suggestion.Score += 3
+			suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("linked from the %s catalog entry", sys))
+		}
+		if incident.Source != "" && containsString(rb.Sources, incident.Source) {
+			suggestion.Score += 2
+			suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("matches incident source %s", incident.Source))
+		}
+		if incident.Team != "" && containsString(rb.Teams, incident.Team) {
+			suggestion.Score++
+			suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("routed to team %s", incident.Team))
+		}
+
+		// A team match alone is too weak to be a useful suggestion
+		if suggestion.Score > 1 {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+	return suggestions, nil
+}
+
+// StartIncidentChecklist instantiates a runbook version as a checklist on an incident
+func StartIncidentChecklist(incidentID uuid.UUID, slug string, version int, actor string) (*models.IncidentChecklist, error) {
+	if _, err := GetIncidentByID(incidentID); err != nil {
+		return nil, fmt.Errorf("incident not found: %w", err)
+	}
+
+	runbook, err := GetRunbook(slug, version)
+	if err != nil {
+		return nil, fmt.Errorf("runbook not found: %w", err)
+	}
+
+	var runbookSteps []models.RunbookStep
+	stepsJSON, _ := json.Marshal(runbook.Steps.Data)
+	json.Unmarshal(stepsJSON, &runbookSteps)
+
+	steps := make([]models.ChecklistStep, 0, len(runbookSteps))
+	for _, s := range runbookSteps {
+		steps = append(steps, models.ChecklistStep{Index: s.Index, Title: s.Title, Detail: s.Detail})
+	}
+
+	checklist := &models.IncidentChecklist{
+		IncidentID:     incidentID,
+		RunbookID:      runbook.ID,
+		RunbookSlug:    runbook.Slug,
+		RunbookVersion: runbook.Version,
+		Title:          runbook.Title,
+		Steps:          models.JSONB{Data: steps},
+		StartedBy:      actor,
+	}
+	if err := db.DB.Create(checklist).Error; err != nil {
+		return nil, fmt.Errorf("failed to create checklist: %w", err)
+	}
+
+	RecordTimelineEvent(incidentID, "runbook_started", actor,
+		fmt.Sprintf("Started runbook \"%s\" (v%d)", runbook.Title, runbook.Version),
+		map[string]interface{}{"checklist_id": checklist.ID, "runbook_slug": runbook.Slug, "runbook_version": runbook.Version})
+
+	broadcastChecklistUpdate(checklist)
+	return checklist, nil
+}
+
+// GetIncidentChecklists returns all checklists started on an incident
+func GetIncidentChecklists(incidentID uuid.UUID) ([]models.IncidentChecklist, error) {
+	var checklists []models.IncidentChecklist
+	err := db.DB.Where("incident_id = ?", incidentID).Order("created_at ASC").Find(&checklists).Error
+	return checklists, err
+}
+
+// SetChecklistStep ticks or unticks a checklist step and records it on the incident timeline. The checklist
+// row is locked while its steps are rewritten, so people ticking different steps at once don't undo each other.
+func SetChecklistStep(incidentID, checklistID uuid.UUID, index int, completed bool, actor string) (*models.IncidentChecklist, error) {
+	var checklist models.IncidentChecklist
+	var step models.ChecklistStep
+	changed := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&checklist, "id = ? AND incident_id = ?", checklistID, incidentID).Error; err != nil {
+			return fmt.Errorf("checklist not found: %w", err)
+		}
+
+		var steps []models.ChecklistStep
+		stepsJSON, _ := json.Marshal(checklist.Steps.Data)
+		json.Unmarshal(stepsJSON, &steps)
+
+		if index < 0 || index >= len(steps) {
+			return fmt.Errorf("step %d does not exist", index)
+		}
+		if steps[index].Completed == completed {
+			return nil
+		}
+
+		now := time.Now()
+		if completed {
+			steps[index].Completed = true
+			steps[index].CompletedBy = actor
+			steps[index].CompletedAt = &now
+		} else {
+			steps[index].Completed = false
+			steps[index].CompletedBy = ""
+			steps[index].CompletedAt = nil
+		}
+		step = steps[index]
+
+		allDone := true
+		for _, s := range steps {
+			if !s.Completed {
+				allDone = false
+				break
+			}
+		}
+		if allDone {
+			checklist.CompletedAt = &now
+		} else {
+			checklist.CompletedAt = nil
+		}
+
+		checklist.Steps = models.JSONB{Data: steps}
+		changed = true
+		return tx.Save(&checklist).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return &checklist, nil
+	}
+
+	eventType, verb := "runbook_step_completed", "Completed"
+	if !completed {
+		eventType, verb = "runbook_step_reopened", "Reopened"
+	}
+	RecordTimelineEvent(incidentID, eventType, actor,
+		fmt.Sprintf("%s step %d of \"%s\": %s", verb, index+1, checklist.Title, step.Title),
+		map[string]interface{}{"checklist_id": checklist.ID, "step_index": index})
+
+	if checklist.CompletedAt != nil {
+		RecordTimelineEvent(incidentID, "runbook_completed", actor,
+			fmt.Sprintf("Completed all steps of \"%s\"", checklist.Title),
+			map[string]interface{}{"checklist_id": checklist.ID})
+	}
+
+	broadcastChecklistUpdate(&checklist)
+	return &checklist, nil
+}
+
+func broadcastChecklistUpdate(checklist *models.IncidentChecklist) {
+	wshub.WSHub.Broadcast <- map[string]interface{}{
+		"type":      "checklist_update",
+		"checklist": checklist,
+	}
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// People ticking different steps at the same time must all see their tick kept
+func TestSetChecklistStepConcurrentTicks(t *testing.T) {
+	openTestDB(t)
+	incident := seedIncident(t)
+
+	const stepCount = 8
+	steps := make([]models.ChecklistStep, stepCount)
+	for i := range steps {
+		steps[i] = models.ChecklistStep{Title: fmt.Sprintf("Step %d", i+1)}
+	}
+	checklist := &models.IncidentChecklist{
+		IncidentID: incident.ID,
+		RunbookID:  uuid.New(),
+		Title:      "Redis memory pressure",
+		Steps:      models.JSONB{Data: steps},
+	}
+	if err := db.DB.Create(checklist).Error; err != nil {
+		t.Fatalf("failed to seed checklist: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, stepCount)
+	for i := 0; i < stepCount; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			if _, err := SetChecklistStep(incident.ID, checklist.ID, index, true, fmt.Sprintf("responder-%d", index)); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("SetChecklistStep: %v", err)
+	}
+
+	var stored models.IncidentChecklist
+	if err := db.DB.First(&stored, "id = ?", checklist.ID).Error; err != nil {
+		t.Fatalf("failed to reload checklist: %v", err)
+	}
+	var got []models.ChecklistStep
+	raw, _ := json.Marshal(stored.Steps.Data)
+	json.Unmarshal(raw, &got)
+	for i, step := range got {
+		if !step.Completed || step.CompletedBy != fmt.Sprintf("responder-%d", i) {
+			t.Errorf("step %d = %+v, want it ticked by responder-%d", i, step, i)
+		}
+	}
+	if stored.CompletedAt == nil {
+		t.Error("checklist with every step ticked is not marked complete")
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// SLATargets is the time to resolve an incident by severity
+var SLATargets = map[string]time.Duration{
+	"high":   1 * time.Hour,
+	"medium": 4 * time.Hour,
+	"low":    24 * time.Hour,
+}
+
+const slaCheckInterval = time.Minute
+
+// StartSLAChecker periodically flags unresolved incidents that have passed their SLA target
+func StartSLAChecker() {
+	log.Printf("⏱️  SLA checker started (every %s)", slaCheckInterval)
+	ticker := time.NewTicker(slaCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := checkSLABreaches(time.Now()); err != nil {
+			log.Printf("❌ SLA check failed: %v", err)
+		}
+	}
+}
+
+func checkSLABreaches(now time.Time) error {
+	var incidents []models.Incident
+	if err := db.DB.
+		Preload("Analysis").
+		Where("status != ? AND sla_breached_at IS NULL", "resolved").
+		Find(&incidents).Error; err != nil {
+		return err
+	}
+
+	for _, incident := range incidents {
+		if incident.Analysis == nil {
+			continue // Not triaged yet, no severity to measure against
+		}
+		target, ok := SLATargets[incident.Analysis.Severity]
+		if !ok || now.Sub(incident.CreatedAt) < target {
+			continue
+		}
+
+		// Only the first checker to flag the incident publishes the breach
+		var outbox EventOutbox
+		flagged := false
+		if err := db.DB.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.Incident{}).
+				Where("id = ? AND sla_breached_at IS NULL", incident.ID).
+				Update("sla_breached_at", now)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			flagged = true
+			return outbox.AddIncident(tx, events.IncidentSLABreached, incident.ID)
+		}); err != nil || !flagged {
+			continue
+		}
+
+		log.Printf("⏰ Incident %s breached its %s SLA (%s)", incident.ID.String()[:8], incident.Analysis.Severity, target)
+		RecordTimelineEvent(incident.ID, "sla_breached", "system",
+			fmt.Sprintf("Unresolved after %s, the target for %s severity", target, incident.Analysis.Severity),
+			map[string]interface{}{"severity": incident.Analysis.Severity, "target_minutes": int(target.Minutes())})
+		outbox.Dispatch()
+	}
+	return nil
+}
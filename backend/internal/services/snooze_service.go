@@ -0,0 +1,306 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	wshub "github.com/tri27pham/incident-management-simulator/backend/internal/websocket"
+	"gorm.io/gorm"
+)
+
+const (
+	maxSnoozeDuration  = 30 * 24 * time.Hour
+	snoozeWakeInterval = 30 * time.Second
+	staleCheckInterval = 5 * time.Minute
+)
+
+// defaultStaleThreshold applies to teams without their own threshold (STALE_INCIDENT_HOURS, default 24)
+func defaultStaleThreshold() time.Duration {
+	if hours, err := strconv.Atoi(os.Getenv("STALE_INCIDENT_HOURS")); err == nil && hours > 0 {
+		return time.Duration(hours) * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// SnoozeIncident hides an unresolved incident from the board until the given time
+func SnoozeIncident(id uuid.UUID, until time.Time, reason, actor string) (*models.Incident, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+	now := time.Now()
+	if !until.After(now) {
+		return nil, fmt.Errorf("until must be in the future")
+	}
+	if until.Sub(now) > maxSnoozeDuration {
+		return nil, fmt.Errorf("incidents can be snoozed for at most %d days", int(maxSnoozeDuration.Hours()/24))
+	}
+
+	incident, err := GetIncidentByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("incident not found")
+	}
+	if incident.Status == "resolved" {
+		return nil, fmt.Errorf("resolved incidents cannot be snoozed")
+	}
+
+	var outbox EventOutbox
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Incident{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"snoozed_until":    until,
+			"snooze_reason":    reason,
+			"snoozed_by":       actor,
+			"stale_flagged_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return outbox.AddIncident(tx, events.IncidentUpdated, id)
+	}); err != nil {
+		return nil, err
+	}
+
+	RecordTimelineEvent(id, "snoozed", actor,
+		fmt.Sprintf("Snoozed until %s: %s", until.UTC().Format("Jan 2 15:04 UTC"), reason),
+		map[string]interface{}{"snoozed_until": until, "reason": reason})
+	log.Printf("💤 Incident %s snoozed until %s by %s", id.String()[:8], until.UTC().Format(time.RFC3339), actor)
+
+	BroadcastIncidentUpdate(id)
+	outbox.Dispatch()
+
+	updated, err := GetIncidentByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// UnsnoozeIncident wakes a snoozed incident early
+func UnsnoozeIncident(id uuid.UUID, actor string) (*models.Incident, error) {
+	incident, err := GetIncidentByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("incident not found")
+	}
+	if incident.SnoozedUntil == nil {
+		return nil, fmt.Errorf("incident is not snoozed")
+	}
+
+	if !wakeIncident(incident, actor, "Woken early") {
+		return nil, fmt.Errorf("incident was already woken")
+	}
+
+	updated, err := GetIncidentByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// wakeIncident clears a snooze, returning false if someone else already did
+func wakeIncident(incident models.Incident, actor, message string) bool {
+	var outbox EventOutbox
+	woken := false
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Incident{}).
+			Where("id = ? AND snoozed_until = ?", incident.ID, incident.SnoozedUntil).
+			Updates(map[string]interface{}{
+				"snoozed_until": nil,
+				"snooze_reason": "",
+				"snoozed_by":    "",
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		woken = true
+		return outbox.AddIncident(tx, events.IncidentUpdated, incident.ID)
+	}); err != nil || !woken {
+		return false
+	}
+
+	RecordTimelineEvent(incident.ID, "unsnoozed", actor,
+		fmt.Sprintf("%s (was snoozed: %s)", message, incident.SnoozeReason),
+		map[string]interface{}{"snoozed_until": incident.SnoozedUntil, "reason": incident.SnoozeReason})
+	log.Printf("⏰ Incident %s woken from snooze by %s", incident.ID.String()[:8], actor)
+
+	wshub.WSHub.Broadcast <- map[string]interface{}{
+		"type":        "incident_woken",
+		"incident_id": incident.ID,
+		"message":     truncateText(incident.Message, 120),
+		"reason":      incident.SnoozeReason,
+		"snoozed_by":  incident.SnoozedBy,
+	}
+	BroadcastIncidentUpdate(incident.ID)
+	outbox.Dispatch()
+	return true
+}
+
+// StartSnoozeWaker returns snoozed incidents to the board when their snooze expires
+func StartSnoozeWaker() {
+	log.Printf("💤 Snooze waker started (every %s)", snoozeWakeInterval)
+	ticker := time.NewTicker(snoozeWakeInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		incidents, err := dueSnoozedIncidents(time.Now())
+		if err != nil {
+			log.Printf("❌ Failed to load snoozed incidents: %v", err)
+			continue
+		}
+		for _, incident := range incidents {
+			wakeIncident(incident, "system", "Snooze expired")
+		}
+	}
+}
+
+// dueSnoozedIncidents lists unresolved incidents whose snooze has run out by now
+func dueSnoozedIncidents(now time.Time) ([]models.Incident, error) {
+	var incidents []models.Incident
+	err := db.DB.
+		Where("status != ? AND snoozed_until IS NOT NULL AND snoozed_until <= ?", "resolved", now).
+		Find(&incidents).Error
+	return incidents, err
+}
+
+// GetStaleThresholds lists team-specific thresholds
+func GetStaleThresholds() ([]models.StaleThreshold, error) {
+	var thresholds []models.StaleThreshold
+	err := db.DB.Order("team ASC").Find(&thresholds).Error
+	return thresholds, err
+}
+
+// DefaultStaleThresholdHours is the threshold for teams without their own
+func DefaultStaleThresholdHours() int {
+	return int(defaultStaleThreshold().Hours())
+}
+
+// SaveStaleThreshold creates or replaces a team's threshold
+func SaveStaleThreshold(threshold *models.StaleThreshold) error {
+	threshold.Team = strings.TrimSpace(threshold.Team)
+	if threshold.Team == "" {
+		return fmt.Errorf("team is required")
+	}
+	if threshold.ThresholdHours < 1 || threshold.ThresholdHours > 24*30 {
+		return fmt.Errorf("threshold_hours must be between 1 and %d", 24*30)
+	}
+	return db.DB.Save(threshold).Error
+}
+
+// DeleteStaleThreshold returns a team to the default threshold
+func DeleteStaleThreshold(team string) error {
+	result := db.DB.Where("team = ?", team).Delete(&models.StaleThreshold{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no threshold configured for team %s", team)
+	}
+	return nil
+}
+
+// StartStaleIncidentChecker flags incidents whose status has not changed within their team's threshold
+func StartStaleIncidentChecker() {
+	log.Printf("🕸️  Stale incident checker started (every %s)", staleCheckInterval)
+	ticker := time.NewTicker(staleCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := checkStaleIncidents(time.Now()); err != nil {
+			log.Printf("❌ Stale incident check failed: %v", err)
+		}
+	}
+}
+
+func checkStaleIncidents(now time.Time) error {
+	thresholds, err := GetStaleThresholds()
+	if err != nil {
+		return err
+	}
+	byTeam := map[string]models.StaleThreshold{}
+	for _, t := range thresholds {
+		byTeam[t.Team] = t
+	}
+
+	var incidents []models.Incident
+	if err := db.DB.
+		Preload("StatusHistory").
+		Where("status != ? AND stale_flagged_at IS NULL AND snoozed_until IS NULL", "resolved").
+		Find(&incidents).Error; err != nil {
+		return err
+	}
+
+	for _, incident := range incidents {
+		threshold := defaultStaleThreshold()
+		if t, ok := byTeam[incident.Team]; ok {
+			if !t.Enabled {
+				continue
+			}
+			threshold = time.Duration(t.ThresholdHours) * time.Hour
+		}
+
+		since := lastStatusChange(incident)
+		if now.Sub(since) < threshold {
+			continue
+		}
+
+		// Only the first checker to flag the incident reports it
+		var outbox EventOutbox
+		flagged := false
+		if err := db.DB.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.Incident{}).
+				Where("id = ? AND stale_flagged_at IS NULL", incident.ID).
+				UpdateColumn("stale_flagged_at", now)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			flagged = true
+			return outbox.AddIncident(tx, events.IncidentStale, incident.ID)
+		}); err != nil || !flagged {
+			continue
+		}
+
+		hours := int(now.Sub(since).Hours())
+		log.Printf("🕸️  Incident %s has been %s for %dh (team %s threshold %s)", incident.ID.String()[:8], incident.Status, hours, incident.Team, threshold)
+		RecordTimelineEvent(incident.ID, "stale", "system",
+			fmt.Sprintf("No status change for %d hours (threshold for %s is %d hours)", hours, incident.Team, int(threshold.Hours())),
+			map[string]interface{}{"status": incident.Status, "hours_in_status": hours, "threshold_hours": int(threshold.Hours())})
+
+		wshub.WSHub.Broadcast <- map[string]interface{}{
+			"type":            "incident_stale",
+			"incident_id":     incident.ID,
+			"message":         truncateText(incident.Message, 120),
+			"status":          incident.Status,
+			"team":            incident.Team,
+			"assignee":        incident.Assignee,
+			"hours_in_status": hours,
+		}
+		outbox.Dispatch()
+	}
+	return nil
+}
+
+// lastStatusChange is when the incident entered its current status, or woke from its last snooze if later
+func lastStatusChange(incident models.Incident) time.Time {
+	since := incident.CreatedAt
+	for _, h := range incident.StatusHistory {
+		if h.ChangedAt.After(since) {
+			since = h.ChangedAt
+		}
+	}
+
+	// Time spent snoozed doesn't count towards staleness
+	var woken models.TimelineEntry
+	if err := db.DB.
+		Where("incident_id = ? AND event_type = ?", incident.ID, "unsnoozed").
+		Order("created_at DESC").
+		First(&woken).Error; err == nil && woken.CreatedAt.After(since) {
+		since = woken.CreatedAt
+	}
+	return since
+}
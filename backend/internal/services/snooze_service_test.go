@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// Resolving a snoozed incident drops the snooze, so the waker never announces it again
+func TestResolvingClearsSnooze(t *testing.T) {
+	openTestDB(t)
+	incident := seedIncident(t)
+	if _, err := SnoozeIncident(incident.ID, time.Now().Add(time.Hour), "waiting on vendor", "alice"); err != nil {
+		t.Fatalf("SnoozeIncident: %v", err)
+	}
+
+	resolved, err := UpdateIncidentStatus(incident.ID, "resolved")
+	if err != nil {
+		t.Fatalf("UpdateIncidentStatus: %v", err)
+	}
+	if resolved.SnoozedUntil != nil || resolved.SnoozeReason != "" || resolved.SnoozedBy != "" {
+		t.Errorf("resolved incident still snoozed: until=%v reason=%q by=%q",
+			resolved.SnoozedUntil, resolved.SnoozeReason, resolved.SnoozedBy)
+	}
+
+	due, err := dueSnoozedIncidents(time.Now().Add(2 * time.Hour))
+	if err != nil {
+		t.Fatalf("dueSnoozedIncidents: %v", err)
+	}
+	if len(due) != 0 {
+		t.Errorf("waker would wake %d resolved incident(s)", len(due))
+	}
+}
+
+// Incidents resolved before snoozes were cleared on resolve are skipped by the waker
+func TestWakerSkipsResolvedIncidents(t *testing.T) {
+	openTestDB(t)
+	open := seedIncident(t)
+	closed := seedIncident(t)
+	expired := time.Now().Add(-time.Minute)
+	if err := db.DB.Model(&models.Incident{}).Where("id IN ?", []interface{}{open.ID, closed.ID}).
+		Update("snoozed_until", expired).Error; err != nil {
+		t.Fatalf("failed to snooze incidents: %v", err)
+	}
+	if err := db.DB.Model(closed).Update("status", "resolved").Error; err != nil {
+		t.Fatalf("failed to resolve incident: %v", err)
+	}
+
+	due, err := dueSnoozedIncidents(time.Now())
+	if err != nil {
+		t.Fatalf("dueSnoozedIncidents: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != open.ID {
+		t.Errorf("due = %v, want only the open incident %s", due, open.ID)
+	}
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	wshub "github.com/tri27pham/incident-management-simulator/backend/internal/websocket"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultUpdateCadences seeds the stakeholder communication policy: high severity every 30 minutes
+var defaultUpdateCadences = []models.UpdateCadence{
+	{Severity: "high", IntervalMinutes: 30, Enabled: true},
+	{Severity: "medium", IntervalMinutes: 60, Enabled: true},
+	{Severity: "low", IntervalMinutes: 240, Enabled: false},
+}
+
+const (
+	updateReminderInterval = time.Minute
+	minCadenceMinutes      = 5
+	maxCadenceMinutes      = 24 * 60
+	draftTimelineEntries   = 20
+	draftTimeout           = 30 * time.Second // A slow AI service falls back to the template rather than stall the request
+)
+
+// SeedUpdateCadences inserts the default cadences without touching ones that were edited
+func SeedUpdateCadences() error {
+	for _, cadence := range defaultUpdateCadences {
+		cadence := cadence
+		if err := db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&cadence).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetUpdateCadences lists the cadence for every severity
+func GetUpdateCadences() ([]models.UpdateCadence, error) {
+	var cadences []models.UpdateCadence
+	err := db.DB.Order("interval_minutes ASC").Find(&cadences).Error
+	return cadences, err
+}
+
+// SaveUpdateCadence creates or replaces the cadence for a severity
+func SaveUpdateCadence(cadence *models.UpdateCadence) error {
+	if _, ok := SLATargets[cadence.Severity]; !ok {
+		return fmt.Errorf("unknown severity: %s", cadence.Severity)
+	}
+	if cadence.IntervalMinutes < minCadenceMinutes || cadence.IntervalMinutes > maxCadenceMinutes {
+		return fmt.Errorf("interval_minutes must be between %d and %d", minCadenceMinutes, maxCadenceMinutes)
+	}
+	return db.DB.Save(cadence).Error
+}
+
+func getUpdateCadence(severity string) (models.UpdateCadence, bool) {
+	var cadence models.UpdateCadence
+	if err := db.DB.Where("severity = ? AND enabled = true", severity).First(&cadence).Error; err != nil {
+		return cadence, false
+	}
+	return cadence, true
+}
+
+// StakeholderUpdateStatus describes an incident's communication history and when the next update is due
+type StakeholderUpdateStatus struct {
+	Updates         []models.StakeholderUpdate `json:"updates"`
+	CadenceMinutes  int                        `json:"cadence_minutes"` // 0 = no cadence applies
+	LastUpdateAt    *time.Time                 `json:"last_update_at"`
+	NextUpdateDueAt *time.Time                 `json:"next_update_due_at"`
+	Overdue         bool                       `json:"overdue"`
+}
+
+// GetStakeholderUpdateStatus returns an incident's updates, newest first, with its cadence clock
+func GetStakeholderUpdateStatus(incidentID uuid.UUID) (StakeholderUpdateStatus, error) {
+	incident, err := GetIncidentByID(incidentID)
+	if err != nil {
+		return StakeholderUpdateStatus{}, err
+	}
+
+	status := StakeholderUpdateStatus{LastUpdateAt: incident.LastStakeholderUpdateAt}
+	if err := db.DB.Where("incident_id = ?", incidentID).Order("created_at DESC").Find(&status.Updates).Error; err != nil {
+		return status, err
+	}
+
+	if incident.Status == "resolved" || incident.Analysis == nil {
+		return status, nil
+	}
+	cadence, ok := getUpdateCadence(incident.Analysis.Severity)
+	if !ok {
+		return status, nil
+	}
+	status.CadenceMinutes = cadence.IntervalMinutes
+	due := updateClockStart(&incident).Add(time.Duration(cadence.IntervalMinutes) * time.Minute)
+	status.NextUpdateDueAt = &due
+	status.Overdue = time.Now().After(due)
+	return status, nil
+}
+
+// updateClockStart is when the cadence clock last reset: the last update, or the incident opening
+func updateClockStart(incident *models.Incident) time.Time {
+	if incident.LastStakeholderUpdateAt != nil {
+		return *incident.LastStakeholderUpdateAt
+	}
+	return incident.CreatedAt
+}
+
+// PostStakeholderUpdate records an update that went out to stakeholders and resets the cadence clock
+func PostStakeholderUpdate(incidentID uuid.UUID, message, actor string, aiDrafted bool) (*models.StakeholderUpdate, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	if _, err := GetIncidentByID(incidentID); err != nil {
+		return nil, fmt.Errorf("incident not found")
+	}
+
+	update := models.StakeholderUpdate{
+		IncidentID: incidentID,
+		Message:    message,
+		PostedBy:   actor,
+		AIDrafted:  aiDrafted,
+		CreatedAt:  time.Now(),
+	}
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&update).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Incident{}).
+			Where("id = ?", incidentID).
+			Update("last_stakeholder_update_at", update.CreatedAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	RecordTimelineEvent(incidentID, "stakeholder_update", actor, update.Message,
+		map[string]interface{}{"update_id": update.ID, "ai_drafted": aiDrafted})
+	BroadcastIncidentUpdate(incidentID)
+	return &update, nil
+}
+
+// StartStakeholderUpdateScheduler reminds responders when an open incident has gone too long without an update
+func StartStakeholderUpdateScheduler() {
+	log.Printf("📣 Stakeholder update scheduler started (every %s)", updateReminderInterval)
+	ticker := time.NewTicker(updateReminderInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := checkStakeholderUpdates(time.Now()); err != nil {
+			log.Printf("❌ Stakeholder update check failed: %v", err)
+		}
+	}
+}
+
+func checkStakeholderUpdates(now time.Time) error {
+	var cadences []models.UpdateCadence
+	if err := db.DB.Where("enabled = true").Find(&cadences).Error; err != nil {
+		return err
+	}
+	if len(cadences) == 0 {
+		return nil
+	}
+	intervals := map[string]time.Duration{}
+	for _, cadence := range cadences {
+		intervals[cadence.Severity] = time.Duration(cadence.IntervalMinutes) * time.Minute
+	}
+
+	var incidents []models.Incident
+	if err := db.DB.Preload("Analysis").Where("status != ? AND snoozed_until IS NULL", "resolved").Find(&incidents).Error; err != nil {
+		return err
+	}
+
+	for _, incident := range incidents {
+		if incident.Analysis == nil {
+			continue // Not triaged yet, no severity to pick a cadence
+		}
+		interval, ok := intervals[incident.Analysis.Severity]
+		if !ok {
+			continue
+		}
+
+		// Remind once per interval while overdue: measured from the last update or the last reminder, whichever is later
+		since := updateClockStart(&incident)
+		last := since
+		if incident.LastUpdateReminderAt != nil && incident.LastUpdateReminderAt.After(last) {
+			last = *incident.LastUpdateReminderAt
+		}
+		if now.Sub(since) < interval || now.Sub(last) < interval {
+			continue
+		}
+
+		// Only the first scheduler to claim the reminder sends it
+		var outbox EventOutbox
+		claimed := false
+		if err := db.DB.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.Incident{}).
+				Where("id = ? AND last_update_reminder_at IS NOT DISTINCT FROM ?", incident.ID, incident.LastUpdateReminderAt).
+				UpdateColumn("last_update_reminder_at", now)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			claimed = true
+			return outbox.AddIncident(tx, events.IncidentUpdateDue, incident.ID)
+		}); err != nil || !claimed {
+			continue
+		}
+
+		overdue := int(now.Sub(since).Minutes())
+		log.Printf("📣 Incident %s has had no stakeholder update for %d minutes (cadence %s)", incident.ID.String()[:8], overdue, interval)
+		RecordTimelineEvent(incident.ID, "stakeholder_update_due", "system",
+			fmt.Sprintf("No stakeholder update for %d minutes; %s severity incidents need one every %d minutes", overdue, incident.Analysis.Severity, int(interval.Minutes())),
+			map[string]interface{}{"severity": incident.Analysis.Severity, "cadence_minutes": int(interval.Minutes()), "minutes_since_update": overdue})
+
+		wshub.WSHub.Broadcast <- map[string]interface{}{
+			"type":                 "stakeholder_update_due",
+			"incident_id":          incident.ID,
+			"message":              truncateText(incident.Message, 120),
+			"severity":             incident.Analysis.Severity,
+			"assignee":             incident.Assignee,
+			"minutes_since_update": overdue,
+		}
+		outbox.Dispatch()
+	}
+	return nil
+}
+
+// StakeholderUpdateDraft is suggested update text for a responder to review before posting
+type StakeholderUpdateDraft struct {
+	Message  string `json:"message"`
+	Provider string `json:"provider"` // AI provider, or "fallback" when the AI service was unavailable
+}
+
+// DraftStakeholderUpdate asks the AI service to write an update from the incident's latest timeline
+func DraftStakeholderUpdate(ctx context.Context, incidentID uuid.UUID) (StakeholderUpdateDraft, error) {
+	incident, err := GetIncidentByID(incidentID)
+	if err != nil {
+		return StakeholderUpdateDraft{}, fmt.Errorf("incident not found")
+	}
+	timeline, err := GetIncidentTimeline(incidentID)
+	if err != nil {
+		return StakeholderUpdateDraft{}, err
+	}
+	if len(timeline) > draftTimelineEntries {
+		timeline = timeline[len(timeline)-draftTimelineEntries:]
+	}
+
+	draft, provider, err := callAgentThink(ctx, buildStakeholderUpdatePrompt(&incident, timeline))
+	if err != nil || draft == "" {
+		log.Printf("⚠️  AI draft for stakeholder update on %s failed, using fallback: %v", incidentID.String()[:8], err)
+		return StakeholderUpdateDraft{Message: fallbackStakeholderUpdate(&incident, timeline), Provider: "fallback"}, nil
+	}
+	return StakeholderUpdateDraft{Message: draft, Provider: provider}, nil
+}
+
+func buildStakeholderUpdatePrompt(incident *models.Incident, timeline []models.TimelineEntry) string {
+	severity := "untriaged"
+	if incident.Analysis != nil && incident.Analysis.Severity != "" {
+		severity = incident.Analysis.Severity
+	}
+
+	var b strings.Builder
+	b.WriteString("You are an incident commander writing a short update for non-technical stakeholders.\n\n")
+	fmt.Fprintf(&b, "Incident: %s\nSeverity: %s\nStatus: %s\nTeam: %s\n", incident.Message, severity, incident.Status, incident.Team)
+	if len(incident.AffectedSystems) > 0 {
+		fmt.Fprintf(&b, "Affected systems: %s\n", strings.Join(incident.AffectedSystems, ", "))
+	}
+	fmt.Fprintf(&b, "Opened: %s\n\nRecent timeline (oldest first):\n", incident.CreatedAt.UTC().Format("15:04 UTC"))
+	for _, entry := range timeline {
+		fmt.Fprintf(&b, "- %s [%s] %s\n", entry.CreatedAt.UTC().Format("15:04"), entry.Actor, entry.Message)
+	}
+	b.WriteString(`
+Write 3-5 plain sentences covering current impact, what has been done, and what happens next.
+Do not speculate beyond the timeline. Do not include internal hostnames or commands.
+Respond ONLY with JSON: {"update": "<text>"}`)
+	return b.String()
+}
+
+// fallbackStakeholderUpdate builds a plain template update when the AI service is unavailable
+func fallbackStakeholderUpdate(incident *models.Incident, timeline []models.TimelineEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "We are continuing to work on: %s. The incident is currently in %s and owned by the %s team.",
+		truncateText(incident.Message, 160), incident.Status, incident.Team)
+	if len(timeline) > 0 {
+		latest := timeline[len(timeline)-1]
+		fmt.Fprintf(&b, " Latest progress (%s): %s.", latest.CreatedAt.UTC().Format("15:04 UTC"), strings.TrimSuffix(latest.Message, "."))
+	}
+	b.WriteString(" We will share another update shortly.")
+	return b.String()
+}
+
+// callAgentThink sends a free-form prompt to the AI service and unwraps a {"update": ...} JSON reply
+func callAgentThink(ctx context.Context, prompt string) (string, string, error) {
+	reqBody, err := json.Marshal(map[string]string{"prompt": prompt})
+	if err != nil {
+		return "", "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, draftTimeout)
+	defer cancel()
+	resp, err := postAIService(ctx, "/api/v1/agent-think", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to call AI service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read AI service response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("AI service returned non-OK status: %s", resp.Status)
+	}
+
+	var result struct {
+		Response string `json:"response"`
+		Provider string `json:"provider"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", "", fmt.Errorf("failed to parse AI service response: %w", err)
+	}
+	if result.Provider == "error" {
+		return "", "", fmt.Errorf("AI service unavailable")
+	}
+
+	var draft struct {
+		Update string `json:"update"`
+	}
+	if err := json.Unmarshal([]byte(result.Response), &draft); err != nil || draft.Update == "" {
+		// Model ignored the JSON instruction; plain text is still usable
+		return strings.TrimSpace(result.Response), result.Provider, nil
+	}
+	return strings.TrimSpace(draft.Update), result.Provider, nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+func statusPageTitle() string {
+	if title := os.Getenv("STATUS_PAGE_TITLE"); title != "" {
+		return title
+	}
+	return "Service Status"
+}
+
+// statusPageURL is the human-facing status page; defaults to the public JSON API
+func statusPageURL() string {
+	if url := os.Getenv("STATUS_PAGE_URL"); url != "" {
+		return strings.TrimRight(url, "/")
+	}
+	return publicBaseURL() + "/api/v1/public/status"
+}
+
+func postStatusLabel(status string) string {
+	switch status {
+	case models.PostInvestigating:
+		return "Investigating"
+	case models.PostIdentified:
+		return "Identified"
+	case models.PostMonitoring:
+		return "Monitoring"
+	case models.PostResolved:
+		return "Resolved"
+	}
+	return status
+}
+
+func publicPostURL(post PublicPost) string {
+	return fmt.Sprintf("%s/api/v1/public/status/posts/%s", publicBaseURL(), post.ID)
+}
+
+// feedEntryBody renders a post's updates, newest first, as plain text
+func feedEntryBody(post PublicPost) string {
+	var b strings.Builder
+	if len(post.Components) > 0 {
+		fmt.Fprintf(&b, "Affected: %s\n\n", strings.Join(post.Components, ", "))
+	}
+	for _, u := range post.Updates {
+		fmt.Fprintf(&b, "%s - %s: %s\n\n", u.CreatedAt.UTC().Format("Jan 2, 15:04 UTC"), postStatusLabel(u.Status), u.Message)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// RenderStatusAtom renders posts as an Atom 1.0 feed
+func RenderStatusAtom(posts []PublicPost) ([]byte, error) {
+	updated := time.Now().UTC()
+	if len(posts) > 0 {
+		updated = posts[0].UpdatedAt.UTC()
+	}
+
+	feed := atomFeed{
+		Title:   statusPageTitle(),
+		ID:      statusPageURL(),
+		Updated: updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: statusPageURL()},
+			{Href: publicBaseURL() + "/api/v1/public/status/feed.atom", Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+	for _, post := range posts {
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   fmt.Sprintf("[%s] %s", postStatusLabel(post.Status), post.Title),
+			ID:      "urn:uuid:" + post.ID.String(),
+			Updated: post.UpdatedAt.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: publicPostURL(post)},
+			Content: atomContent{Type: "text", Body: feedEntryBody(post)},
+		})
+	}
+
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RenderStatusRSS renders posts as an RSS 2.0 feed
+func RenderStatusRSS(posts []PublicPost) ([]byte, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         statusPageTitle(),
+			Link:          statusPageURL(),
+			Description:   "Incidents and status updates",
+			LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, post := range posts {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       fmt.Sprintf("[%s] %s", postStatusLabel(post.Status), post.Title),
+			Link:        publicPostURL(post),
+			GUID:        rssGUID{IsPermaLink: false, Value: post.ID.String() + "@" + post.UpdatedAt.UTC().Format(time.RFC3339)},
+			PubDate:     post.UpdatedAt.UTC().Format(time.RFC1123Z),
+			Description: feedEntryBody(post),
+		})
+	}
+
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
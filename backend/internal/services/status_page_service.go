@@ -0,0 +1,542 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// componentStatusRank orders component statuses from best to worst
+var componentStatusRank = map[string]int{
+	models.ComponentOperational:   0,
+	models.ComponentDegraded:      1,
+	models.ComponentPartialOutage: 2,
+	models.ComponentMajorOutage:   3,
+}
+
+// severityComponentStatus is the status of a component directly hit by an incident of a given severity
+var severityComponentStatus = map[string]string{
+	"high":   models.ComponentMajorOutage,
+	"medium": models.ComponentPartialOutage,
+	"low":    models.ComponentDegraded,
+}
+
+var overallStatusDescriptions = map[string]string{
+	models.ComponentOperational:   "All systems operational",
+	models.ComponentDegraded:      "Degraded performance",
+	models.ComponentPartialOutage: "Partial outage",
+	models.ComponentMajorOutage:   "Major outage",
+}
+
+var postStatuses = []string{models.PostInvestigating, models.PostIdentified, models.PostMonitoring, models.PostResolved}
+
+// defaultStatusComponents maps customer-facing components onto the default service catalog
+var defaultStatusComponents = []models.StatusComponent{
+	{Name: "Website & API", Description: "Public API and web app", Services: pq.StringArray{"api-gateway", "edge-cdn", "cloudflare-cdn"}, DisplayOrder: 1},
+	{Name: "Accounts & Login", Description: "Sign-in, sessions and account management", Services: pq.StringArray{"user-service"}, DisplayOrder: 2},
+	{Name: "Payments & Billing", Description: "Card payments, invoices and subscriptions", Services: pq.StringArray{"payment-gateway", "billing-service"}, DisplayOrder: 3},
+	{Name: "Email Delivery", Description: "Transactional email", Services: pq.StringArray{"email-relay-service"}, DisplayOrder: 4},
+	{Name: "Reporting & Analytics", Description: "Dashboards and data exports", Services: pq.StringArray{"data-pipeline-processor", "analytics-data-pipeline"}, DisplayOrder: 5},
+}
+
+// SeedStatusComponents creates the default components when none exist yet
+func SeedStatusComponents() error {
+	var count int64
+	if err := db.DB.Model(&models.StatusComponent{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	for _, component := range defaultStatusComponents {
+		component := component
+		if err := db.DB.Create(&component).Error; err != nil {
+			return err
+		}
+	}
+	log.Printf("📣 Seeded %d status page components", len(defaultStatusComponents))
+	return nil
+}
+
+func validateStatusComponent(component *models.StatusComponent) error {
+	component.Name = strings.TrimSpace(component.Name)
+	if component.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if component.Services == nil {
+		component.Services = pq.StringArray{}
+	}
+	return nil
+}
+
+func CreateStatusComponent(component *models.StatusComponent) error {
+	if err := validateStatusComponent(component); err != nil {
+		return err
+	}
+	return db.DB.Create(component).Error
+}
+
+// UpdateStatusComponent saves name, description, services and ordering; overrides have their own functions
+func UpdateStatusComponent(component *models.StatusComponent) error {
+	if err := validateStatusComponent(component); err != nil {
+		return err
+	}
+	return db.DB.Model(component).
+		Select("name", "description", "services", "display_order").
+		Updates(component).Error
+}
+
+func DeleteStatusComponent(id uuid.UUID) error {
+	result := db.DB.Delete(&models.StatusComponent{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("status component not found")
+	}
+	return nil
+}
+
+func GetStatusComponentByID(id uuid.UUID) (models.StatusComponent, error) {
+	var component models.StatusComponent
+	if err := db.DB.First(&component, "id = ?", id).Error; err != nil {
+		return component, err
+	}
+	components := []models.StatusComponent{component}
+	if err := applyComponentStatuses(components, time.Now()); err != nil {
+		return component, err
+	}
+	return components[0], nil
+}
+
+// GetStatusComponents returns every component with its derived and effective status
+func GetStatusComponents() ([]models.StatusComponent, error) {
+	var components []models.StatusComponent
+	if err := db.DB.Order("display_order ASC, name ASC").Find(&components).Error; err != nil {
+		return nil, err
+	}
+	if err := applyComponentStatuses(components, time.Now()); err != nil {
+		return nil, err
+	}
+	return components, nil
+}
+
+// SetComponentOverride pins a component's status until cleared (or until `until`)
+func SetComponentOverride(id uuid.UUID, status, reason string, until *time.Time, actor string) (models.StatusComponent, error) {
+	if _, ok := componentStatusRank[status]; !ok {
+		return models.StatusComponent{}, fmt.Errorf("status must be one of operational, degraded, partial_outage, major_outage")
+	}
+	if until != nil && !until.After(time.Now()) {
+		return models.StatusComponent{}, fmt.Errorf("override_until must be in the future")
+	}
+
+	result := db.DB.Model(&models.StatusComponent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"override_status": status,
+		"override_reason": reason,
+		"override_by":     actor,
+		"override_until":  until,
+	})
+	if result.Error != nil {
+		return models.StatusComponent{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.StatusComponent{}, fmt.Errorf("status component not found")
+	}
+
+	log.Printf("📣 %s overrode status component %s to %s", actor, id.String()[:8], status)
+	return GetStatusComponentByID(id)
+}
+
+// ClearComponentOverride returns a component to its derived status
+func ClearComponentOverride(id uuid.UUID) (models.StatusComponent, error) {
+	result := db.DB.Model(&models.StatusComponent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"override_status": "",
+		"override_reason": "",
+		"override_by":     "",
+		"override_until":  nil,
+	})
+	if result.Error != nil {
+		return models.StatusComponent{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.StatusComponent{}, fmt.Errorf("status component not found")
+	}
+	return GetStatusComponentByID(id)
+}
+
+// applyComponentStatuses fills DerivedStatus from open incidents and Status from the override, if active.
+// A component backing a system named in an incident takes the incident's severity; one that only
+// depends on an affected system (via the service catalog) is shown as degraded.
+func applyComponentStatuses(components []models.StatusComponent, now time.Time) error {
+	var incidents []models.Incident
+	if err := db.DB.Preload("Analysis").Where("status != ?", "resolved").Find(&incidents).Error; err != nil {
+		return err
+	}
+	catalog, err := GetAllServices()
+	if err != nil {
+		return err
+	}
+
+	// Worst status per system across all open incidents
+	systemStatus := map[string]string{}
+	raise := func(system, status string) {
+		if componentStatusRank[status] > componentStatusRank[systemStatus[system]] {
+			systemStatus[system] = status
+		}
+	}
+	for i := range incidents {
+		incident := &incidents[i]
+		direct := models.ComponentDegraded // Untriaged incidents count as degraded until a severity is known
+		if incident.Analysis != nil {
+			if status, ok := severityComponentStatus[incident.Analysis.Severity]; ok {
+				direct = status
+			}
+		}
+
+		systems := incidentSystems(incident)
+		for _, system := range systems {
+			raise(system, direct)
+		}
+		for _, impacted := range blastRadius(systems, catalog).Impacted {
+			raise(impacted.Name, models.ComponentDegraded)
+		}
+	}
+
+	for i := range components {
+		component := &components[i]
+		derived := models.ComponentOperational
+		for _, svc := range component.Services {
+			if status, ok := systemStatus[svc]; ok && componentStatusRank[status] > componentStatusRank[derived] {
+				derived = status
+			}
+		}
+		component.DerivedStatus = derived
+		component.Status = derived
+
+		if component.OverrideStatus != "" && (component.OverrideUntil == nil || component.OverrideUntil.After(now)) {
+			component.Status = component.OverrideStatus
+		}
+	}
+	return nil
+}
+
+// CreateStatusPost opens a public post with its first update
+func CreateStatusPost(post *models.StatusPost, message string) error {
+	post.Title = strings.TrimSpace(post.Title)
+	if post.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if strings.TrimSpace(message) == "" {
+		return fmt.Errorf("message is required")
+	}
+	if post.Status == "" {
+		post.Status = models.PostInvestigating
+	}
+	if !containsString(postStatuses, post.Status) {
+		return fmt.Errorf("status must be one of %s", strings.Join(postStatuses, ", "))
+	}
+	if err := validatePostComponents(post.ComponentIDs); err != nil {
+		return err
+	}
+	if post.ComponentIDs == nil {
+		post.ComponentIDs = pq.StringArray{}
+	}
+	if post.Status == models.PostResolved {
+		now := time.Now()
+		post.ResolvedAt = &now
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		update := models.StatusPostUpdate{
+			PostID:    post.ID,
+			Status:    post.Status,
+			Message:   message,
+			CreatedBy: post.CreatedBy,
+			CreatedAt: time.Now(),
+		}
+		if err := tx.Create(&update).Error; err != nil {
+			return err
+		}
+		post.Updates = []models.StatusPostUpdate{update}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if post.IncidentID != nil {
+		RecordTimelineEvent(*post.IncidentID, "status_page_posted", post.CreatedBy,
+			fmt.Sprintf("Published status page post %q (%s)", post.Title, post.Status),
+			map[string]interface{}{"post_id": post.ID})
+	}
+	log.Printf("📣 Published status post %q (%s)", post.Title, post.Status)
+	return nil
+}
+
+// AddStatusPostUpdate appends a public update and moves the post to its new status
+func AddStatusPostUpdate(postID uuid.UUID, status, message, actor string) (*models.StatusPost, error) {
+	if strings.TrimSpace(message) == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+
+	var post models.StatusPost
+	if err := db.DB.First(&post, "id = ?", postID).Error; err != nil {
+		return nil, fmt.Errorf("status post not found")
+	}
+	if status == "" {
+		status = post.Status
+	}
+	if !containsString(postStatuses, status) {
+		return nil, fmt.Errorf("status must be one of %s", strings.Join(postStatuses, ", "))
+	}
+
+	now := time.Now()
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		update := models.StatusPostUpdate{
+			PostID:    post.ID,
+			Status:    status,
+			Message:   message,
+			CreatedBy: actor,
+			CreatedAt: now,
+		}
+		if err := tx.Create(&update).Error; err != nil {
+			return err
+		}
+
+		post.Status = status
+		if status == models.PostResolved && post.ResolvedAt == nil {
+			post.ResolvedAt = &now
+		} else if status != models.PostResolved {
+			post.ResolvedAt = nil // Reopened
+		}
+		return tx.Model(&post).Select("status", "resolved_at", "updated_at").Updates(&post).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if post.IncidentID != nil {
+		RecordTimelineEvent(*post.IncidentID, "status_page_updated", actor,
+			fmt.Sprintf("Status page post %q updated (%s)", post.Title, status),
+			map[string]interface{}{"post_id": post.ID})
+	}
+	return GetStatusPost(post.ID)
+}
+
+func validatePostComponents(ids pq.StringArray) error {
+	for _, raw := range ids {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("invalid component ID: %s", raw)
+		}
+		var count int64
+		db.DB.Model(&models.StatusComponent{}).Where("id = ?", id).Count(&count)
+		if count == 0 {
+			return fmt.Errorf("unknown component: %s", raw)
+		}
+	}
+	return nil
+}
+
+func GetStatusPost(id uuid.UUID) (*models.StatusPost, error) {
+	var post models.StatusPost
+	err := db.DB.
+		Preload("Updates", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at DESC")
+		}).
+		First(&post, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// GetStatusPosts returns unresolved posts plus those resolved after `resolvedSince`, newest first
+func GetStatusPosts(resolvedSince time.Time) ([]models.StatusPost, error) {
+	var posts []models.StatusPost
+	err := db.DB.
+		Preload("Updates", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at DESC")
+		}).
+		Where("resolved_at IS NULL OR resolved_at >= ?", resolvedSince).
+		Order("created_at DESC").
+		Find(&posts).Error
+	return posts, err
+}
+
+func DeleteStatusPost(id uuid.UUID) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", id).Delete(&models.StatusPostUpdate{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.StatusPost{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("status post not found")
+		}
+		return nil
+	})
+}
+
+// PublicComponent is the customer-facing view of a component
+type PublicComponent struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+}
+
+// PublicPostUpdate is the customer-facing view of a post update
+type PublicPostUpdate struct {
+	Status    string    `json:"status"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PublicPost is the customer-facing view of a status post (no internal incident link or authors)
+type PublicPost struct {
+	ID         uuid.UUID          `json:"id"`
+	Title      string             `json:"title"`
+	Status     string             `json:"status"`
+	Components []string           `json:"components"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+	ResolvedAt *time.Time         `json:"resolved_at"`
+	Updates    []PublicPostUpdate `json:"updates"`
+}
+
+// PublicStatus is the whole public status page
+type PublicStatus struct {
+	Status      string            `json:"status"`
+	Description string            `json:"description"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Components  []PublicComponent `json:"components"`
+	ActivePosts []PublicPost      `json:"active_posts"`
+	RecentPosts []PublicPost      `json:"recent_posts"` // Resolved in the last 7 days
+}
+
+// GetPublicStatus builds the read-only public status page
+func GetPublicStatus() (*PublicStatus, error) {
+	components, err := GetStatusComponents()
+	if err != nil {
+		return nil, err
+	}
+	posts, err := GetStatusPosts(time.Now().AddDate(0, 0, -7))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(components))
+	page := &PublicStatus{
+		Status:      models.ComponentOperational,
+		UpdatedAt:   time.Now().UTC(),
+		Components:  []PublicComponent{},
+		ActivePosts: []PublicPost{},
+		RecentPosts: []PublicPost{},
+	}
+	for _, c := range components {
+		names[c.ID.String()] = c.Name
+		page.Components = append(page.Components, PublicComponent{ID: c.ID, Name: c.Name, Description: c.Description, Status: c.Status})
+		if componentStatusRank[c.Status] > componentStatusRank[page.Status] {
+			page.Status = c.Status
+		}
+	}
+	page.Description = overallStatusDescriptions[page.Status]
+
+	for _, post := range posts {
+		public := toPublicPost(post, names)
+		if post.ResolvedAt == nil {
+			page.ActivePosts = append(page.ActivePosts, public)
+		} else {
+			page.RecentPosts = append(page.RecentPosts, public)
+		}
+	}
+	return page, nil
+}
+
+// GetPublicPost returns a single post for the public API
+func GetPublicPost(id uuid.UUID) (*PublicPost, error) {
+	post, err := GetStatusPost(id)
+	if err != nil {
+		return nil, err
+	}
+	names, err := statusComponentNames()
+	if err != nil {
+		return nil, err
+	}
+	public := toPublicPost(*post, names)
+	return &public, nil
+}
+
+// GetPublicPostsForFeed returns the most recent posts for the Atom/RSS feeds
+func GetPublicPostsForFeed(limit int) ([]PublicPost, error) {
+	var posts []models.StatusPost
+	if err := db.DB.
+		Preload("Updates", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at DESC")
+		}).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	names, err := statusComponentNames()
+	if err != nil {
+		return nil, err
+	}
+
+	public := make([]PublicPost, len(posts))
+	for i, post := range posts {
+		public[i] = toPublicPost(post, names)
+	}
+	return public, nil
+}
+
+func statusComponentNames() (map[string]string, error) {
+	var components []models.StatusComponent
+	if err := db.DB.Select("id", "name").Find(&components).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(components))
+	for _, c := range components {
+		names[c.ID.String()] = c.Name
+	}
+	return names, nil
+}
+
+func toPublicPost(post models.StatusPost, componentNames map[string]string) PublicPost {
+	public := PublicPost{
+		ID:         post.ID,
+		Title:      post.Title,
+		Status:     post.Status,
+		Components: []string{},
+		CreatedAt:  post.CreatedAt,
+		UpdatedAt:  post.UpdatedAt,
+		ResolvedAt: post.ResolvedAt,
+		Updates:    []PublicPostUpdate{},
+	}
+	for _, id := range post.ComponentIDs {
+		if name, ok := componentNames[id]; ok {
+			public.Components = append(public.Components, name)
+		}
+	}
+	sort.Strings(public.Components)
+	for _, u := range post.Updates {
+		public.Updates = append(public.Updates, PublicPostUpdate{Status: u.Status, Message: u.Message, CreatedAt: u.CreatedAt})
+	}
+	return public
+}
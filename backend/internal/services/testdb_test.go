@@ -0,0 +1,110 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	wshub "github.com/tri27pham/incident-management-simulator/backend/internal/websocket"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDatabaseEnv names a throwaway Postgres database for the tests that need one. Those tests truncate
+// every table they touch, so never point it at a database whose data matters.
+const testDatabaseEnv = "TEST_DATABASE_URL"
+
+// testModels are the tables the services tests use
+var testModels = []interface{}{
+	&models.Incident{},
+	&models.IncidentAnalysis{},
+	&models.StatusHistory{},
+	&models.AgentExecution{},
+	&models.TimelineEntry{},
+	&models.Runbook{},
+	&models.IncidentChecklist{},
+	&models.WebhookSubscription{},
+	&models.WebhookDelivery{},
+	&models.WebhookDeliveryAttempt{},
+	&models.ChatNotification{},
+	&models.NotificationPreference{},
+	&models.NotificationRule{},
+	&models.NotificationAttempt{},
+	&models.EmailMessage{},
+	&models.EmailDigestItem{},
+	&models.FlapState{},
+	&models.FlapTransition{},
+	&models.Job{},
+}
+
+var (
+	testDBOnce sync.Once
+	testDBErr  error
+	testTables []string
+)
+
+// openTestDB connects db.DB to the test database and empties the tables the tests use, skipping the
+// test when no database is configured
+func openTestDB(t *testing.T) {
+	t.Helper()
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set; skipping a test that needs Postgres", testDatabaseEnv)
+	}
+
+	testDBOnce.Do(func() {
+		database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if err != nil {
+			testDBErr = err
+			return
+		}
+		db.DB = database
+		RegisterIncidentJobs()
+		if testDBErr = db.DB.AutoMigrate(testModels...); testDBErr != nil {
+			return
+		}
+		for _, model := range testModels {
+			stmt := &gorm.Statement{DB: db.DB}
+			if err := stmt.Parse(model); err != nil {
+				testDBErr = err
+				return
+			}
+			testTables = append(testTables, stmt.Schema.Table)
+		}
+		// Nothing serves websocket clients in tests, so keep broadcasts from filling the channel
+		go func() {
+			for range wshub.WSHub.Broadcast {
+			}
+		}()
+	})
+	if testDBErr != nil {
+		t.Fatalf("test database: %v", testDBErr)
+	}
+
+	if err := db.DB.Exec(fmt.Sprintf("TRUNCATE %s CASCADE", strings.Join(testTables, ", "))).Error; err != nil {
+		t.Fatalf("failed to empty the test database: %v", err)
+	}
+}
+
+// seedIncident stores an incident on redis-test
+func seedIncident(t *testing.T) *models.Incident {
+	t.Helper()
+	incident := &models.Incident{
+		ID:              uuid.New(),
+		Message:         "Redis memory usage critical",
+		Source:          "redis-test",
+		Status:          "triage",
+		AffectedSystems: []string{"redis-test"},
+		MetricsSnapshot: "{}",
+	}
+	if err := db.DB.Create(incident).Error; err != nil {
+		t.Fatalf("failed to seed incident: %v", err)
+	}
+	return incident
+}
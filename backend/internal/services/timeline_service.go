@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+)
+
+// RecordTimelineEvent appends an entry to an incident's timeline.
+// Failures are logged rather than returned so callers never fail because of bookkeeping.
+func RecordTimelineEvent(incidentID uuid.UUID, eventType, actor, message string, metadata map[string]interface{}) *models.TimelineEntry {
+	if actor == "" {
+		actor = "system"
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+
+	entry := models.TimelineEntry{
+		IncidentID: incidentID,
+		EventType:  eventType,
+		Actor:      actor,
+		Message:    message,
+		Metadata:   models.JSONB{Data: metadata},
+		CreatedAt:  time.Now(),
+	}
+	if err := db.DB.Create(&entry).Error; err != nil {
+		log.Printf("⚠️  Failed to record timeline event %s for incident %s: %v", eventType, incidentID.String()[:8], err)
+		return nil
+	}
+	return &entry
+}
+
+// GetIncidentTimeline returns timeline entries merged with status changes, oldest first
+func GetIncidentTimeline(incidentID uuid.UUID) ([]models.TimelineEntry, error) {
+	var entries []models.TimelineEntry
+	if err := db.DB.Where("incident_id = ?", incidentID).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	var history []models.StatusHistory
+	if err := db.DB.Where("incident_id = ?", incidentID).Find(&history).Error; err != nil {
+		return nil, err
+	}
+
+	for _, h := range history {
+		message := fmt.Sprintf("Status set to %s", h.ToStatus)
+		metadata := map[string]interface{}{"to_status": h.ToStatus}
+		if h.FromStatus != nil {
+			message = fmt.Sprintf("Status changed from %s to %s", *h.FromStatus, h.ToStatus)
+			metadata["from_status"] = *h.FromStatus
+		}
+		entries = append(entries, models.TimelineEntry{
+			ID:         h.ID,
+			IncidentID: h.IncidentID,
+			EventType:  "status_change",
+			Message:    message,
+			Metadata:   models.JSONB{Data: metadata},
+			CreatedAt:  h.ChangedAt,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
@@ -0,0 +1,391 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	webhookMaxAttempts     = 8                // Attempts before a delivery goes dead
+	webhookBaseBackoff     = 10 * time.Second // Doubled after every failed attempt
+	webhookMaxBackoff      = time.Hour
+	webhookLease           = time.Minute // How long a claimed delivery is hidden from other workers
+	webhookBatchSize       = 10
+	webhookPollInterval    = 2 * time.Second
+	webhookRequestTimeout  = 10 * time.Second
+	webhookMaxResponseBody = 2048
+)
+
+// webhookPingEvent is sent by the test endpoint and bypasses event type filters
+const webhookPingEvent = "webhook.ping"
+
+var webhookClient = &http.Client{Timeout: webhookRequestTimeout}
+
+// webhookDisableThreshold is the number of consecutive failed attempts before a subscription is disabled
+func webhookDisableThreshold() int {
+	if v, err := strconv.Atoi(os.Getenv("WEBHOOK_DISABLE_AFTER_FAILURES")); err == nil && v > 0 {
+		return v
+	}
+	return 15
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// SignWebhookPayload returns the signature header value for a body sent at timestamp.
+// Receivers recompute HMAC-SHA256(secret, "<timestamp>.<body>") and compare in constant time.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func validateWebhookSubscription(sub *models.WebhookSubscription) error {
+	parsed, err := url.Parse(sub.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) URL")
+	}
+	for _, t := range sub.EventTypes {
+		if !events.IsKnownType(t) {
+			return fmt.Errorf("unknown event type: %s", t)
+		}
+	}
+	if sub.EventTypes == nil {
+		sub.EventTypes = pq.StringArray{}
+	}
+	return nil
+}
+
+// CreateWebhookSubscription stores a subscription and generates its signing secret
+func CreateWebhookSubscription(sub *models.WebhookSubscription) error {
+	if err := validateWebhookSubscription(sub); err != nil {
+		return err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return fmt.Errorf("failed to generate secret: %w", err)
+	}
+	sub.Secret = secret
+	sub.Enabled = true
+
+	if err := db.DB.Create(sub).Error; err != nil {
+		return err
+	}
+
+	log.Printf("🔗 Created webhook subscription %s → %s (events: %v)", sub.ID.String()[:8], sub.URL, []string(sub.EventTypes))
+	return nil
+}
+
+// UpdateWebhookSubscription saves changes; re-enabling a subscription resets its failure count
+func UpdateWebhookSubscription(sub *models.WebhookSubscription) error {
+	if err := validateWebhookSubscription(sub); err != nil {
+		return err
+	}
+	if sub.Enabled {
+		sub.ConsecutiveFailures = 0
+		sub.DisabledAt = nil
+		sub.DisabledReason = ""
+	}
+	return db.DB.Save(sub).Error
+}
+
+func GetWebhookSubscriptions() ([]models.WebhookSubscription, error) {
+	var subs []models.WebhookSubscription
+	err := db.DB.Order("created_at ASC").Find(&subs).Error
+	return subs, err
+}
+
+func GetWebhookSubscriptionByID(id uuid.UUID) (models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	err := db.DB.First(&sub, "id = ?", id).Error
+	return sub, err
+}
+
+// DeleteWebhookSubscription removes a subscription along with its queued deliveries and logs
+func DeleteWebhookSubscription(id uuid.UUID) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&models.WebhookDeliveryAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("subscription_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.WebhookSubscription{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("webhook subscription not found")
+		}
+		return nil
+	})
+}
+
+func GetWebhookDeliveries(subscriptionID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := db.DB.Where("subscription_id = ?", subscriptionID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// GetWebhookDeliveryByID returns a delivery together with its attempt log
+func GetWebhookDeliveryByID(id uuid.UUID) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := db.DB.
+		Preload("AttemptLog", func(db *gorm.DB) *gorm.DB {
+			return db.Order("attempt_number ASC")
+		}).
+		First(&delivery, "id = ?", id).Error
+	return delivery, err
+}
+
+// enqueueWebhookDeliveries queues a delivery of event for every matching subscription inside tx.
+// Personal subscriptions (with an owner) are skipped; notification rules decide what they receive.
+func enqueueWebhookDeliveries(tx *gorm.DB, event events.Event) error {
+	var subs []models.WebhookSubscription
+	if err := tx.
+		Where("enabled = true AND (cardinality(event_types) = 0 OR ? = ANY(event_types))", event.Type).
+		Where("owner IS NULL OR owner = ''").
+		Find(&subs).Error; err != nil {
+		return fmt.Errorf("failed to load webhook subscriptions: %w", err)
+	}
+
+	for _, sub := range subs {
+		if _, err := enqueueWebhookDelivery(tx, sub.ID, event); err != nil {
+			return fmt.Errorf("failed to queue webhook delivery for subscription %s: %w", sub.ID.String()[:8], err)
+		}
+	}
+	return nil
+}
+
+func enqueueWebhookDelivery(tx *gorm.DB, subscriptionID uuid.UUID, event events.Event) (*models.WebhookDelivery, error) {
+	// Round-trip through JSON so the stored payload is exactly what gets sent
+	var payload map[string]interface{}
+	raw, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal(raw, &payload)
+
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: subscriptionID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        models.JSONB{Data: payload},
+		Status:         models.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now(),
+	}
+	return delivery, tx.Create(delivery).Error
+}
+
+// SendWebhookPing queues a ping event for a subscription regardless of its event filter
+func SendWebhookPing(subscriptionID uuid.UUID) (*models.WebhookDelivery, error) {
+	event := events.Event{
+		ID:         uuid.New(),
+		Type:       webhookPingEvent,
+		OccurredAt: time.Now().UTC(),
+		Data:       map[string]interface{}{"message": "Webhook test from incident-management-simulator"},
+	}
+	return enqueueWebhookDelivery(db.DB, subscriptionID, event)
+}
+
+// RedeliverWebhook queues a fresh copy of an existing delivery
+func RedeliverWebhook(id uuid.UUID) (*models.WebhookDelivery, error) {
+	original, err := GetWebhookDeliveryByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("delivery not found: %w", err)
+	}
+
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now(),
+		RedeliveryOf:   &original.ID,
+	}
+	if err := db.DB.Create(delivery).Error; err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔁 Queued redelivery %s of webhook delivery %s", delivery.ID.String()[:8], original.ID.String()[:8])
+	return delivery, nil
+}
+
+// StartWebhookDispatcher polls the delivery queue forever. Safe to run in several processes.
+func StartWebhookDispatcher() {
+	log.Printf("🔗 Webhook dispatcher started (poll every %s)", webhookPollInterval)
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		deliveries, err := claimWebhookDeliveries()
+		if err != nil {
+			log.Printf("❌ Failed to claim webhook deliveries: %v", err)
+			continue
+		}
+		for i := range deliveries {
+			attemptWebhookDelivery(&deliveries[i])
+		}
+	}
+}
+
+// claimWebhookDeliveries locks due deliveries with SKIP LOCKED and pushes their next attempt
+// out by the lease, so a crash mid-delivery just results in a later retry
+func claimWebhookDeliveries() ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND next_attempt_at <= ?", []string{models.WebhookDeliveryPending, models.WebhookDeliveryRetrying}, time.Now()).
+			Where("subscription_id IN (SELECT id FROM webhook_subscriptions WHERE enabled = true)").
+			Order("next_attempt_at ASC").
+			Limit(webhookBatchSize).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(deliveries))
+		for i, d := range deliveries {
+			ids[i] = d.ID
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(webhookLease)).Error
+	})
+	return deliveries, err
+}
+
+func attemptWebhookDelivery(delivery *models.WebhookDelivery) {
+	sub, err := GetWebhookSubscriptionByID(delivery.SubscriptionID)
+	if err != nil {
+		log.Printf("⚠️  Dropping webhook delivery %s: subscription missing", delivery.ID.String()[:8])
+		delivery.Status = models.WebhookDeliveryDead
+		delivery.LastError = "subscription no longer exists"
+		db.DB.Save(delivery)
+		return
+	}
+
+	body, _ := json.Marshal(delivery.Payload.Data)
+	delivery.Attempts++
+
+	attempt := models.WebhookDeliveryAttempt{
+		DeliveryID:     delivery.ID,
+		SubscriptionID: sub.ID,
+		AttemptNumber:  delivery.Attempts,
+		CreatedAt:      time.Now(),
+	}
+
+	start := time.Now()
+	statusCode, respBody, sendErr := sendWebhook(&sub, delivery, body)
+	attempt.DurationMs = time.Since(start).Milliseconds()
+	attempt.StatusCode = statusCode
+	attempt.ResponseBody = respBody
+	delivery.LastStatusCode = statusCode
+
+	if sendErr == nil && statusCode >= 200 && statusCode < 300 {
+		now := time.Now()
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		sub.ConsecutiveFailures = 0
+	} else {
+		if sendErr != nil {
+			attempt.Error = sendErr.Error()
+		} else {
+			attempt.Error = fmt.Sprintf("endpoint returned HTTP %d", statusCode)
+		}
+		delivery.LastError = attempt.Error
+
+		if delivery.Attempts >= webhookMaxAttempts {
+			delivery.Status = models.WebhookDeliveryDead
+			log.Printf("💀 Webhook delivery %s dead after %d attempts: %s", delivery.ID.String()[:8], delivery.Attempts, attempt.Error)
+		} else {
+			delivery.Status = models.WebhookDeliveryRetrying
+			delivery.NextAttemptAt = time.Now().Add(webhookBackoff(delivery.Attempts))
+		}
+
+		sub.ConsecutiveFailures++
+		if threshold := webhookDisableThreshold(); sub.Enabled && sub.ConsecutiveFailures >= threshold {
+			now := time.Now()
+			sub.Enabled = false
+			sub.DisabledAt = &now
+			sub.DisabledReason = fmt.Sprintf("Disabled after %d consecutive failed deliveries (last error: %s)", sub.ConsecutiveFailures, attempt.Error)
+			log.Printf("⛔ Webhook subscription %s disabled: %s", sub.ID.String()[:8], sub.DisabledReason)
+		}
+	}
+
+	if err := db.DB.Create(&attempt).Error; err != nil {
+		log.Printf("⚠️  Failed to log webhook attempt: %v", err)
+	}
+	if err := db.DB.Save(delivery).Error; err != nil {
+		log.Printf("⚠️  Failed to update webhook delivery %s: %v", delivery.ID.String()[:8], err)
+	}
+	db.DB.Model(&sub).Select("consecutive_failures", "enabled", "disabled_at", "disabled_reason").Updates(&sub)
+}
+
+func sendWebhook(sub *models.WebhookSubscription, delivery *models.WebhookDelivery, body []byte) (int, string, error) {
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "incident-management-simulator-webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", delivery.ID.String())
+	req.Header.Set("X-Webhook-Signature", SignWebhookPayload(sub.Secret, time.Now().Unix(), body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponseBody))
+	return resp.StatusCode, string(respBody), nil
+}
+
+// webhookBackoff returns the delay before the next attempt: 10s, 20s, 40s, ... capped at an hour
+func webhookBackoff(attempts int) time.Duration {
+	// Compare before converting: past ~60 doublings the delay no longer fits in a Duration
+	delay := float64(webhookBaseBackoff) * math.Pow(2, float64(attempts-1))
+	if delay > float64(webhookMaxBackoff) {
+		return webhookMaxBackoff
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	body := []byte(`{"type":"incident.created"}`)
+	got := SignWebhookPayload("whsec_test", 1700000000, body)
+
+	// A receiver recomputes HMAC-SHA256(secret, "<timestamp>.<body>")
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := "t=1700000000,v1=" + hex.EncodeToString(mac.Sum(nil))
+	if got != want {
+		t.Errorf("SignWebhookPayload = %q, want %q", got, want)
+	}
+
+	if SignWebhookPayload("whsec_other", 1700000000, body) == got {
+		t.Error("signature does not depend on the secret")
+	}
+	if SignWebhookPayload("whsec_test", 1700000001, body) == got {
+		t.Error("signature does not depend on the timestamp")
+	}
+	if SignWebhookPayload("whsec_test", 1700000000, []byte(`{"type":"incident.deleted"}`)) == got {
+		t.Error("signature does not depend on the body")
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, webhookBaseBackoff},
+		{2, 2 * webhookBaseBackoff},
+		{3, 4 * webhookBaseBackoff},
+		{webhookMaxAttempts, 128 * webhookBaseBackoff},
+		{10, webhookMaxBackoff},
+		{100, webhookMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// Deliveries are queued with the change they report, and vanish with it if it is rolled back
+func TestEventOutboxQueuesDeliveriesInTransaction(t *testing.T) {
+	openTestDB(t)
+	subscribed := &models.WebhookSubscription{URL: "https://hooks.example.com/all"}
+	if err := CreateWebhookSubscription(subscribed); err != nil {
+		t.Fatalf("CreateWebhookSubscription: %v", err)
+	}
+	filtered := &models.WebhookSubscription{URL: "https://hooks.example.com/agent", EventTypes: []string{events.AgentFailed}}
+	if err := CreateWebhookSubscription(filtered); err != nil {
+		t.Fatalf("CreateWebhookSubscription: %v", err)
+	}
+	incident := seedIncident(t)
+
+	var outbox EventOutbox
+	rollback := errors.New("rolled back")
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := outbox.AddIncident(tx, events.IncidentUpdated, incident.ID); err != nil {
+			return err
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("transaction = %v, want it rolled back", err)
+	}
+	var count int64
+	db.DB.Model(&models.WebhookDelivery{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d deliveries survived the rollback", count)
+	}
+
+	if _, err := UpdateIncidentStatus(incident.ID, "resolved"); err != nil {
+		t.Fatalf("UpdateIncidentStatus: %v", err)
+	}
+	var deliveries []models.WebhookDelivery
+	if err := db.DB.Order("event_type").Find(&deliveries).Error; err != nil {
+		t.Fatalf("failed to load deliveries: %v", err)
+	}
+	if len(deliveries) != 2 || deliveries[0].EventType != events.IncidentResolved || deliveries[1].EventType != events.IncidentUpdated {
+		t.Fatalf("deliveries = %+v, want incident.resolved and incident.updated", deliveries)
+	}
+	for _, d := range deliveries {
+		if d.SubscriptionID != subscribed.ID {
+			t.Errorf("%s went to subscription %s, want only the unfiltered one", d.EventType, d.SubscriptionID)
+		}
+	}
+}
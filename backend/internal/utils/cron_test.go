@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		field string
+		want  []int
+	}{
+		{"5", []int{5}},
+		{"5/15", []int{5, 20, 35, 50}},
+		{"*/20", []int{0, 20, 40}},
+		{"10-30/10", []int{10, 20, 30}},
+		{"1,2,58/1", []int{1, 2, 58, 59}},
+	}
+	for _, tt := range tests {
+		values, err := parseCronField(tt.field, 0, 59)
+		if err != nil {
+			t.Fatalf("parseCronField(%q): %v", tt.field, err)
+		}
+		var got []int
+		for v := range values {
+			got = append(got, v)
+		}
+		sort.Ints(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCronField(%q) = %v, want %v", tt.field, got, tt.want)
+		}
+	}
+
+	for _, field := range []string{"60", "5/0", "30-10", "x/5"} {
+		if _, err := parseCronField(field, 0, 59); err == nil {
+			t.Errorf("parseCronField(%q) should fail", field)
+		}
+	}
+}
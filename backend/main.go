@@ -3,11 +3,15 @@ package main
 import (
 	"log"
 	"os"
+	_ "time/tzdata" // Quiet hours need IANA zones even in minimal containers
 
 	"github.com/joho/godotenv"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/agent"
 	"github.com/tri27pham/incident-management-simulator/backend/internal/db"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/events"
 	"github.com/tri27pham/incident-management-simulator/backend/internal/models"
 	"github.com/tri27pham/incident-management-simulator/backend/internal/router"
+	"github.com/tri27pham/incident-management-simulator/backend/internal/services"
 	"github.com/tri27pham/incident-management-simulator/backend/internal/websocket"
 )
 
@@ -18,11 +22,95 @@ func main() {
 	}
 
 	db.ConnectDatabase()
-	db.DB.AutoMigrate(&models.Incident{}, &models.IncidentAnalysis{}, &models.StatusHistory{}, &models.AgentExecution{})
+	db.DB.AutoMigrate(
+		&models.Incident{},
+		&models.IncidentAnalysis{},
+		&models.StatusHistory{},
+		&models.AgentExecution{},
+		&models.Service{},
+		&models.TimelineEntry{},
+		&models.Runbook{},
+		&models.IncidentChecklist{},
+		&models.MaintenanceWindow{},
+		&models.SuppressedAlert{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.WebhookDeliveryAttempt{},
+		&models.ChatNotification{},
+		&models.NotificationPreference{},
+		&models.EmailMessage{},
+		&models.EmailDigestItem{},
+		&models.NotificationRule{},
+		&models.NotificationAttempt{},
+		&models.StatusComponent{},
+		&models.StatusPost{},
+		&models.StatusPostUpdate{},
+		&models.UpdateCadence{},
+		&models.StakeholderUpdate{},
+		&models.StaleThreshold{},
+		&models.FlapState{},
+		&models.FlapTransition{},
+		&models.Job{},
+		&models.AgentTargetLease{},
+		&models.AgentActionRun{},
+		&models.AgentBreakerState{},
+	)
+
+	if err := services.SeedServiceCatalog(); err != nil {
+		log.Printf("⚠️  Failed to seed service catalog: %v", err)
+	}
+	if err := services.SeedRunbooks(); err != nil {
+		log.Printf("⚠️  Failed to seed runbooks: %v", err)
+	}
+	if err := services.SeedStatusComponents(); err != nil {
+		log.Printf("⚠️  Failed to seed status components: %v", err)
+	}
+	if err := services.SeedUpdateCadences(); err != nil {
+		log.Printf("⚠️  Failed to seed stakeholder update cadences: %v", err)
+	}
 
 	// Start the WebSocket hub in a separate goroutine
 	go websocket.WSHub.Run()
 
+	// AI analysis and agent workflow phases run as durable jobs that survive restarts;
+	// agent executions left mid-workflow by a restart are resumed or failed first
+	services.RegisterIncidentJobs()
+	agent.RegisterExecutors()
+	agent.RegisterJobs()
+	agent.RecoverExecutions()
+	go services.StartJobWorkers()
+
+	// Deliver the outbound webhooks queued alongside every domain event in the background
+	go services.StartWebhookDispatcher()
+
+	// Flag incidents that run past the resolution target for their severity
+	go services.StartSLAChecker()
+
+	// Remind responders when an open incident is overdue for a stakeholder update
+	go services.StartStakeholderUpdateScheduler()
+
+	// Return snoozed incidents to the board on time, and flag ones stuck in a status
+	go services.StartSnoozeWaker()
+	go services.StartStaleIncidentChecker()
+
+	// Clear flapping once an oscillating alert settles down
+	go services.StartFlapMonitor()
+
+	// Cancel agent executions nobody approved in time
+	go agent.StartApprovalExpiry()
+
+	// One routing engine decides who hears about each event and on which channels
+	events.Subscribe(services.RouteNotifications)
+	if services.EmailEnabled() {
+		go services.StartEmailDispatcher()
+	}
+
+	// The shared chat channel always hears about high-severity incidents and approval requests
+	if services.ChatOpsEnabled() {
+		events.Subscribe(services.NotifyChatOps)
+		log.Println("💬 Chat-ops notifications enabled")
+	}
+
 	r := router.SetupRouter()
 
 	port := os.Getenv("PORT")
@@ -0,0 +1,29 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Service Catalog
+-- Owners, tiers, runbooks and upstream dependencies per service
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS services (
+  name VARCHAR(100) PRIMARY KEY,
+  display_name VARCHAR(255),
+  description TEXT,
+  owner VARCHAR(100),                     -- Owning team
+  tier INTEGER DEFAULT 3,                 -- 1 = business critical, 3 = best effort
+  runbooks TEXT[] DEFAULT '{}',           -- Runbook slugs or URLs
+  depends_on TEXT[] DEFAULT '{}',         -- Upstream services this one needs
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- GIN index to find dependents of a service quickly
+CREATE INDEX IF NOT EXISTS idx_services_depends_on
+ON services USING GIN(depends_on);
+
+COMMENT ON TABLE services IS 'Service catalog used for ownership lookups and blast-radius calculation';
+COMMENT ON COLUMN services.depends_on IS 'Upstream dependencies; dependents are derived by reversing these edges';
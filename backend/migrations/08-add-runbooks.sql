@@ -0,0 +1,72 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Incident Timeline
+-- Notable events on an incident beyond status changes
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS incident_timeline (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  incident_id UUID NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+  event_type VARCHAR(100),                -- e.g. "runbook_started", "runbook_step_completed"
+  actor VARCHAR(255),                     -- User name or "system"
+  message TEXT,
+  metadata JSONB DEFAULT '{}',
+  created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_incident_timeline_incident
+ON incident_timeline(incident_id);
+
+-- =========================================================
+-- Runbook Library
+-- Versioned step-by-step guides (Markdown or YAML source)
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS runbooks (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  slug VARCHAR(100) NOT NULL,
+  version INTEGER NOT NULL,
+  title VARCHAR(255),
+  format VARCHAR(20) DEFAULT 'markdown'
+    CHECK (format IN ('markdown', 'yaml')),
+  content TEXT,                           -- Raw source as authored
+  steps JSONB DEFAULT '[]',               -- Ordered steps parsed from content
+  services TEXT[] DEFAULT '{}',           -- Catalog services this applies to
+  sources TEXT[] DEFAULT '{}',            -- Incident sources this applies to
+  actions TEXT[] DEFAULT '{}',            -- Related agent actions
+  teams TEXT[] DEFAULT '{}',              -- Teams incidents are routed to
+  created_by VARCHAR(255),
+  created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_runbooks_slug_version
+ON runbooks(slug, version);
+
+-- =========================================================
+-- Incident Checklists
+-- A runbook version instantiated against an incident
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS incident_checklists (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  incident_id UUID NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+  runbook_id UUID NOT NULL REFERENCES runbooks(id),
+  runbook_slug VARCHAR(100),
+  runbook_version INTEGER,
+  title VARCHAR(255),
+  steps JSONB DEFAULT '[]',               -- [{index, title, completed, completed_by, completed_at}]
+  started_by VARCHAR(255),
+  completed_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_incident_checklists_incident
+ON incident_checklists(incident_id);
+
+COMMENT ON TABLE runbooks IS 'Each edit inserts a new version; checklists pin the version they were started from';
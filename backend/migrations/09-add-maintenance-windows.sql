@@ -0,0 +1,56 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Maintenance Windows
+-- Scheduled periods where alerts for some services are expected
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  title VARCHAR(255),
+  services TEXT[] DEFAULT '{}',           -- Systems covered by the window
+  starts_at TIMESTAMP NOT NULL,           -- One-off start, or when a recurring series begins
+  ends_at TIMESTAMP,                      -- One-off end, or when a recurring series stops
+  recurrence VARCHAR(100),                -- Cron expression (UTC); NULL/empty for one-off windows
+  duration_minutes INTEGER,               -- Length of each recurring occurrence
+  mode VARCHAR(20) DEFAULT 'suppress'
+    CHECK (mode IN ('suppress', 'annotate')),
+  allow_agent_actions BOOLEAN DEFAULT false,
+  created_by VARCHAR(255),
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_services
+ON maintenance_windows USING GIN(services);
+
+-- =========================================================
+-- Suppressed Alerts
+-- Alerts held back by a maintenance window, kept for review
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS suppressed_alerts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  window_id UUID NOT NULL REFERENCES maintenance_windows(id) ON DELETE CASCADE,
+  message TEXT,
+  source VARCHAR(255),
+  affected_systems TEXT[] DEFAULT '{}',
+  payload JSONB DEFAULT '{}',             -- Original incident as received
+  received_at TIMESTAMP DEFAULT NOW(),
+  reviewed_at TIMESTAMP,
+  reviewed_by VARCHAR(255),
+  resolution VARCHAR(20),                 -- "dismissed" or "promoted"
+  incident_id UUID REFERENCES incidents(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_suppressed_alerts_window
+ON suppressed_alerts(window_id);
+
+CREATE INDEX IF NOT EXISTS idx_suppressed_alerts_unreviewed
+ON suppressed_alerts(received_at) WHERE reviewed_at IS NULL;
+
+COMMENT ON COLUMN maintenance_windows.mode IS 'suppress: hold alerts for review; annotate: open incidents marked as during maintenance';
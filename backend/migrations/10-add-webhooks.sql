@@ -0,0 +1,76 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Webhook Subscriptions
+-- External endpoints that receive signed event notifications
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name VARCHAR(255),
+  url TEXT NOT NULL,
+  secret VARCHAR(255) NOT NULL,           -- HMAC-SHA256 signing key
+  event_types TEXT[] DEFAULT '{}',        -- Empty = all events
+  enabled BOOLEAN DEFAULT true,
+  consecutive_failures INTEGER DEFAULT 0,
+  disabled_at TIMESTAMP,
+  disabled_reason TEXT,
+  created_by VARCHAR(255),
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- =========================================================
+-- Webhook Deliveries
+-- Durable delivery queue, one row per event per subscription
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+  event_id UUID NOT NULL,
+  event_type VARCHAR(100),
+  payload JSONB DEFAULT '{}',             -- Exact body sent to the endpoint
+  status VARCHAR(20) DEFAULT 'pending'
+    CHECK (status IN ('pending', 'retrying', 'succeeded', 'dead')),
+  attempts INTEGER DEFAULT 0,
+  next_attempt_at TIMESTAMP DEFAULT NOW(),
+  last_status_code INTEGER,
+  last_error TEXT,
+  delivered_at TIMESTAMP,
+  redelivery_of UUID REFERENCES webhook_deliveries(id) ON DELETE SET NULL,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription
+ON webhook_deliveries(subscription_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+ON webhook_deliveries(next_attempt_at) WHERE status IN ('pending', 'retrying');
+
+-- =========================================================
+-- Webhook Delivery Attempts
+-- One row per HTTP attempt, for debugging failing endpoints
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  delivery_id UUID NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+  subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+  attempt_number INTEGER,
+  status_code INTEGER,
+  response_body TEXT,                     -- Truncated
+  error TEXT,
+  duration_ms BIGINT,
+  created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery
+ON webhook_delivery_attempts(delivery_id);
+
+COMMENT ON COLUMN webhook_subscriptions.secret IS 'Signs X-Webhook-Signature: t=<unix>,v1=hex(HMAC-SHA256(secret, "<t>.<body>"))';
@@ -0,0 +1,36 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Agent approval attribution
+-- Who approved or rejected an execution, from the UI or chat
+-- =========================================================
+
+ALTER TABLE agent_executions
+ADD COLUMN IF NOT EXISTS approved_by VARCHAR(255),
+ADD COLUMN IF NOT EXISTS rejected_by VARCHAR(255),
+ADD COLUMN IF NOT EXISTS decided_at TIMESTAMP;
+
+-- =========================================================
+-- Chat Notifications
+-- One row per chat-ops message, also used to avoid posting twice
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS chat_notifications (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  kind VARCHAR(50) NOT NULL,              -- "high_severity_incident", "approval_request" (default route) or the routed event's type
+  subject_id UUID NOT NULL,               -- Incident, agent execution or routed event the message is about
+  incident_id UUID,
+  status_code INTEGER,
+  error TEXT,
+  sent_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_chat_notification_subject
+ON chat_notifications(kind, subject_id);
+
+CREATE INDEX IF NOT EXISTS idx_chat_notifications_incident_id
+ON chat_notifications(incident_id);
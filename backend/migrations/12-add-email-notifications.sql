@@ -0,0 +1,77 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Incident ownership & SLA
+-- =========================================================
+
+ALTER TABLE incidents
+ADD COLUMN IF NOT EXISTS assignee VARCHAR(255),
+ADD COLUMN IF NOT EXISTS sla_breached_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_incidents_sla_open
+ON incidents(created_at) WHERE sla_breached_at IS NULL AND status != 'resolved';
+
+-- =========================================================
+-- Notification Preferences
+-- Per-user email settings, keyed by display name (X-User-Name)
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+  user_name VARCHAR(255) PRIMARY KEY,
+  email VARCHAR(255) NOT NULL,
+  event_types TEXT[] DEFAULT '{}',        -- Empty = every email event
+  delivery_mode VARCHAR(20) DEFAULT 'immediate'
+    CHECK (delivery_mode IN ('immediate', 'hourly', 'daily')),
+  enabled BOOLEAN DEFAULT true,
+  last_digest_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- =========================================================
+-- Email Outbox
+-- Rendered emails, sent and retried by the background dispatcher
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS email_outbox (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_name VARCHAR(255),
+  recipient VARCHAR(255) NOT NULL,
+  subject TEXT,
+  text_body TEXT,
+  html_body TEXT,
+  event_type VARCHAR(100),                -- "digest" for digest emails
+  status VARCHAR(20) DEFAULT 'pending'
+    CHECK (status IN ('pending', 'sent', 'failed')),
+  attempts INTEGER DEFAULT 0,
+  next_attempt_at TIMESTAMP DEFAULT NOW(),
+  last_error TEXT,
+  sent_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_outbox_due
+ON email_outbox(next_attempt_at) WHERE status = 'pending';
+
+-- =========================================================
+-- Email Digest Items
+-- Notifications held back for hourly/daily digests
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS email_digest_items (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_name VARCHAR(255) NOT NULL,
+  event_type VARCHAR(100),
+  incident_id UUID,
+  summary TEXT,
+  created_at TIMESTAMP DEFAULT NOW(),
+  digested_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_digest_items_pending
+ON email_digest_items(user_name) WHERE digested_at IS NULL;
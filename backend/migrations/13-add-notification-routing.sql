@@ -0,0 +1,78 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Notification preferences: routing settings
+-- Email becomes optional now that other channels exist
+-- =========================================================
+
+ALTER TABLE notification_preferences
+ALTER COLUMN email DROP NOT NULL,
+ADD COLUMN IF NOT EXISTS chat_webhook_url TEXT,
+ADD COLUMN IF NOT EXISTS quiet_hours_start VARCHAR(5),   -- "HH:MM"
+ADD COLUMN IF NOT EXISTS quiet_hours_end VARCHAR(5),     -- "HH:MM", may wrap past midnight
+ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) DEFAULT 'UTC',
+ADD COLUMN IF NOT EXISTS throttle_minutes INTEGER DEFAULT 10;
+
+-- Personal webhook subscriptions are only used by notification rules
+ALTER TABLE webhook_subscriptions
+ADD COLUMN IF NOT EXISTS owner VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_owner
+ON webhook_subscriptions(owner);
+
+-- =========================================================
+-- Notification Rules
+-- Per-user routing by event type, severity and team (empty = any)
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS notification_rules (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_name VARCHAR(255) NOT NULL,
+  name VARCHAR(255),
+  channels TEXT[] DEFAULT '{}',           -- ws, email, webhook, chat
+  event_types TEXT[] DEFAULT '{}',
+  severities TEXT[] DEFAULT '{}',
+  teams TEXT[] DEFAULT '{}',
+  bypass_quiet_hours BOOLEAN DEFAULT false,
+  enabled BOOLEAN DEFAULT true,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_rules_user_name
+ON notification_rules(user_name);
+
+-- =========================================================
+-- Notification Attempts
+-- Every routing decision, including suppressed ones
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS notification_attempts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_name VARCHAR(255) NOT NULL,
+  rule_id UUID,                           -- NULL for the default email rule from preferences
+  channel VARCHAR(20),
+  event_id UUID,
+  event_type VARCHAR(100),
+  incident_id UUID,
+  dedup_key VARCHAR(255),                 -- Channel + incident fingerprint, used for throttling
+  status VARCHAR(20)
+    CHECK (status IN ('sent', 'queued', 'suppressed', 'failed')),
+  reason VARCHAR(50),                     -- quiet_hours, throttled, no_address
+  detail TEXT,
+  reference VARCHAR(255),                 -- Email outbox / webhook delivery IDs
+  created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_attempts_user
+ON notification_attempts(user_name, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_notification_attempts_dedup
+ON notification_attempts(user_name, dedup_key, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_notification_attempts_incident_id
+ON notification_attempts(incident_id);
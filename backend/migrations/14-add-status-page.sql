@@ -0,0 +1,60 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Status Components
+-- Customer-facing parts of the product; status is derived from
+-- open incidents on the backing services unless overridden
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS status_components (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name VARCHAR(255) NOT NULL,
+  description TEXT,
+  services TEXT[] DEFAULT '{}',
+  display_order INTEGER DEFAULT 0,
+  override_status VARCHAR(20),             -- operational, degraded, partial_outage, major_outage
+  override_reason TEXT,
+  override_by VARCHAR(255),
+  override_until TIMESTAMP, -- NULL = until cleared
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- =========================================================
+-- Status Posts
+-- Public incident write-ups, separate from internal notes
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS status_posts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  title VARCHAR(255) NOT NULL,
+  status VARCHAR(20) DEFAULT 'investigating', -- investigating, identified, monitoring, resolved
+  component_ids TEXT[] DEFAULT '{}',
+  incident_id UUID,                           -- internal link, never exposed publicly
+  created_by VARCHAR(255),
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW(),
+  resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_posts_incident_id
+ON status_posts(incident_id);
+
+CREATE INDEX IF NOT EXISTS idx_status_posts_updated_at
+ON status_posts(updated_at DESC);
+
+CREATE TABLE IF NOT EXISTS status_post_updates (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  post_id UUID NOT NULL REFERENCES status_posts(id) ON DELETE CASCADE,
+  status VARCHAR(20),
+  message TEXT,
+  created_by VARCHAR(255),
+  created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_post_updates_post_id
+ON status_post_updates(post_id);
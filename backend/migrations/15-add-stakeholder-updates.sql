@@ -0,0 +1,48 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Incidents: stakeholder update clock
+-- =========================================================
+
+ALTER TABLE incidents
+ADD COLUMN IF NOT EXISTS last_stakeholder_update_at TIMESTAMP, -- NULL = clock runs from created_at
+ADD COLUMN IF NOT EXISTS last_update_reminder_at TIMESTAMP;
+
+-- =========================================================
+-- Stakeholder Update Cadences
+-- How often each severity needs an update while unresolved
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS stakeholder_update_cadences (
+  severity VARCHAR(20) PRIMARY KEY,        -- high, medium, low
+  interval_minutes INTEGER NOT NULL,
+  enabled BOOLEAN DEFAULT true,
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+INSERT INTO stakeholder_update_cadences (severity, interval_minutes, enabled) VALUES
+  ('high', 30, true),
+  ('medium', 60, true),
+  ('low', 240, false)
+ON CONFLICT (severity) DO NOTHING;
+
+-- =========================================================
+-- Stakeholder Updates
+-- Communications sent about an incident; posting one resets the clock
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS stakeholder_updates (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  incident_id UUID NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+  message TEXT NOT NULL,
+  posted_by VARCHAR(255),
+  ai_drafted BOOLEAN DEFAULT false,
+  created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stakeholder_updates_incident_id
+ON stakeholder_updates(incident_id, created_at DESC);
@@ -0,0 +1,32 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Incidents: snooze and stale flag
+-- =========================================================
+
+ALTER TABLE incidents
+ADD COLUMN IF NOT EXISTS snoozed_until TIMESTAMP,   -- hidden from the board until this time
+ADD COLUMN IF NOT EXISTS snooze_reason TEXT,
+ADD COLUMN IF NOT EXISTS snoozed_by VARCHAR(255),
+ADD COLUMN IF NOT EXISTS stale_flagged_at TIMESTAMP; -- cleared on the next status change
+
+CREATE INDEX IF NOT EXISTS idx_incidents_snoozed_until
+ON incidents(snoozed_until)
+WHERE snoozed_until IS NOT NULL;
+
+-- =========================================================
+-- Stale Incident Thresholds
+-- Per-team time allowed in one status; teams without a row use
+-- the STALE_INCIDENT_HOURS default
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS stale_incident_thresholds (
+  team VARCHAR(100) PRIMARY KEY,
+  threshold_hours INTEGER NOT NULL,
+  enabled BOOLEAN DEFAULT true,
+  updated_at TIMESTAMP DEFAULT NOW()
+);
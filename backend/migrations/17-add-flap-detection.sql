@@ -0,0 +1,49 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Flap States
+-- One row per alert fingerprint (source + systems + normalised message)
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS flap_states (
+  fingerprint VARCHAR(32) PRIMARY KEY,
+  source VARCHAR(255),
+  message TEXT,
+  flapping BOOLEAN DEFAULT false,
+  flapping_since TIMESTAMP,
+  transition_count INTEGER DEFAULT 0,   -- transitions in the window at the last evaluation
+  last_transition_at TIMESTAMP,
+  last_incident_id UUID,
+  cleared_at TIMESTAMP,
+  cleared_by VARCHAR(255),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_flap_states_source
+ON flap_states(source);
+
+CREATE INDEX IF NOT EXISTS idx_flap_states_flapping
+ON flap_states(flapping);
+
+-- =========================================================
+-- Flap Transitions
+-- Every open/resolve per fingerprint, counted in a sliding window
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS flap_transitions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  fingerprint VARCHAR(32) NOT NULL,
+  incident_id UUID NOT NULL,
+  kind VARCHAR(20),                     -- opened, resolved
+  occurred_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_flap_transitions_fp_time
+ON flap_transitions(fingerprint, occurred_at);
+
+CREATE INDEX IF NOT EXISTS idx_flap_transitions_incident_id
+ON flap_transitions(incident_id);
@@ -0,0 +1,35 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Jobs
+-- Durable background work (AI analysis, agent workflow phases),
+-- claimed by workers with FOR UPDATE SKIP LOCKED and a lease
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS jobs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  type VARCHAR(100) NOT NULL,           -- incident.analyze, agent.plan, agent.execute
+  payload JSONB DEFAULT '{}'::jsonb,
+  status VARCHAR(20) DEFAULT 'queued',  -- queued, running, succeeded, dead, cancelled
+  attempts INTEGER DEFAULT 0,
+  max_attempts INTEGER DEFAULT 5,
+  run_at TIMESTAMP DEFAULT NOW(),       -- earliest time the next attempt may start
+  locked_until TIMESTAMP,               -- worker lease; expired leases are reclaimed
+  locked_by VARCHAR(100),
+  last_error TEXT,
+  cancelled_by VARCHAR(255),
+  started_at TIMESTAMP,
+  finished_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_claim
+ON jobs(type, status, run_at);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_created_at
+ON jobs(created_at);
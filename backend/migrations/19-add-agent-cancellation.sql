@@ -0,0 +1,15 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Agent execution cancellation
+-- Who stopped an execution, when, and why (any non-terminal phase)
+-- =========================================================
+
+ALTER TABLE agent_executions
+ADD COLUMN IF NOT EXISTS cancelled_by VARCHAR(255),
+ADD COLUMN IF NOT EXISTS cancel_reason TEXT,
+ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP;
@@ -0,0 +1,28 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Agent rollback
+-- Pre-change snapshots and compensating actions, run automatically
+-- or on approval when execution or verification fails
+-- =========================================================
+
+ALTER TABLE agent_executions
+ADD COLUMN IF NOT EXISTS rollback_plan JSONB DEFAULT '{}',      -- {mode, snapshot, compensate, notes}
+ADD COLUMN IF NOT EXISTS snapshot JSONB DEFAULT '[]',           -- Array of {target, taken_at, state, error}
+ADD COLUMN IF NOT EXISTS rollback_reason TEXT,
+ADD COLUMN IF NOT EXISTS rollback_logs JSONB DEFAULT '[]',
+ADD COLUMN IF NOT EXISTS rollback_checks JSONB DEFAULT '[]',
+ADD COLUMN IF NOT EXISTS rollback_passed BOOLEAN,
+ADD COLUMN IF NOT EXISTS rollback_approved_by VARCHAR(255),
+ADD COLUMN IF NOT EXISTS rollback_started_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS rollback_completed_at TIMESTAMP;
+
+-- Rollback is its own phase
+ALTER TABLE agent_executions DROP CONSTRAINT IF EXISTS agent_executions_status_check;
+ALTER TABLE agent_executions ADD CONSTRAINT agent_executions_status_check
+  CHECK (status IN ('thinking', 'previewing', 'awaiting_approval', 'executing', 'verifying',
+                    'awaiting_rollback_approval', 'rolling_back', 'completed', 'failed', 'cancelled'));
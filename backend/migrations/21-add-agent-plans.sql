@@ -0,0 +1,15 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Multi-step agent plans
+-- An ordered list of catalog actions with conditions on the previous
+-- step's verification; each step keeps its own approval, logs and checks
+-- =========================================================
+
+ALTER TABLE agent_executions
+ADD COLUMN IF NOT EXISTS plan JSONB DEFAULT '[]',            -- Array of {action, condition, commands, approved, status, logs, checks, ...}
+ADD COLUMN IF NOT EXISTS current_step INTEGER DEFAULT 0;     -- Index of the step being run or decided
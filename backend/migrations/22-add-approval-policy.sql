@@ -0,0 +1,15 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Policy-based approval
+-- The policy decision (risk level, required approvals, reasons) and every
+-- distinct approver's sign-off are kept on the execution
+-- =========================================================
+
+ALTER TABLE agent_executions
+ADD COLUMN IF NOT EXISTS approval_policy JSONB DEFAULT '{}', -- {risk_level, required_approvals, reasons, evaluated_at}
+ADD COLUMN IF NOT EXISTS approvals JSONB DEFAULT '[]';       -- Array of {actor, steps, at}
@@ -0,0 +1,18 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Approval expiry and stale-plan protection
+-- Executions left awaiting approval past the TTL are cancelled; the state
+-- shown at preview is compared with current state before approval
+-- =========================================================
+
+ALTER TABLE agent_executions
+ADD COLUMN IF NOT EXISTS preview_state JSONB DEFAULT '{}',   -- {service: {health, status}} from health-monitor /status at preview
+ADD COLUMN IF NOT EXISTS approval_expires_at TIMESTAMP;      -- Cancelled if still awaiting approval by then
+
+CREATE INDEX IF NOT EXISTS idx_agent_executions_approval_expiry
+ON agent_executions(approval_expires_at) WHERE status = 'awaiting_approval';
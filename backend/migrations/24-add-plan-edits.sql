@@ -0,0 +1,17 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Editable remediation plans
+-- Approvers may edit the previewed plan; the agent's plan is kept with
+-- the differences so edits can be evaluated later
+-- =========================================================
+
+ALTER TABLE agent_executions
+ADD COLUMN IF NOT EXISTS ai_plan JSONB DEFAULT '[]',         -- The plan as the agent previewed it, once edited
+ADD COLUMN IF NOT EXISTS plan_diff JSONB DEFAULT '[]',       -- Array of {step, kind, detail, from, to}
+ADD COLUMN IF NOT EXISTS plan_edited_by VARCHAR(255),
+ADD COLUMN IF NOT EXISTS plan_edited_at TIMESTAMP;
@@ -0,0 +1,58 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Agent Target Leases
+-- One row per target system held by a running execution
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS agent_target_leases (
+  target VARCHAR(100) PRIMARY KEY,
+  execution_id UUID NOT NULL,
+  acquired_at TIMESTAMP DEFAULT NOW(),
+  expires_at TIMESTAMP NOT NULL          -- an expired lease may be taken over
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_target_leases_execution_id
+ON agent_target_leases(execution_id);
+
+-- =========================================================
+-- Agent Action Runs
+-- Every action the agent ran for real, counted in a sliding window
+-- by the per-action and per-system rate limits
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS agent_action_runs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  execution_id UUID NOT NULL,
+  incident_id UUID,
+  action VARCHAR(100),
+  target VARCHAR(100),
+  started_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_action_runs_execution_id
+ON agent_action_runs(execution_id);
+
+CREATE INDEX IF NOT EXISTS idx_agent_action_runs_action_time
+ON agent_action_runs(action, started_at);
+
+CREATE INDEX IF NOT EXISTS idx_agent_action_runs_target_time
+ON agent_action_runs(target, started_at);
+
+-- =========================================================
+-- Agent Circuit Breaker
+-- A single row; opened after too many failed executions
+-- =========================================================
+
+CREATE TABLE IF NOT EXISTS agent_breaker_states (
+  id INTEGER PRIMARY KEY,
+  opened_at TIMESTAMP,
+  reason TEXT,
+  reset_at TIMESTAMP,                    -- failures before this no longer count
+  reset_by VARCHAR(255),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
@@ -0,0 +1,14 @@
+-- Switch to app DB context
+\connect incident_db
+
+-- Switch to app user
+SET ROLE incident_user;
+
+-- =========================================================
+-- Maintenance window timezones
+-- Recurrences were always read in UTC; a window may now name the
+-- IANA timezone its cron expression is written in
+-- =========================================================
+
+ALTER TABLE maintenance_windows
+ADD COLUMN IF NOT EXISTS timezone VARCHAR(64);  -- e.g. Europe/London; NULL/empty means UTC
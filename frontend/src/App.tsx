@@ -7,6 +7,7 @@ import { FilterBar } from './components/FilterBar';
 import { ResolvedIncidentsPanel } from './components/ResolvedIncidentsPanel';
 import { LoginScreen } from './components/LoginScreen';
 import { CreateIncidentModal, NewIncidentData } from './components/CreateIncidentModal';
+import { ChatOpsDecisionModal, ChatOpsLink, clearChatOpsLink, readChatOpsLink } from './components/ChatOpsDecisionModal';
 import { ActiveUsers } from './components/ActiveUsers';
 import { InfoTooltip } from './components/InfoTooltip';
 import * as api from './services/api';
@@ -185,6 +186,8 @@ function App() {
   const [resolvedIncidents, setResolvedIncidents] = useState<Incident[]>([]);
   const [showCreateModal, setShowCreateModal] = useState(false);
   const [showGuideModal, setShowGuideModal] = useState(false);
+  // Approve/reject link from the shared chat channel, confirmed once the user has logged in
+  const [chatOpsLink, setChatOpsLink] = useState<ChatOpsLink | null>(readChatOpsLink);
   const [showFailureDropdown, setShowFailureDropdown] = useState(false);
   const [dropdownPosition, setDropdownPosition] = useState<{ top: number; right: number } | null>(null);
   const [isTriggeringFailure, setIsTriggeringFailure] = useState(false);
@@ -1234,7 +1237,56 @@ function App() {
             setModalIncident(null);
             return;
           }
-          
+
+          // Notification routed to a specific user by the backend rules
+          if ((data as any).type === 'notification') {
+            if ((data as any).user === currentUserName) {
+              showSuccessToast(`${(data as any).title}: ${(data as any).body}`);
+            }
+            return;
+          }
+
+          // Open incident has gone past its stakeholder update cadence
+          if ((data as any).type === 'stakeholder_update_due') {
+            const reminder = data as any;
+            if (!reminder.assignee || reminder.assignee === currentUserName) {
+              showErrorToast(`Stakeholder update due (${reminder.minutes_since_update}m since last): ${reminder.message}`);
+            }
+            return;
+          }
+
+          // Snoozed incident returned to the board
+          if ((data as any).type === 'incident_woken') {
+            showSuccessToast(`Back from snooze: ${(data as any).message}`);
+            return;
+          }
+
+          // Incident has sat in one status past its team's threshold
+          if ((data as any).type === 'incident_stale') {
+            const stale = data as any;
+            if (!stale.assignee || stale.assignee === currentUserName) {
+              showErrorToast(`Stale for ${stale.hours_in_status}h in ${stale.status}: ${stale.message}`);
+            }
+            return;
+          }
+
+          // Agent plan nobody approved before its approval window closed
+          if ((data as any).type === 'agent_approval_expired') {
+            showErrorToast(`Agent approval expired (${(data as any).action}): ${(data as any).message}`);
+            return;
+          }
+
+          // Too many agent executions failed; agent actions are suspended until the breaker is reset
+          if ((data as any).type === 'agent_circuit_open') {
+            showErrorToast((data as any).message);
+            return;
+          }
+
+          // Other typed messages (e.g. checklist_update) are not incident updates
+          if ((data as any).type) {
+            return;
+          }
+
           // Block WebSocket updates during failure trigger progress bar
           if (blockWebSocketUpdatesRef.current) {
             console.log('🚫 Blocking WebSocket update during failure trigger');
@@ -1243,6 +1295,17 @@ function App() {
           
           const incident = mapBackendIncidentToFrontend(data);
           const isResolved = data.status === 'resolved';
+
+          // Snoozed incidents are hidden from the board until the backend wakes them
+          const snoozedUntil = (data as any).snoozed_until;
+          if (!isResolved && snoozedUntil && new Date(snoozedUntil) > new Date()) {
+            setBoard((prevBoard) => ({
+              Triage: { ...prevBoard.Triage, items: prevBoard.Triage.items.filter(i => i.id !== incident.id) },
+              Investigating: { ...prevBoard.Investigating, items: prevBoard.Investigating.items.filter(i => i.id !== incident.id) },
+              Fixing: { ...prevBoard.Fixing, items: prevBoard.Fixing.items.filter(i => i.id !== incident.id) },
+            }));
+            return;
+          }
           const status = mapBackendStatusToFrontend(data.status);
           
           console.log(`✨ Processing incident ${incident.incidentNumber} -> ${isResolved ? 'resolved' : status}`);
@@ -2454,6 +2517,18 @@ function App() {
         incidents={resolvedIncidents}
       />
 
+      {/* Chat-ops decision from a shared channel link */}
+      {chatOpsLink && (
+        <ChatOpsDecisionModal
+          link={chatOpsLink}
+          onClose={() => {
+            clearChatOpsLink();
+            setChatOpsLink(null);
+          }}
+          onDecided={showSuccessToast}
+        />
+      )}
+
       {/* Create Incident Modal */}
       <CreateIncidentModal 
         isOpen={showCreateModal}
@@ -1,6 +1,7 @@
 import React, { useEffect, useState } from 'react';
 import { AgentExecution } from '../types';
-import { startAgentRemediation, getIncidentAgentExecutions, getAgentExecution, approveAgentExecution, rejectAgentExecution } from '../services/api';
+import { PlanEditor } from './PlanEditor';
+import { startAgentRemediation, getIncidentAgentExecutions, getAgentExecution, approveAgentExecution, rejectAgentExecution, cancelAgentExecution, decideAgentRollback } from '../services/api';
 
 interface AgentWorkflowProps {
   incidentId: string;
@@ -97,6 +98,8 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
   const [pollingExecution, setPollingExecution] = useState<string | null>(null);
   const [expandedCancelledIds, setExpandedCancelledIds] = useState<Set<string>>(new Set());
   const [approvingId, setApprovingId] = useState<string | null>(null);
+  const [stepSelection, setStepSelection] = useState<Record<string, number[]>>({});
+  const [editingId, setEditingId] = useState<string | null>(null);
 
   // Fetch existing executions on mount
   useEffect(() => {
@@ -125,8 +128,8 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
           }
         }
 
-        // Stop polling if completed or failed
-        if (execution.status === 'completed' || execution.status === 'failed') {
+        // Stop polling if completed, failed or cancelled
+        if (execution.status === 'completed' || execution.status === 'failed' || execution.status === 'cancelled') {
           setPollingExecution(null);
         }
       } catch (err) {
@@ -172,6 +175,18 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
     success: raw.success,
     error_message: raw.error_message,
     rollback_performed: raw.rollback_performed,
+    rollback_reason: raw.rollback_reason,
+    rollback_logs: raw.rollback_logs?.Data || raw.rollback_logs,
+    rollback_checks: raw.rollback_checks?.Data || raw.rollback_checks,
+    rollback_passed: raw.rollback_passed,
+    plan: raw.plan?.Data || raw.plan,
+    current_step: raw.current_step,
+    plan_diff: raw.plan_diff?.Data || raw.plan_diff,
+    plan_edited_by: raw.plan_edited_by,
+    approval_policy: raw.approval_policy?.Data || raw.approval_policy,
+    approvals: raw.approvals?.Data || raw.approvals,
+    approval_expires_at: raw.approval_expires_at,
+    approved_by: raw.approved_by,
     dry_run: raw.dry_run,
     started_at: raw.started_at,
     completed_at: raw.completed_at,
@@ -179,11 +194,11 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
     updated_at: raw.updated_at,
   });
 
-  const handleStartRemediation = async () => {
+  const handleStartRemediation = async (dryRun: boolean = false) => {
     setLoading(true);
     setError(null);
     try {
-      const execution = await startAgentRemediation(incidentId);
+      const execution = await startAgentRemediation(incidentId, dryRun);
       const mapped = mapExecution(execution);
       setExecutions(prev => [mapped, ...prev]);
       setPollingExecution(execution.id);
@@ -194,20 +209,50 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
     }
   };
 
-  const handleApprove = async (executionId: string) => {
+  const handleApprove = async (executionId: string, confirmStale: boolean = false) => {
     setApprovingId(executionId);
     try {
-      const execution = await approveAgentExecution(executionId);
+      const execution = await approveAgentExecution(executionId, stepSelection[executionId], confirmStale);
       const mapped = mapExecution(execution);
       setExecutions(prev => prev.map(ex => ex.id === executionId ? mapped : ex));
+      if (mapped.status === 'awaiting_approval') {
+        // Recorded, but the approval policy needs another approver
+        setApprovingId(null);
+        return;
+      }
       setPollingExecution(executionId); // Resume polling
       // Don't clear approvingId here - let polling handle it when status changes
     } catch (err: any) {
-      setError(err.message);
       setApprovingId(null);
+      // The system changed since the preview: ask whether the plan still applies
+      if (err.stalePlan && !confirmStale && window.confirm(`${err.message}\n\nApprove this plan anyway?`)) {
+        handleApprove(executionId, true);
+        return;
+      }
+      setError(err.message);
     }
   };
 
+  const handlePlanSaved = (raw: any) => {
+    const mapped = mapExecution(raw);
+    setExecutions(prev => prev.map(ex => ex.id === mapped.id ? mapped : ex));
+    // Step indexes refer to the old plan
+    setStepSelection(prev => {
+      const next = { ...prev };
+      delete next[mapped.id];
+      return next;
+    });
+    setEditingId(null);
+  };
+
+  const toggleStep = (execution: AgentExecution, index: number) => {
+    setStepSelection(prev => {
+      const current = prev[execution.id] ?? (execution.plan || []).map((_, i) => i);
+      const next = current.includes(index) ? current.filter(i => i !== index) : [...current, index].sort();
+      return { ...prev, [execution.id]: next };
+    });
+  };
+
   const handleReject = async (executionId: string) => {
     try {
       const execution = await rejectAgentExecution(executionId);
@@ -218,6 +263,30 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
     }
   };
 
+  const handleRollbackDecision = async (executionId: string, approve: boolean) => {
+    try {
+      const execution = await decideAgentRollback(executionId, approve);
+      const mapped = mapExecution(execution);
+      setExecutions(prev => prev.map(ex => ex.id === executionId ? mapped : ex));
+      if (approve) setPollingExecution(executionId);
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
+  const handleCancel = async (executionId: string) => {
+    const reason = window.prompt('Why are you stopping this execution?');
+    if (!reason || !reason.trim()) return;
+    try {
+      const execution = await cancelAgentExecution(executionId, reason.trim());
+      const mapped = mapExecution(execution);
+      setExecutions(prev => prev.map(ex => ex.id === executionId ? mapped : ex));
+      setPollingExecution(null);
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
   const toggleCancelledExpanded = (executionId: string) => {
     setExpandedCancelledIds(prev => {
       const newSet = new Set(prev);
@@ -237,6 +306,8 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
       case 'awaiting_approval': return '⏳';
       case 'executing': return '⚡';
       case 'verifying': return '🔍';
+      case 'awaiting_rollback_approval':
+      case 'rolling_back': return '⏪';
       case 'completed': return '✅';
       case 'cancelled': return '🚫';
       case 'failed': return '❌';
@@ -253,6 +324,9 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
       case 'executing':
       case 'verifying':
         return 'rgb(168, 85, 247)'; // purple
+      case 'awaiting_rollback_approval':
+      case 'rolling_back':
+        return 'rgb(249, 115, 22)'; // orange
       case 'completed':
         return 'rgb(34, 197, 94)'; // green
       case 'cancelled':
@@ -310,7 +384,7 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
       {/* Start/Retry Remediation Button */}
       {!hasActiveExecution && (
         <button
-          onClick={handleStartRemediation}
+          onClick={() => handleStartRemediation(false)}
           disabled={loading || isResolved}
           className="w-full py-2 px-3 rounded-lg text-sm font-medium transition-all flex items-center justify-center gap-2"
           style={{
@@ -340,6 +414,20 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
           )}
         </button>
       )}
+      {!hasActiveExecution && !loading && !isResolved && (
+        <button
+          onClick={() => handleStartRemediation(true)}
+          className="w-full py-1.5 px-3 rounded-lg text-xs font-medium transition-all"
+          style={{
+            backgroundColor: 'transparent',
+            color: 'rgb(var(--text-secondary))',
+            border: '1px solid rgb(var(--border-color))',
+            cursor: 'pointer',
+          }}
+        >
+          🧪 Dry run (simulate, no changes)
+        </button>
+      )}
 
       {error && (
         <div 
@@ -418,6 +506,9 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
                   </div>
                   <div className="text-xs" style={{ color: 'rgb(var(--text-tertiary))' }}>
                     {new Date(execution.created_at).toLocaleString()}
+                    {execution.approved_by && !execution.dry_run && (
+                      <span> · {execution.approved_by === 'approval-policy' ? 'Auto-approved by policy' : `Approved by ${execution.approved_by}`}</span>
+                    )}
                   </div>
                 </div>
               </div>
@@ -616,6 +707,71 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
               </div>
             )}
 
+            {/* Multi-step Plan */}
+            {Array.isArray(execution.plan) && execution.plan.length > 1 && (
+              <div className="space-y-2">
+                <div className="flex items-center gap-2 text-sm font-medium" style={{ color: 'rgb(var(--text-primary))' }}>
+                  <span>🪜</span>
+                  <span>Plan ({execution.plan.length} steps)</span>
+                </div>
+                {execution.plan.map((step, i) => {
+                  const selected = (stepSelection[execution.id] ?? execution.plan!.map((_, j) => j)).includes(i);
+                  const condition = !step.condition
+                    ? 'always'
+                    : `if ${step.condition.check ? `"${step.condition.check}"` : "previous step's checks"} ${step.condition.passed ? 'passed' : 'failed'}`;
+                  const statusColor = step.status === 'passed' ? 'rgb(34, 197, 94)'
+                    : step.status === 'failed' ? 'rgb(239, 68, 68)'
+                    : step.status === 'skipped' ? 'rgb(107, 114, 128)'
+                    : 'rgb(168, 85, 247)';
+                  return (
+                    <div
+                      key={i}
+                      className="p-2 rounded text-xs"
+                      style={{
+                        backgroundColor: 'rgba(168, 85, 247, 0.05)',
+                        border: execution.current_step === i && execution.status !== 'awaiting_approval'
+                          ? '1px solid rgb(168, 85, 247)'
+                          : '1px solid rgba(168, 85, 247, 0.2)'
+                      }}
+                    >
+                      <div className="flex items-center gap-2">
+                        {execution.status === 'awaiting_approval' && (
+                          <input type="checkbox" checked={selected} onChange={() => toggleStep(execution, i)} />
+                        )}
+                        <span className="font-medium" style={{ color: 'rgb(var(--text-primary))' }}>
+                          {i + 1}. {step.action}
+                        </span>
+                        <span style={{ color: 'rgb(var(--text-tertiary))' }}>({condition})</span>
+                        {execution.status !== 'awaiting_approval' && (
+                          <span className="ml-auto uppercase font-bold" style={{ color: statusColor, fontSize: '10px' }}>
+                            {step.status}
+                          </span>
+                        )}
+                      </div>
+                      {step.reason && (
+                        <div className="mt-1" style={{ color: 'rgb(var(--text-secondary))' }}>{step.reason}</div>
+                      )}
+                      {step.outcome && (
+                        <div className="mt-1" style={{ color: 'rgb(var(--text-tertiary))' }}>{step.outcome}</div>
+                      )}
+                    </div>
+                  );
+                })}
+              </div>
+            )}
+
+            {/* Human edits to the agent's plan */}
+            {Array.isArray(execution.plan_diff) && execution.plan_diff.length > 0 && (
+              <div className="text-xs p-2 rounded" style={{ backgroundColor: 'rgba(168, 85, 247, 0.05)', color: 'rgb(var(--text-secondary))' }}>
+                <span className="font-medium">✏️ Plan edited by {execution.plan_edited_by}:</span>{' '}
+                {execution.plan_diff.map(change => change.detail).join('; ')}
+              </div>
+            )}
+
+            {editingId === execution.id && execution.status === 'awaiting_approval' && (
+              <PlanEditor execution={execution} onSaved={handlePlanSaved} onCancel={() => setEditingId(null)} />
+            )}
+
             {/* Approval Prompt / Execution Status */}
             {(execution.status === 'awaiting_approval' || execution.status === 'executing' || execution.status === 'verifying') && (
               <div 
@@ -654,6 +810,24 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
                             <p className="text-xs" style={{ color: 'rgb(var(--text-secondary))' }}>
                               Review the commands and risks above before proceeding.
                             </p>
+                            {execution.approval_policy && execution.approval_policy.required_approvals > 0 && (
+                              <div className="text-xs mt-1" style={{ color: 'rgb(var(--text-secondary))' }}>
+                                <span className="font-medium">
+                                  Policy ({execution.approval_policy.risk_level} risk): {(execution.approvals || []).length} of {execution.approval_policy.required_approvals} approval{execution.approval_policy.required_approvals === 1 ? '' : 's'}
+                                </span>
+                                {(execution.approvals || []).length > 0 && (
+                                  <span> · approved by {(execution.approvals || []).map(a => a.actor).join(', ')}</span>
+                                )}
+                                {execution.approval_policy.reasons?.length > 0 && (
+                                  <div>{execution.approval_policy.reasons.join('; ')}</div>
+                                )}
+                              </div>
+                            )}
+                            {execution.approval_expires_at && (
+                              <div className="text-xs mt-1" style={{ color: 'rgb(var(--text-tertiary))' }}>
+                                Expires {new Date(execution.approval_expires_at).toLocaleTimeString()} if not approved
+                              </div>
+                            )}
                           </div>
                         </div>
                         <div className="flex gap-2">
@@ -688,6 +862,23 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
                             ✗ Reject
                           </button>
                         </div>
+                        {editingId !== execution.id && (
+                          <button
+                            type="button"
+                            onClick={(e) => {
+                              e.preventDefault();
+                              setEditingId(execution.id);
+                            }}
+                            className="w-full mt-2 py-1 px-3 rounded-lg font-medium text-xs transition-all"
+                            style={{
+                              border: '1px solid rgb(168, 85, 247)',
+                              color: 'rgb(168, 85, 247)',
+                              cursor: 'pointer',
+                            }}
+                          >
+                            ✏️ Edit plan before approving
+                          </button>
+                        )}
                       </>
                     )}
                   </>
@@ -700,13 +891,28 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
                     <span className="font-medium text-xs" style={{ color: 'rgb(59, 130, 246)' }}>
                       {execution.status === 'executing' ? '⚡ Executing commands...' : '🔍 Verifying results...'}
                     </span>
+                    <button
+                      type="button"
+                      onClick={(e) => {
+                        e.preventDefault();
+                        handleCancel(execution.id);
+                      }}
+                      className="ml-2 py-0.5 px-2 rounded font-medium text-xs transition-all"
+                      style={{
+                        backgroundColor: 'rgb(239, 68, 68)',
+                        color: 'white',
+                        cursor: 'pointer',
+                      }}
+                    >
+                      ■ Stop
+                    </button>
                   </div>
                 )}
               </div>
             )}
             
             {/* Completion Status */}
-            {execution.status === 'completed' && execution.success && execution.verification_passed && (
+            {execution.status === 'completed' && execution.success && (execution.verification_passed || execution.dry_run) && (
               <div 
                 className="p-3 rounded-lg"
                 style={{ 
@@ -755,10 +961,12 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
                   </svg>
                   <div>
                     <div className="font-semibold text-sm" style={{ color: 'rgb(34, 197, 94)' }}>
-                      ✅ Incident Resolved Successfully
+                      {execution.dry_run ? '🧪 Dry Run Complete' : '✅ Incident Resolved Successfully'}
                     </div>
                     <p className="text-xs" style={{ color: 'rgb(var(--text-secondary))' }}>
-                      All remediation actions completed and verified.
+                      {execution.dry_run
+                        ? 'No changes were made. Review the simulated commands and current-state checks below.'
+                        : 'All remediation actions completed and verified.'}
                     </p>
                   </div>
                 </div>
@@ -886,6 +1094,71 @@ const AgentWorkflow: React.FC<AgentWorkflowProps> = ({ incidentId, canAgentAct,
               </div>
             )}
 
+            {/* Rollback Phase */}
+            {(execution.status === 'awaiting_rollback_approval' || execution.status === 'rolling_back' || execution.rollback_performed) && (
+              <div
+                className="p-3 rounded-lg space-y-2"
+                style={{
+                  backgroundColor: 'rgba(249, 115, 22, 0.08)',
+                  border: '1px solid rgb(249, 115, 22)'
+                }}
+              >
+                <div className="flex items-center gap-2 text-sm font-medium" style={{ color: 'rgb(249, 115, 22)' }}>
+                  <span>⏪</span>
+                  <span>Rollback</span>
+                  {execution.rollback_passed !== undefined && execution.rollback_passed !== null && (
+                    <span className="text-xs">{execution.rollback_passed ? '(restored)' : '(did not restore pre-change state)'}</span>
+                  )}
+                </div>
+                {execution.rollback_reason && (
+                  <p className="text-xs" style={{ color: 'rgb(var(--text-secondary))' }}>
+                    {execution.rollback_reason}
+                  </p>
+                )}
+                {execution.status === 'awaiting_rollback_approval' && (
+                  <div className="flex gap-2">
+                    <button
+                      type="button"
+                      onClick={(e) => {
+                        e.preventDefault();
+                        handleRollbackDecision(execution.id, true);
+                      }}
+                      className="flex-1 py-1.5 px-3 rounded-lg font-medium text-sm transition-all"
+                      style={{ backgroundColor: 'rgb(249, 115, 22)', color: 'white', cursor: 'pointer' }}
+                    >
+                      ⏪ Approve Rollback
+                    </button>
+                    <button
+                      type="button"
+                      onClick={(e) => {
+                        e.preventDefault();
+                        handleRollbackDecision(execution.id, false);
+                      }}
+                      className="flex-1 py-1.5 px-3 rounded-lg font-medium text-sm transition-all"
+                      style={{ backgroundColor: 'rgb(107, 114, 128)', color: 'white', cursor: 'pointer' }}
+                    >
+                      Leave As Is
+                    </button>
+                  </div>
+                )}
+                {execution.status === 'rolling_back' && (
+                  <div className="text-xs font-medium" style={{ color: 'rgb(249, 115, 22)' }}>
+                    Running compensating commands...
+                  </div>
+                )}
+                {Array.isArray(execution.rollback_logs) && execution.rollback_logs.map((log: any, idx: number) => (
+                  <div key={idx} className="text-xs font-mono" style={{ color: 'rgb(var(--text-secondary))' }}>
+                    {log.status === 'success' ? '✓' : '✗'} {log.command}: {log.error_detail || log.output}
+                  </div>
+                ))}
+                {Array.isArray(execution.rollback_checks) && execution.rollback_checks.map((check: any, idx: number) => (
+                  <div key={idx} className="text-xs" style={{ color: check.passed ? 'rgb(34, 197, 94)' : 'rgb(239, 68, 68)' }}>
+                    {check.passed ? '✓' : '✗'} {check.check_name}: {check.result}
+                  </div>
+                ))}
+              </div>
+            )}
+
             {/* Error Message (for actual errors, not rejections) */}
             {execution.error_message && execution.status === 'failed' && (
               <div 
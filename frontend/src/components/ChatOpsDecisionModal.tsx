@@ -0,0 +1,133 @@
+import { useEffect, useState } from 'react';
+import { AgentExecutionResponse, decideSharedChatOpsLink, getAgentExecution } from '../services/api';
+import { getUserName } from '../utils/nameGenerator';
+
+// A shared chat-ops link, read from the dashboard URL (?chatops_execution=...&chatops_action=...&expires=...&sig=...)
+export interface ChatOpsLink {
+  executionId: string;
+  action: 'approve' | 'reject';
+  expires: string;
+  sig: string;
+}
+
+const LINK_PARAMS = ['chatops_execution', 'chatops_action', 'expires', 'sig'];
+
+export function readChatOpsLink(): ChatOpsLink | null {
+  const params = new URLSearchParams(window.location.search);
+  const executionId = params.get('chatops_execution');
+  const action = params.get('chatops_action');
+  const expires = params.get('expires');
+  const sig = params.get('sig');
+  if (!executionId || (action !== 'approve' && action !== 'reject') || !expires || !sig) {
+    return null;
+  }
+  return { executionId, action, expires, sig };
+}
+
+// Drops the link from the address bar so a reload doesn't offer the decision again
+export function clearChatOpsLink() {
+  const url = new URL(window.location.href);
+  LINK_PARAMS.forEach(param => url.searchParams.delete(param));
+  window.history.replaceState(null, '', url.toString());
+}
+
+interface ChatOpsDecisionModalProps {
+  link: ChatOpsLink;
+  onClose: () => void;
+  onDecided: (message: string) => void;
+}
+
+// Confirms an approve or reject clicked in the shared chat channel. The link names no one, so the decision
+// is recorded under this dashboard user, the same as the approve and reject buttons on the incident.
+export const ChatOpsDecisionModal: React.FC<ChatOpsDecisionModalProps> = ({ link, onClose, onDecided }) => {
+  const [execution, setExecution] = useState<AgentExecutionResponse | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [isSubmitting, setIsSubmitting] = useState(false);
+  const verb = link.action === 'approve' ? 'Approve' : 'Reject';
+
+  useEffect(() => {
+    getAgentExecution(link.executionId)
+      .then(setExecution)
+      .catch(() => setError('This execution could not be found.'));
+  }, [link.executionId]);
+
+  const handleConfirm = async () => {
+    setIsSubmitting(true);
+    setError(null);
+    try {
+      await decideSharedChatOpsLink(link.executionId, link.action, link.expires, link.sig);
+      onDecided(`${verb === 'Approve' ? 'Approved' : 'Rejected'} remediation as ${getUserName()}`);
+      onClose();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : `Failed to ${link.action} agent execution`);
+    } finally {
+      setIsSubmitting(false);
+    }
+  };
+
+  return (
+    <div
+      className="fixed inset-0 flex items-center justify-center z-50"
+      onClick={onClose}
+      style={{ backgroundColor: 'rgba(0, 0, 0, 0.5)' }}
+    >
+      <div
+        className="rounded-lg shadow-xl border max-w-lg w-full mx-4"
+        onClick={(e) => e.stopPropagation()}
+        style={{
+          backgroundColor: `rgb(var(--card-bg))`,
+          borderColor: `rgb(var(--border-color))`,
+        }}
+      >
+        {/* Header */}
+        <div className="flex items-center justify-between p-6 border-b" style={{ borderColor: `rgb(var(--border-color))` }}>
+          <h2 className="text-xl font-semibold text-primary">{verb} remediation from chat</h2>
+          <button
+            onClick={onClose}
+            className="text-secondary hover:text-primary transition-colors p-1 rounded-lg hover:bg-theme-button-hover cursor-pointer"
+          >
+            <svg className="w-5 h-5" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+              <path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M6 18L18 6M6 6l12 12" />
+            </svg>
+          </button>
+        </div>
+
+        <div className="p-6 space-y-4">
+          {execution && (
+            <div className="text-sm text-secondary space-y-1">
+              <div><span className="font-medium text-primary">Action:</span> {execution.recommended_action || 'unknown'}</div>
+              <div><span className="font-medium text-primary">Status:</span> {execution.status}</div>
+              {execution.reasoning && <div>{execution.reasoning}</div>}
+            </div>
+          )}
+          <p className="text-sm text-secondary">
+            This will be recorded as <span className="font-medium text-primary">{getUserName()} (via chat)</span>.
+          </p>
+          {error && <p className="text-sm text-red-500">{error}</p>}
+
+          {/* Action Buttons */}
+          <div className="flex items-center justify-end gap-3 pt-2">
+            <button
+              type="button"
+              onClick={onClose}
+              className="px-4 py-2 rounded-lg text-sm font-medium text-secondary hover:text-primary transition-colors cursor-pointer"
+            >
+              Cancel
+            </button>
+            <button
+              type="button"
+              onClick={handleConfirm}
+              disabled={isSubmitting || !execution}
+              className="px-6 py-2 rounded-lg text-sm font-medium text-white transition-colors disabled:opacity-50 disabled:cursor-not-allowed cursor-pointer"
+              style={{
+                backgroundColor: link.action === 'approve' ? 'rgb(249, 115, 22)' : 'rgb(239, 68, 68)',
+              }}
+            >
+              {isSubmitting ? 'Confirming...' : `Confirm ${link.action} as ${getUserName()}`}
+            </button>
+          </div>
+        </div>
+      </div>
+    </div>
+  );
+};
@@ -0,0 +1,198 @@
+import { useEffect, useState } from 'react';
+import { AgentExecution, CatalogAction, PlanStepEdit } from '../types';
+import { getAgentActions, editAgentPlan, AgentExecutionResponse } from '../services/api';
+
+interface PlanEditorProps {
+  execution: AgentExecution;
+  onSaved: (execution: AgentExecutionResponse) => void;
+  onCancel: () => void;
+}
+
+const MAX_PLAN_STEPS = 3;
+
+// Lets an approver adjust the previewed plan before approving it: swap a step for another catalog
+// action, tune its parameters within the catalog's limits, leave out optional commands, or add and remove steps.
+// The backend re-validates the edit and re-runs the preview, so this only guides the input.
+export function PlanEditor({ execution, onSaved, onCancel }: PlanEditorProps) {
+  const [catalog, setCatalog] = useState<CatalogAction[]>([]);
+  const [steps, setSteps] = useState<PlanStepEdit[]>(() => {
+    if (!execution.plan || execution.plan.length === 0) {
+      return [{ action: execution.recommended_action || '' }];
+    }
+    return execution.plan.map(step => ({
+      action: step.action,
+      condition: step.condition,
+      params: step.params,
+      drop_commands: step.dropped_commands,
+    }));
+  });
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    getAgentActions().then(setCatalog).catch(err => setError(err.message));
+  }, []);
+
+  const actionFor = (name: string) => catalog.find(a => a.name === name);
+
+  const updateStep = (index: number, change: Partial<PlanStepEdit>) => {
+    setSteps(prev => prev.map((step, i) => (i === index ? { ...step, ...change } : step)));
+  };
+
+  const setParam = (index: number, name: string, value: number) => {
+    setSteps(prev => prev.map((step, i) => (i === index ? { ...step, params: { ...step.params, [name]: value } } : step)));
+  };
+
+  const toggleCommand = (index: number, name: string) => {
+    setSteps(prev => prev.map((step, i) => {
+      if (i !== index) return step;
+      const dropped = step.drop_commands || [];
+      return {
+        ...step,
+        drop_commands: dropped.includes(name) ? dropped.filter(n => n !== name) : [...dropped, name],
+      };
+    }));
+  };
+
+  const setCondition = (index: number, value: string) => {
+    updateStep(index, {
+      condition: value === 'always' ? undefined : { check: steps[index].condition?.check, passed: value === 'passed' },
+    });
+  };
+
+  const handleSave = async () => {
+    setSaving(true);
+    setError(null);
+    try {
+      onSaved(await editAgentPlan(execution.id, steps));
+    } catch (err: any) {
+      setError(err.message);
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const inputStyle = {
+    backgroundColor: 'rgb(var(--card-bg))',
+    color: 'rgb(var(--text-primary))',
+    border: '1px solid rgb(var(--border-color))',
+  };
+
+  return (
+    <div className="space-y-2 p-3 rounded-lg" style={{ border: '1px solid rgba(168, 85, 247, 0.4)' }}>
+      <div className="text-sm font-medium" style={{ color: 'rgb(var(--text-primary))' }}>
+        ✏️ Edit plan
+      </div>
+
+      {steps.map((step, i) => {
+        const action = actionFor(step.action);
+        return (
+          <div key={i} className="p-2 rounded text-xs space-y-2" style={{ backgroundColor: 'rgba(168, 85, 247, 0.05)' }}>
+            <div className="flex items-center gap-2">
+              <span className="font-medium" style={{ color: 'rgb(var(--text-primary))' }}>{i + 1}.</span>
+              <select
+                className="flex-1 px-2 py-1 rounded"
+                style={inputStyle}
+                value={step.action}
+                onChange={(e) => updateStep(i, { action: e.target.value, params: undefined, drop_commands: undefined })}
+              >
+                {!action && <option value={step.action}>{step.action}</option>}
+                {catalog.map(a => (
+                  <option key={a.name} value={a.name}>{a.name} ({a.systems.join(', ')})</option>
+                ))}
+              </select>
+              {i > 0 && (
+                <select
+                  className="px-2 py-1 rounded"
+                  style={inputStyle}
+                  value={!step.condition ? 'always' : step.condition.passed ? 'passed' : 'failed'}
+                  onChange={(e) => setCondition(i, e.target.value)}
+                >
+                  <option value="always">always</option>
+                  <option value="passed">if {step.condition?.check ? `"${step.condition.check}"` : 'previous checks'} passed</option>
+                  <option value="failed">if {step.condition?.check ? `"${step.condition.check}"` : 'previous checks'} failed</option>
+                </select>
+              )}
+              {steps.length > 1 && (
+                <button
+                  type="button"
+                  onClick={() => setSteps(prev => prev.filter((_, j) => j !== i))}
+                  className="px-2 py-1 rounded"
+                  style={{ color: 'rgb(239, 68, 68)' }}
+                  title="Remove step"
+                >
+                  ✕
+                </button>
+              )}
+            </div>
+
+            {action?.params?.map(param => (
+              <label key={param.name} className="flex items-center gap-2" title={param.description}>
+                <span className="font-mono" style={{ color: 'rgb(var(--text-secondary))' }}>{param.name}</span>
+                <input
+                  type="number"
+                  className="w-20 px-2 py-0.5 rounded"
+                  style={inputStyle}
+                  min={param.min}
+                  max={param.max}
+                  value={step.params?.[param.name] ?? param.default}
+                  onChange={(e) => setParam(i, param.name, Number(e.target.value))}
+                />
+                <span style={{ color: 'rgb(var(--text-tertiary))' }}>{param.min}–{param.max}</span>
+              </label>
+            ))}
+
+            {action?.commands.map(cmd => (
+              <label key={cmd.name} className="flex items-center gap-2">
+                <input
+                  type="checkbox"
+                  checked={!(step.drop_commands || []).includes(cmd.name)}
+                  disabled={cmd.required}
+                  onChange={() => toggleCommand(i, cmd.name)}
+                />
+                <span style={{ color: 'rgb(var(--text-secondary))' }}>
+                  {cmd.name}{cmd.required && ' (required)'}
+                </span>
+              </label>
+            ))}
+          </div>
+        );
+      })}
+
+      {steps.length < MAX_PLAN_STEPS && catalog.length > 0 && (
+        <button
+          type="button"
+          onClick={() => setSteps(prev => [...prev, { action: catalog[0].name, condition: { passed: false } }])}
+          className="text-xs px-2 py-1 rounded"
+          style={{ color: 'rgb(168, 85, 247)' }}
+        >
+          + Add step
+        </button>
+      )}
+
+      {error && (
+        <div className="text-xs" style={{ color: 'rgb(239, 68, 68)' }}>{error}</div>
+      )}
+
+      <div className="flex gap-2">
+        <button
+          type="button"
+          onClick={handleSave}
+          disabled={saving}
+          className="flex-1 py-1.5 px-3 rounded-lg font-medium text-sm"
+          style={{ backgroundColor: 'rgb(168, 85, 247)', color: 'white', cursor: saving ? 'wait' : 'pointer' }}
+        >
+          {saving ? 'Saving...' : 'Save plan'}
+        </button>
+        <button
+          type="button"
+          onClick={onCancel}
+          className="flex-1 py-1.5 px-3 rounded-lg font-medium text-sm"
+          style={{ border: '1px solid rgb(var(--border-color))', color: 'rgb(var(--text-primary))' }}
+        >
+          Cancel
+        </button>
+      </div>
+    </div>
+  );
+}
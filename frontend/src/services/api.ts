@@ -1,3 +1,6 @@
+import { getUserName } from '../utils/nameGenerator';
+import { CatalogAction, PlanStepEdit } from '../types';
+
 const API_BASE_URL = import.meta.env.VITE_API_URL || 'http://localhost:8080/api/v1';
 
 export interface BackendStatusHistory {
@@ -445,6 +448,20 @@ export interface AgentExecutionResponse {
   success?: boolean;
   error_message?: string;
   rollback_performed?: boolean;
+  rollback_reason?: string;
+  rollback_logs?: any;
+  rollback_checks?: any;
+  rollback_passed?: boolean;
+  plan?: any;
+  current_step?: number;
+  plan_diff?: any;
+  plan_edited_by?: string;
+  approval_policy?: any;
+  approvals?: any;
+  approval_expires_at?: string;
+  approved_by?: string;
+  cancelled_by?: string;
+  cancel_reason?: string;
   dry_run: boolean;
   started_at?: string;
   completed_at?: string;
@@ -452,8 +469,8 @@ export interface AgentExecutionResponse {
   updated_at: string;
 }
 
-export async function startAgentRemediation(incidentId: string): Promise<AgentExecutionResponse> {
-  const response = await fetch(`${API_BASE_URL}/incidents/${incidentId}/agent/remediate`, {
+export async function startAgentRemediation(incidentId: string, dryRun: boolean = false): Promise<AgentExecutionResponse> {
+  const response = await fetch(`${API_BASE_URL}/incidents/${incidentId}/agent/remediate${dryRun ? '?dry_run=true' : ''}`, {
     method: 'POST',
   });
   if (!response.ok) {
@@ -479,13 +496,39 @@ export async function getIncidentAgentExecutions(incidentId: string): Promise<Ag
   return response.json();
 }
 
-export async function approveAgentExecution(executionId: string): Promise<AgentExecutionResponse> {
+// steps: 0-based plan steps to approve; omit to approve the whole plan
+export async function approveAgentExecution(executionId: string, steps?: number[], confirmStale: boolean = false): Promise<AgentExecutionResponse> {
   const response = await fetch(`${API_BASE_URL}/agent/executions/${executionId}/approve`, {
     method: 'POST',
+    // The approval policy counts distinct approvers, so say who is approving
+    headers: { 'Content-Type': 'application/json', 'X-User-Name': getUserName() },
+    ...(steps || confirmStale ? { body: JSON.stringify({ steps, confirm_stale: confirmStale }) } : {}),
   });
   if (!response.ok) {
     const error = await response.json();
-    throw new Error(error.error || 'Failed to approve agent execution');
+    // stalePlan: the system changed since the preview; retry with confirmStale to approve anyway
+    throw Object.assign(new Error(error.error || 'Failed to approve agent execution'), { stalePlan: !!error.stale_plan });
+  }
+  return response.json();
+}
+
+export async function getAgentActions(): Promise<CatalogAction[]> {
+  const response = await fetch(`${API_BASE_URL}/agent/actions`);
+  if (!response.ok) {
+    throw new Error('Failed to fetch agent actions');
+  }
+  return response.json();
+}
+
+export async function editAgentPlan(executionId: string, steps: PlanStepEdit[]): Promise<AgentExecutionResponse> {
+  const response = await fetch(`${API_BASE_URL}/agent/executions/${executionId}/plan`, {
+    method: 'PUT',
+    headers: { 'Content-Type': 'application/json', 'X-User-Name': getUserName() },
+    body: JSON.stringify({ steps }),
+  });
+  if (!response.ok) {
+    const error = await response.json();
+    throw new Error(error.error || 'Failed to edit agent plan');
   }
   return response.json();
 }
@@ -501,3 +544,41 @@ export async function rejectAgentExecution(executionId: string): Promise<AgentEx
   return response.json();
 }
 
+// Confirms a decision from a shared chat-ops link under this dashboard user; the link is signed but names no one
+export async function decideSharedChatOpsLink(executionId: string, action: 'approve' | 'reject', expires: string, sig: string): Promise<AgentExecutionResponse> {
+  const query = new URLSearchParams({ expires, sig });
+  const response = await fetch(`${API_BASE_URL}/chatops/shared/executions/${executionId}/${action}?${query}`, {
+    method: 'POST',
+    headers: { 'X-User-Name': getUserName() },
+  });
+  if (!response.ok) {
+    const error = await response.json();
+    throw new Error(error.error || `Failed to ${action} agent execution`);
+  }
+  return response.json();
+}
+
+export async function decideAgentRollback(executionId: string, approve: boolean): Promise<AgentExecutionResponse> {
+  const response = await fetch(`${API_BASE_URL}/agent/executions/${executionId}/rollback/${approve ? 'approve' : 'decline'}`, {
+    method: 'POST',
+  });
+  if (!response.ok) {
+    const error = await response.json();
+    throw new Error(error.error || 'Failed to record rollback decision');
+  }
+  return response.json();
+}
+
+export async function cancelAgentExecution(executionId: string, reason: string): Promise<AgentExecutionResponse> {
+  const response = await fetch(`${API_BASE_URL}/agent/executions/${executionId}/cancel`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ reason }),
+  });
+  if (!response.ok) {
+    const error = await response.json();
+    throw new Error(error.error || 'Failed to cancel agent execution');
+  }
+  return response.json();
+}
+
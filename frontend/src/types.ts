@@ -51,6 +51,8 @@ export type AgentExecutionStatus =
   | 'awaiting_approval' 
   | 'executing' 
   | 'verifying' 
+  | 'awaiting_rollback_approval'
+  | 'rolling_back'
   | 'completed' 
   | 'cancelled'
   | 'failed';
@@ -61,6 +63,7 @@ export interface AgentCommand {
   args: string[];
   target: string;
   description: string;
+  required?: boolean; // Catalog pre-checks an approver cannot drop from a step
 }
 
 export interface AgentRisk {
@@ -86,6 +89,66 @@ export interface VerificationCheck {
   expected: string;
 }
 
+export interface PlanStep {
+  action: string;
+  reason?: string;
+  condition?: { check?: string; passed: boolean };
+  params?: Record<string, number>;
+  dropped_commands?: string[];
+  commands: AgentCommand[];
+  risks: AgentRisk[];
+  approved: boolean;
+  status: 'pending' | 'running' | 'verifying' | 'passed' | 'failed' | 'skipped';
+  outcome?: string;
+  logs?: ExecutionLog[];
+  checks?: VerificationCheck[];
+}
+
+// A catalog action's tunable parameter and its limits
+export interface ActionParam {
+  name: string;
+  description: string;
+  default: number;
+  min: number;
+  max: number;
+}
+
+export interface CatalogAction {
+  name: string;
+  systems: string[];
+  description: string;
+  commands: AgentCommand[];
+  params?: ActionParam[];
+  checks: { name: string }[];
+}
+
+// One step of a plan as an approver edits it
+export interface PlanStepEdit {
+  action: string;
+  condition?: { check?: string; passed: boolean };
+  params?: Record<string, number>;
+  drop_commands?: string[];
+}
+
+export interface PlanChange {
+  step: number;
+  kind: 'step_added' | 'step_removed' | 'action_changed' | 'condition_changed' | 'param_changed' | 'command_dropped' | 'command_restored';
+  detail: string;
+}
+
+export interface ApprovalDecision {
+  risk_level: 'low' | 'medium' | 'high';
+  required_approvals: number;
+  reasons: string[];
+  evaluated_at: string;
+}
+
+export interface Approval {
+  actor: string;
+  steps: number[];
+  at: string;
+}
+
 export interface AgentExecution {
   id: string;
   incident_id: string;
@@ -97,6 +160,14 @@ export interface AgentExecution {
   commands?: AgentCommand[];
   risks?: AgentRisk[];
   estimated_impact?: string;
+  plan?: PlanStep[];
+  current_step?: number;
+  plan_diff?: PlanChange[];
+  plan_edited_by?: string;
+  approval_policy?: ApprovalDecision;
+  approvals?: Approval[];
+  approval_expires_at?: string;
+  approved_by?: string;
   execution_logs?: ExecutionLog[];
   verification_checks?: VerificationCheck[];
   verification_passed?: boolean;
@@ -104,6 +175,10 @@ export interface AgentExecution {
   success?: boolean;
   error_message?: string;
   rollback_performed?: boolean;
+  rollback_reason?: string;
+  rollback_logs?: ExecutionLog[];
+  rollback_checks?: VerificationCheck[];
+  rollback_passed?: boolean;
   dry_run: boolean;
   started_at?: string;
   completed_at?: string;
@@ -140,6 +140,8 @@ def create_incident(message, source, error_logs, metrics):
         if response.status_code == 201:
             incident_id = response.json().get('id', 'unknown')
             print(f"✅ Created incident {incident_id[:8]} for {source}")
+        elif response.status_code == 202 and response.json().get('suppressed'):
+            print(f"🔕 Alert for {source} suppressed by maintenance window")
         else:
             print(f"⚠️  Backend responded with status {response.status_code}: {response.text}")
             
@@ -1086,6 +1088,91 @@ def clear_disk():
             "message": str(e)
         }), 500
 
+@app.route('/snapshot/<system>', methods=['GET'])
+def snapshot(system):
+    """Read-only capture of a system's state, taken by the agent before it changes anything"""
+    try:
+        if system == "redis-test":
+            container = docker_client.containers.get("redis-test")
+            keys = container.exec_run(["redis-cli", "DBSIZE"])
+            memory = container.exec_run(["redis-cli", "INFO", "memory"])
+            policy = container.exec_run(["redis-cli", "CONFIG", "GET", "maxmemory-policy"])
+            memory_info = {}
+            for line in memory.output.decode().split('\n'):
+                if ':' in line and not line.startswith('#'):
+                    key, value = line.split(':', 1)
+                    memory_info[key.strip()] = value.strip()
+            policy_lines = policy.output.decode().split()
+            return jsonify({
+                "system": system,
+                "container_status": container.status,
+                "key_count": int(keys.output.decode().strip() or 0),
+                "memory_used": int(memory_info.get('used_memory', 0)),
+                "memory_max": int(memory_info.get('maxmemory', 0)),
+                "maxmemory_policy": policy_lines[1] if len(policy_lines) > 1 else "",
+                "taken_at": datetime.now().isoformat()
+            }), 200
+
+        if system == "postgres-test":
+            container = docker_client.containers.get("postgres-test")
+            conn = psycopg2.connect(
+                host="postgres-test",
+                port=5432,
+                database="testdb",
+                user="testuser",
+                password="testpass",
+                connect_timeout=5
+            )
+            cursor = conn.cursor()
+            cursor.execute("""
+                SELECT name, setting FROM pg_settings
+                WHERE name IN ('max_connections', 'shared_buffers', 'work_mem',
+                               'statement_timeout', 'idle_in_transaction_session_timeout', 'autovacuum')
+            """)
+            settings = {name: setting for name, setting in cursor.fetchall()}
+            cursor.execute("""
+                SELECT count(*) FILTER (WHERE state = 'idle'), count(*)
+                FROM pg_stat_activity WHERE pid <> pg_backend_pid()
+            """)
+            idle, total = cursor.fetchone()
+            cursor.close()
+            conn.close()
+            return jsonify({
+                "system": system,
+                "container_status": container.status,
+                "settings": settings,
+                "idle_connections": idle,
+                "total_connections": total,
+                "taken_at": datetime.now().isoformat()
+            }), 200
+
+        if system == "disk-monitor":
+            usage = shutil.disk_usage("/var/log")
+            return jsonify({
+                "system": system,
+                "file_count": len(os.listdir("/var/log")),
+                "used_mb": round(usage.used / (1024 * 1024), 2),
+                "free_mb": round(usage.free / (1024 * 1024), 2),
+                "taken_at": datetime.now().isoformat()
+            }), 200
+
+        return jsonify({
+            "status": "error",
+            "message": f"No snapshot available for {system}"
+        }), 404
+
+    except docker.errors.NotFound:
+        return jsonify({
+            "status": "error",
+            "message": f"Container '{system}' not found"
+        }), 404
+    except Exception as e:
+        print(f"❌ Error taking snapshot of {system}: {e}")
+        return jsonify({
+            "status": "error",
+            "message": str(e)
+        }), 500
+
 def start_scheduler():
     """Start the background scheduler"""
     scheduler = BackgroundScheduler()